@@ -0,0 +1,129 @@
+// Package eventbus delivers domain events to in-process subscribers.
+package eventbus
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+)
+
+// asyncQueueSize bounds the deliveries waiting for asynchronous handlers, a delivery beyond it is dropped
+const asyncQueueSize = 64
+
+// Handler reacts to a published event
+type Handler func(event domain.Event)
+
+// delivery is an event waiting for an asynchronous handler
+type delivery struct {
+	event   domain.Event
+	handler Handler
+}
+
+// Bus is an in-process event bus, handlers run in the publisher's goroutine in subscription order,
+// asynchronous handlers one at a time on a background worker
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[domain.EventType][]Handler
+	all      []Handler
+	async    map[domain.EventType][]Handler
+	queue    chan delivery
+	closed   bool
+	done     chan struct{}
+}
+
+// New creates an event bus without subscribers, Close stops its background worker
+func New() *Bus {
+	b := &Bus{
+		handlers: make(map[domain.EventType][]Handler),
+		async:    make(map[domain.EventType][]Handler),
+		queue:    make(chan delivery, asyncQueueSize),
+		done:     make(chan struct{}),
+	}
+	go b.work()
+	return b
+}
+
+// Subscribe registers a handler for one type of event
+func (b *Bus) Subscribe(eventType domain.EventType, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// SubscribeAsync registers a handler for one type of event that runs after Publish returns,
+// for side effects such as notifications that must not hold up the publisher
+func (b *Bus) SubscribeAsync(eventType domain.EventType, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.async[eventType] = append(b.async[eventType], handler)
+}
+
+// SubscribeAll registers a handler for every event
+func (b *Bus) SubscribeAll(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.all = append(b.all, handler)
+}
+
+// Publish delivers an event to its subscribers, a failing subscriber does not stop the others
+// Handlers may publish further events
+func (b *Bus) Publish(event domain.Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.all)+len(b.handlers[event.Type()]))
+	handlers = append(handlers, b.all...)
+	handlers = append(handlers, b.handlers[event.Type()]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.deliver(event, handler)
+	}
+	b.enqueue(event)
+}
+
+// enqueue hands an event to its asynchronous handlers, without waiting for a full queue
+func (b *Bus) enqueue(event domain.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, handler := range b.async[event.Type()] {
+		select {
+		case b.queue <- delivery{event: event, handler: handler}:
+		default:
+			slog.Error("[EventBus] Queue full, dropping delivery", "event", event.Type())
+		}
+	}
+}
+
+// work runs the queued deliveries until the bus is closed
+func (b *Bus) work() {
+	defer close(b.done)
+	for d := range b.queue {
+		b.deliver(d.event, d.handler)
+	}
+}
+
+// Close stops taking asynchronous deliveries and waits for the queued ones to finish
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+	<-b.done
+}
+
+// deliver runs a handler, recovering from its panic
+func (b *Bus) deliver(event domain.Event, handler Handler) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("[EventBus] Subscriber panicked", "event", event.Type(), "panic", r)
+		}
+	}()
+	handler(event)
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Publish(t *testing.T) {
+	bus := New()
+	var received []string
+
+	bus.SubscribeAll(func(event domain.Event) {
+		received = append(received, "all:"+string(event.Type()))
+	})
+	bus.Subscribe(domain.EventSessionCheckedOut, func(event domain.Event) {
+		panic("broken subscriber")
+	})
+	bus.Subscribe(domain.EventSessionCheckedOut, func(event domain.Event) {
+		checkedOut := event.(domain.SessionCheckedOut)
+		received = append(received, "checked_out:"+checkedOut.Session.ID)
+		// Subscribers may publish follow-up events
+		bus.Publish(domain.OvertimeThresholdCrossed{At: event.OccurredAt(), Date: checkedOut.Session.Date, OvertimeMinutes: 30})
+	})
+
+	bus.Publish(domain.SessionCheckedIn{At: time.Now(), Session: &domain.WorkSession{ID: "s1"}})
+	bus.Publish(domain.SessionCheckedOut{At: time.Now(), Session: &domain.WorkSession{ID: "s1", Date: "2025-10-16"}})
+
+	assert.Equal(t, []string{
+		"all:session.checked_in",
+		"all:session.checked_out",
+		"checked_out:s1",
+		"all:overtime.threshold_crossed",
+	}, received)
+}
+
+func TestBus_SubscribeAsync(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	var received []string
+
+	bus.SubscribeAsync(domain.EventOvertimeThresholdCrossed, func(event domain.Event) {
+		<-release
+		received = append(received, "notified:"+event.(domain.OvertimeThresholdCrossed).Date)
+	})
+
+	// Publishing does not wait for a slow asynchronous subscriber
+	published := make(chan struct{})
+	go func() {
+		bus.Publish(domain.OvertimeThresholdCrossed{At: time.Now(), Date: "2025-10-16", OvertimeMinutes: 30})
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish waited for an asynchronous subscriber")
+	}
+
+	// Closing waits for the queued deliveries, later events are dropped
+	close(release)
+	bus.Close()
+	bus.Publish(domain.OvertimeThresholdCrossed{At: time.Now(), Date: "2025-10-17", OvertimeMinutes: 30})
+	assert.Equal(t, []string{"notified:2025-10-16"}, received)
+}
@@ -0,0 +1,37 @@
+// Package subscriber contains the reactions to domain events: audit logging, notifications and integrations.
+package subscriber
+
+import (
+	"log/slog"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+)
+
+// AuditLog records every event in the structured log
+type AuditLog struct{}
+
+// NewAuditLog creates an audit log subscriber
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+// Handle logs an event with the fields that identify what changed
+func (a *AuditLog) Handle(event domain.Event) {
+	attrs := []any{"event", event.Type(), "at", event.OccurredAt()}
+	switch e := event.(type) {
+	case domain.SessionCheckedIn:
+		attrs = append(attrs, "session", e.Session.ID, "date", e.Session.Date, "check_in", e.Session.CheckIn)
+	case domain.SessionCheckedOut:
+		attrs = append(attrs, "session", e.Session.ID, "date", e.Session.Date, "check_out", e.Session.CheckOut,
+			"worked_minutes", e.WorkedMinutes, "overtime_minutes", e.OvertimeMinutes)
+	case domain.SessionEdited:
+		attrs = append(attrs, "session", e.Session.ID, "date", e.Session.Date, "change", e.Change)
+	case domain.ConfigChanged:
+		attrs = append(attrs, "work_hours", e.Config.DefaultWorkHours, "overtime_mode", e.Config.OvertimePolicy.Mode)
+	case domain.OvertimeThresholdCrossed:
+		attrs = append(attrs, "date", e.Date, "overtime_minutes", e.OvertimeMinutes)
+	case domain.AttendanceSynced:
+		attrs = append(attrs, "date", e.Date, "check_in", e.CheckIn, "check_out", e.CheckOut)
+	}
+	slog.Info("[Audit] Event", attrs...)
+}
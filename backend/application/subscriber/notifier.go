@@ -0,0 +1,54 @@
+package subscriber
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+)
+
+// configTimeout bounds loading the config for a notification, events carry no request context
+const configTimeout = 5 * time.Second
+
+// Alarmer sends a push notification to a webhook URL
+type Alarmer interface {
+	Alarm(url string, message string) error
+}
+
+// Notifier pushes notifications for events worth interrupting the user for
+type Notifier struct {
+	repo    domain.Repository
+	alarmer Alarmer
+}
+
+// NewNotifier creates a notification subscriber
+func NewNotifier(repo domain.Repository, alarmer Alarmer) *Notifier {
+	return &Notifier{repo: repo, alarmer: alarmer}
+}
+
+// Handle sends an overtime notification to the check-out webhook when a day goes into overtime
+func (n *Notifier) Handle(event domain.Event) {
+	crossed, ok := event.(domain.OvertimeThresholdCrossed)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configTimeout)
+	defer cancel()
+	config, err := n.repo.GetConfig(ctx)
+	if err != nil {
+		slog.Info("[Notifier] Failed to get config", "error", err)
+		return
+	}
+	if config.CheckOutWebhookURL == "" {
+		return
+	}
+
+	message := fmt.Sprintf("⏱️ %s went into overtime: %dh %dm",
+		crossed.Date, crossed.OvertimeMinutes/domain.MinutesPerHour, crossed.OvertimeMinutes%domain.MinutesPerHour)
+	if err := n.alarmer.Alarm(config.CheckOutWebhookURL, message); err != nil {
+		slog.Info("[Notifier] Failed to send notification", "error", err)
+	}
+}
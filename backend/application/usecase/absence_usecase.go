@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+)
+
+// ListAbsences retrieves the absences of a month, defaulting to the current month
+func (uc *WorkUsecase) ListAbsences(ctx context.Context, yearMonth string) (*dto.AbsenceListResponse, error) {
+	if yearMonth == "" {
+		yearMonth = time.Now().Format("2006-01")
+	}
+	if _, err := time.Parse("2006-01", yearMonth); err != nil {
+		return nil, fmt.Errorf("invalid month %q, expected YYYY-MM", yearMonth)
+	}
+
+	absences, err := uc.repo.GetAbsencesByMonth(ctx, yearMonth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+
+	resp := &dto.AbsenceListResponse{
+		YearMonth:  yearMonth,
+		AbsentDays: domain.AbsentDays(absences),
+		Absences:   make([]dto.AbsenceResponse, 0, len(absences)),
+	}
+	for _, absence := range absences {
+		resp.Absences = append(resp.Absences, toAbsenceResponse(absence))
+	}
+	return resp, nil
+}
+
+// SaveAbsence creates an absence, or updates it when the request carries an ID
+func (uc *WorkUsecase) SaveAbsence(ctx context.Context, req *dto.AbsenceRequest) (*dto.AbsenceResponse, error) {
+	absence := &domain.Absence{
+		ID:     req.ID,
+		Date:   req.Date,
+		Type:   domain.AbsenceType(req.Type),
+		Amount: domain.AbsenceAmount(req.Amount),
+		Note:   req.Note,
+	}
+	if absence.Amount == "" {
+		absence.Amount = domain.AbsenceFullDay
+	}
+	if err := absence.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid absence: %w", err)
+	}
+	// Moving an absence to another date changes the expected work of both
+	dates := []string{absence.Date}
+	if absence.ID == "" {
+		absence.ID = uuid.New().String()
+	} else {
+		previous, err := uc.repo.GetAbsence(ctx, absence.ID)
+		switch {
+		case err == nil:
+			if previous.Date != absence.Date {
+				dates = append(dates, previous.Date)
+			}
+		case !errors.Is(err, domain.ErrAbsenceNotFound):
+			return nil, fmt.Errorf("failed to get absence: %w", err)
+		}
+	}
+
+	// A date cannot hold more than a full day of absence
+	existing, err := uc.repo.GetAbsencesByDate(ctx, absence.Date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+	others := make([]*domain.Absence, 0, len(existing))
+	for _, other := range existing {
+		if other.ID != absence.ID {
+			others = append(others, other)
+		}
+	}
+	if domain.AbsentDays(others)+absence.Days() > 1 {
+		return nil, fmt.Errorf("invalid absence: %s already has %.1f day(s) of absence", absence.Date, domain.AbsentDays(others))
+	}
+
+	if err := uc.repo.SaveAbsence(ctx, absence); err != nil {
+		return nil, fmt.Errorf("failed to save absence: %w", err)
+	}
+
+	slog.Info("[SaveAbsence] Absence saved", "date", absence.Date, "type", absence.Type, "amount", absence.Amount)
+
+	if err := uc.recordAbsenceBalances(ctx, dates...); err != nil {
+		return nil, err
+	}
+
+	resp := toAbsenceResponse(absence)
+	return &resp, nil
+}
+
+// DeleteAbsence deletes an absence by ID
+func (uc *WorkUsecase) DeleteAbsence(ctx context.Context, id string) error {
+	absence, err := uc.repo.GetAbsence(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get absence: %w", err)
+	}
+	if err := uc.repo.DeleteAbsence(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+
+	slog.Info("[DeleteAbsence] Absence deleted", "id", id, "date", absence.Date)
+	return uc.recordAbsenceBalances(ctx, absence.Date)
+}
+
+// recordAbsenceBalances recalculates the time bank entries of the dates an absence change touched,
+// leave changes the day's expected work
+func (uc *WorkUsecase) recordAbsenceBalances(ctx context.Context, dates ...string) error {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get config: %w", err)
+	}
+	for _, date := range dates {
+		if err := uc.recordDailyBalance(ctx, date, config); err != nil {
+			slog.Info("[Absence] Failed to update time bank", "date", date, "error", err)
+		}
+	}
+	return nil
+}
+
+// toAbsenceResponse converts an absence to its DTO
+func toAbsenceResponse(absence *domain.Absence) dto.AbsenceResponse {
+	return dto.AbsenceResponse{
+		ID:     absence.ID,
+		Date:   absence.Date,
+		Type:   string(absence.Type),
+		Amount: string(absence.Amount),
+		Days:   absence.Days(),
+		Note:   absence.Note,
+	}
+}
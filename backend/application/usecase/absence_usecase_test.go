@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbsence_RecalculatesTimeBank(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestUsecase(t)
+	workSession(t, uc, "2025-10-13", 9, 17)
+	workSession(t, uc, "2025-10-14", 9, 17)
+
+	// A half day of leave halves the expected work, the full day worked is surplus
+	absence, err := uc.SaveAbsence(ctx, &dto.AbsenceRequest{Date: "2025-10-13", Type: string(domain.AbsenceAnnualLeave), Amount: string(domain.AbsenceHalfDay)})
+	assert.NoError(t, err)
+	minutes, _ := dailyBalance(t, repo, "2025-10-13")
+	assert.Equal(t, 240, minutes)
+
+	// Moving the absence recalculates the date it left as well as the one it moved to
+	_, err = uc.SaveAbsence(ctx, &dto.AbsenceRequest{ID: absence.ID, Date: "2025-10-14", Type: string(domain.AbsenceAnnualLeave), Amount: string(domain.AbsenceHalfDay)})
+	assert.NoError(t, err)
+	minutes, _ = dailyBalance(t, repo, "2025-10-13")
+	assert.Equal(t, 0, minutes)
+	minutes, _ = dailyBalance(t, repo, "2025-10-14")
+	assert.Equal(t, 240, minutes)
+
+	// Deleting it restores the expected work of its date
+	assert.NoError(t, uc.DeleteAbsence(ctx, absence.ID))
+	minutes, _ = dailyBalance(t, repo, "2025-10-14")
+	assert.Equal(t, 0, minutes)
+	assert.ErrorIs(t, uc.DeleteAbsence(ctx, absence.ID), domain.ErrAbsenceNotFound)
+}
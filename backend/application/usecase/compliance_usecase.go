@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+)
+
+// GetCompliance evaluates the recorded work against the configured labor-law limits
+func (uc *WorkUsecase) GetCompliance(ctx context.Context) (*dto.ComplianceResponse, error) {
+	now := time.Now()
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	issues, err := uc.EvaluateCompliance(ctx, config, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ComplianceResponse{
+		EvaluatedAt: now,
+		Rules:       fromComplianceRules(config.ComplianceRules),
+		Issues:      toComplianceIssues(issues),
+	}, nil
+}
+
+// EvaluateCompliance loads the work days the compliance rules look at and evaluates them at a given time
+func (uc *WorkUsecase) EvaluateCompliance(ctx context.Context, config *domain.WorkConfig, now time.Time) ([]domain.ComplianceIssue, error) {
+	from, to := domain.CompliancePeriod(config, now)
+	sessions, err := uc.repo.GetSessionsByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	absences, err := uc.repo.GetAbsencesByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+
+	days := domain.BuildWorkDays(sessions, absences, config)
+	return domain.EvaluateCompliance(days, config, now), nil
+}
+
+// toComplianceRules converts and validates requested compliance rules
+func toComplianceRules(req *dto.ComplianceRules) (domain.ComplianceRules, error) {
+	rules := domain.ComplianceRules{
+		MaxMonthlyOvertimeMinutes: req.MaxMonthlyOvertimeMinutes,
+		MaxWeeklyWorkMinutes:      req.MaxWeeklyWorkMinutes,
+		MinRestMinutes:            req.MinRestMinutes,
+		WarningPercent:            req.WarningPercent,
+	}
+	if err := rules.Validate(); err != nil {
+		return rules, fmt.Errorf("invalid compliance rules: %w", err)
+	}
+	return rules, nil
+}
+
+// fromComplianceRules converts compliance rules to their DTO
+func fromComplianceRules(rules domain.ComplianceRules) dto.ComplianceRules {
+	return dto.ComplianceRules{
+		MaxMonthlyOvertimeMinutes: rules.MaxMonthlyOvertimeMinutes,
+		MaxWeeklyWorkMinutes:      rules.MaxWeeklyWorkMinutes,
+		MinRestMinutes:            rules.MinRestMinutes,
+		WarningPercent:            rules.WarningPercent,
+	}
+}
+
+// toComplianceIssues converts compliance issues to their DTOs
+func toComplianceIssues(issues []domain.ComplianceIssue) []dto.ComplianceIssue {
+	resp := make([]dto.ComplianceIssue, 0, len(issues))
+	for _, issue := range issues {
+		resp = append(resp, dto.ComplianceIssue{
+			Rule:          string(issue.Rule),
+			Severity:      string(issue.Severity),
+			Date:          issue.Date,
+			ActualMinutes: issue.ActualMinutes,
+			LimitMinutes:  issue.LimitMinutes,
+			Message:       issue.Message,
+		})
+	}
+	return resp
+}
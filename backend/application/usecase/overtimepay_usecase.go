@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+)
+
+// holidayLookups bounds the holiday provider lookups of a request that run at the same time
+const holidayLookups = 4
+
+// GetOvertimePay estimates a month's overtime pay, defaulting to the current month
+// Each day's overtime is paid at the multiplier of its official day type
+func (uc *WorkUsecase) GetOvertimePay(ctx context.Context, yearMonth string) (*dto.OvertimePayResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	if yearMonth == "" {
+		yearMonth = config.WorkDate(time.Now())[:7]
+	}
+	if _, err := time.Parse("2006-01", yearMonth); err != nil {
+		return nil, fmt.Errorf("invalid month %q, expected YYYY-MM", yearMonth)
+	}
+
+	sessions, err := uc.repo.GetSessionsByMonth(ctx, yearMonth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	absences, err := uc.repo.GetAbsencesByMonth(ctx, yearMonth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+
+	days := domain.BuildWorkDays(sessions, absences, config)
+	dates := make([]string, 0, len(days))
+	for _, day := range days {
+		dates = append(dates, day.Date)
+	}
+	dayTypes := uc.resolveDayTypes(ctx, dates, config)
+	for _, day := range days {
+		day.RestDay = dayTypes[day.Date] != domain.DayTypeWorkday
+	}
+
+	pay := domain.CalculateOvertimePay(days, dayTypes, yearMonth, config.OvertimePolicy, config.OvertimeHourlyRate)
+	return toOvertimePayResponse(pay), nil
+}
+
+// resolveDayTypes looks up the day types of dates, a few at a time
+// Dates not looked up by the time the context is done follow the work schedule
+func (uc *WorkUsecase) resolveDayTypes(ctx context.Context, dates []string, config *domain.WorkConfig) map[string]domain.DayType {
+	resolved := make([]domain.DayType, len(dates))
+	slots := make(chan struct{}, holidayLookups)
+	var wg sync.WaitGroup
+	for i, date := range dates {
+		select {
+		case <-ctx.Done():
+			resolved[i] = scheduledDayType(date, config)
+			continue
+		case slots <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			resolved[i] = uc.resolveDayType(ctx, date, config)
+		}()
+	}
+	wg.Wait()
+
+	dayTypes := make(map[string]domain.DayType, len(dates))
+	for i, date := range dates {
+		dayTypes[date] = resolved[i]
+	}
+	return dayTypes
+}
+
+// resolveDayType asks the holiday provider for a date's day type, a weekend the work cycle works on
+// is a workday. Falls back to the work schedule when the provider fails
+func (uc *WorkUsecase) resolveDayType(ctx context.Context, date string, config *domain.WorkConfig) domain.DayType {
+	dayType, err := uc.holidayProvider.GetDayType(ctx, date)
+	if err != nil {
+		slog.Info("[OvertimePay] Failed to get day type, using work schedule", "date", date, "error", err)
+		return scheduledDayType(date, config)
+	}
+	if dayType == domain.DayTypeRestDay && config.IsWeekendWorkday(date) {
+		return domain.DayTypeWorkday
+	}
+	return dayType
+}
+
+// scheduledDayType returns the day type of a date by the work schedule alone
+func scheduledDayType(date string, config *domain.WorkConfig) domain.DayType {
+	if config.IsScheduledWorkday(date) {
+		return domain.DayTypeWorkday
+	}
+	return domain.DayTypeRestDay
+}
+
+// toOvertimePayResponse converts an overtime pay estimate to its DTO
+func toOvertimePayResponse(pay *domain.OvertimePay) *dto.OvertimePayResponse {
+	resp := &dto.OvertimePayResponse{
+		YearMonth:      pay.YearMonth,
+		HourlyRate:     pay.HourlyRate,
+		WorkdayMinutes: pay.WorkdayMinutes,
+		RestDayMinutes: pay.RestDayMinutes,
+		HolidayMinutes: pay.HolidayMinutes,
+		WorkdayPay:     pay.WorkdayPay,
+		RestDayPay:     pay.RestDayPay,
+		HolidayPay:     pay.HolidayPay,
+		TotalPay:       pay.TotalPay,
+		Days:           make([]dto.DayOvertimePay, 0, len(pay.Days)),
+	}
+	for _, day := range pay.Days {
+		resp.Days = append(resp.Days, dto.DayOvertimePay{
+			Date:            day.Date,
+			DayType:         string(day.DayType),
+			Multiplier:      day.DayType.Multiplier(),
+			OvertimeMinutes: day.OvertimeMinutes,
+			Pay:             day.Pay,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// stalledCalendar is a holiday provider that does not answer until the lookup is given up
+type stalledCalendar struct{}
+
+func (stalledCalendar) GetDayType(ctx context.Context, date string) (domain.DayType, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestGetOvertimePay_StalledCalendar(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.holidayProvider = stalledCalendar{}
+	workSession(t, uc, "2025-10-10", 9, 21)
+	workSession(t, uc, "2025-10-11", 9, 13)
+
+	// The day types fall back to the work schedule once the request is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	pay, err := uc.GetOvertimePay(ctx, "2025-10")
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(started), time.Second)
+	assert.Len(t, pay.Days, 2)
+	assert.Equal(t, string(domain.DayTypeWorkday), pay.Days[0].DayType)
+	assert.Equal(t, 120, pay.Days[0].OvertimeMinutes)
+	assert.Equal(t, string(domain.DayTypeRestDay), pay.Days[1].DayType)
+	assert.Equal(t, 240, pay.Days[1].OvertimeMinutes)
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+)
+
+// ListProjects retrieves all projects, archived ones included
+func (uc *WorkUsecase) ListProjects(ctx context.Context) (*dto.ProjectListResponse, error) {
+	projects, err := uc.repo.GetProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	resp := &dto.ProjectListResponse{Projects: make([]dto.ProjectResponse, 0, len(projects))}
+	for _, project := range projects {
+		resp.Projects = append(resp.Projects, toProjectResponse(project))
+	}
+	return resp, nil
+}
+
+// SaveProject creates a project, or updates it when the request carries an ID
+func (uc *WorkUsecase) SaveProject(ctx context.Context, req *dto.ProjectRequest) (*dto.ProjectResponse, error) {
+	project := &domain.Project{
+		ID:       req.ID,
+		Name:     req.Name,
+		Code:     req.Code,
+		Archived: req.Archived,
+	}
+	if err := project.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid project: %w", err)
+	}
+	if project.ID == "" {
+		project.ID = uuid.New().String()
+	}
+
+	if err := uc.repo.SaveProject(ctx, project); err != nil {
+		return nil, fmt.Errorf("failed to save project: %w", err)
+	}
+
+	slog.Info("[SaveProject] Project saved", "id", project.ID, "name", project.Name)
+
+	resp := toProjectResponse(project)
+	return &resp, nil
+}
+
+// DeleteProject deletes a project that no time is allocated to
+func (uc *WorkUsecase) DeleteProject(ctx context.Context, id string) error {
+	if err := uc.repo.DeleteProject(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	slog.Info("[DeleteProject] Project deleted", "id", id)
+	return nil
+}
+
+// GetAllocations retrieves the time allocations of a session
+func (uc *WorkUsecase) GetAllocations(ctx context.Context, sessionID string) (*dto.AllocationsResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	session, err := uc.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	allocations, err := uc.repo.GetAllocationsBySession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocations: %w", err)
+	}
+
+	return toAllocationsResponse(session.Rounded(config.RoundingPolicy), allocations), nil
+}
+
+// SaveAllocations replaces the time allocations of a session,
+// together they cannot exceed the minutes worked in the session
+func (uc *WorkUsecase) SaveAllocations(ctx context.Context, req *dto.AllocationsRequest) (*dto.AllocationsResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	session, err := uc.repo.GetSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	projects, err := uc.repo.GetProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	byID := make(map[string]*domain.Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	allocations := make([]*domain.Allocation, 0, len(req.Allocations))
+	for _, item := range req.Allocations {
+		project, ok := byID[item.ProjectID]
+		if !ok {
+			return nil, fmt.Errorf("invalid allocation: %w: %s", domain.ErrProjectNotFound, item.ProjectID)
+		}
+		if project.Archived {
+			return nil, fmt.Errorf("%w: project %s is archived", domain.ErrInvalidAllocation, project.Name)
+		}
+		allocations = append(allocations, &domain.Allocation{
+			ID:        uuid.New().String(),
+			SessionID: session.ID,
+			ProjectID: item.ProjectID,
+			Task:      item.Task,
+			Minutes:   item.Minutes,
+			Percent:   item.Percent,
+		})
+	}
+
+	// Worked minutes follow the rounded punches, as overtime does
+	rounded := session.Rounded(config.RoundingPolicy)
+	if err := domain.ValidateAllocations(allocations, rounded.CalculateActualWorkMinutes()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.SaveAllocations(ctx, session.ID, allocations); err != nil {
+		return nil, fmt.Errorf("failed to save allocations: %w", err)
+	}
+	uc.events.Publish(domain.SessionEdited{At: time.Now(), Session: session, Change: domain.SessionChangeAllocations})
+
+	slog.Info("[SaveAllocations] Allocations saved", "session", session.ID, "count", len(allocations))
+
+	return toAllocationsResponse(rounded, allocations), nil
+}
+
+// clampAllocations fits the allocations of a checked-out session to its worked minutes after the session changed,
+// an open session is left alone until it is checked out again
+func (uc *WorkUsecase) clampAllocations(ctx context.Context, session *domain.WorkSession, config *domain.WorkConfig) error {
+	if session.Voided || !session.HasCheckedOut() {
+		return nil
+	}
+	allocations, err := uc.repo.GetAllocationsBySession(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get allocations: %w", err)
+	}
+
+	worked := session.Rounded(config.RoundingPolicy).CalculateActualWorkMinutes()
+	clamped, changed := domain.ClampAllocations(allocations, worked)
+	if !changed {
+		return nil
+	}
+	if err := uc.repo.SaveAllocations(ctx, session.ID, clamped); err != nil {
+		return fmt.Errorf("failed to save allocations: %w", err)
+	}
+
+	slog.Info("[Allocations] Allocations clamped to the worked minutes", "session", session.ID, "worked_minutes", worked)
+	return nil
+}
+
+// GetProjectTotals sums the time allocated per project between two dates (inclusive),
+// defaulting to the current month up to today
+func (uc *WorkUsecase) GetProjectTotals(ctx context.Context, from, to string) (*dto.ProjectTotalsResponse, error) {
+	now := time.Now()
+	if from == "" {
+		from = now.Format("2006-01") + "-01"
+	}
+	if to == "" {
+		to = now.Format("2006-01-02")
+	}
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if from > to {
+		return nil, fmt.Errorf("from %s is after to %s", from, to)
+	}
+
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	sessions, err := uc.repo.GetSessionsByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	allocations, err := uc.repo.GetAllocationsByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocations: %w", err)
+	}
+	projects, err := uc.repo.GetProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	byID := make(map[string]*domain.Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	rounded := make([]*domain.WorkSession, 0, len(sessions))
+	for _, session := range sessions {
+		rounded = append(rounded, session.Rounded(config.RoundingPolicy))
+	}
+	totals, unallocated := domain.SumAllocations(rounded, allocations)
+
+	resp := &dto.ProjectTotalsResponse{
+		From:               from,
+		To:                 to,
+		UnallocatedMinutes: unallocated,
+		Projects:           make([]dto.ProjectTotal, 0, len(totals)),
+	}
+	for _, total := range totals {
+		item := dto.ProjectTotal{
+			ProjectID: total.ProjectID,
+			Minutes:   total.Minutes,
+			Tasks:     make([]dto.TaskTotal, 0, len(total.Tasks)),
+		}
+		if project, ok := byID[total.ProjectID]; ok {
+			item.Name = project.Name
+			item.Code = project.Code
+		}
+		for task, minutes := range total.Tasks {
+			item.Tasks = append(item.Tasks, dto.TaskTotal{Task: task, Minutes: minutes})
+		}
+		sort.Slice(item.Tasks, func(i, j int) bool {
+			if item.Tasks[i].Minutes != item.Tasks[j].Minutes {
+				return item.Tasks[i].Minutes > item.Tasks[j].Minutes
+			}
+			return item.Tasks[i].Task < item.Tasks[j].Task
+		})
+		resp.Projects = append(resp.Projects, item)
+	}
+	return resp, nil
+}
+
+// toProjectResponse converts a project to its DTO
+func toProjectResponse(project *domain.Project) dto.ProjectResponse {
+	return dto.ProjectResponse{
+		ID:       project.ID,
+		Name:     project.Name,
+		Code:     project.Code,
+		Archived: project.Archived,
+	}
+}
+
+// toAllocationsResponse converts the allocations of a session to their DTO
+func toAllocationsResponse(session *domain.WorkSession, allocations []*domain.Allocation) *dto.AllocationsResponse {
+	worked := session.CalculateActualWorkMinutes()
+	resp := &dto.AllocationsResponse{
+		SessionID:     session.ID,
+		Date:          session.Date,
+		WorkedMinutes: worked,
+		Allocations:   make([]dto.AllocationResponse, 0, len(allocations)),
+	}
+	for _, allocation := range allocations {
+		minutes := allocation.ResolveMinutes(worked)
+		resp.AllocatedMinutes += minutes
+		resp.Allocations = append(resp.Allocations, dto.AllocationResponse{
+			ID:              allocation.ID,
+			ProjectID:       allocation.ProjectID,
+			Task:            allocation.Task,
+			Minutes:         allocation.Minutes,
+			Percent:         allocation.Percent,
+			ResolvedMinutes: minutes,
+		})
+	}
+	return resp
+}
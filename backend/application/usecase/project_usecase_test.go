@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocations_FollowSessionChanges(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestUsecase(t)
+	workSession(t, uc, "2025-10-13", 9, 17)
+	sessions, err := repo.GetSessionsByDate(ctx, "2025-10-13")
+	assert.NoError(t, err)
+	sessionID := sessions[0].ID
+
+	project, err := uc.SaveProject(ctx, &dto.ProjectRequest{Name: "Billing"})
+	assert.NoError(t, err)
+	archived, err := uc.SaveProject(ctx, &dto.ProjectRequest{Name: "Legacy", Archived: true})
+	assert.NoError(t, err)
+
+	// Archived projects take no new time, too much time is a bad request as well
+	_, err = uc.SaveAllocations(ctx, &dto.AllocationsRequest{SessionID: sessionID, Allocations: []dto.AllocationRequest{{ProjectID: archived.ID, Minutes: 60}}})
+	assert.ErrorIs(t, err, domain.ErrInvalidAllocation)
+	_, err = uc.SaveAllocations(ctx, &dto.AllocationsRequest{SessionID: sessionID, Allocations: []dto.AllocationRequest{{ProjectID: project.ID, Minutes: 481}}})
+	assert.ErrorIs(t, err, domain.ErrInvalidAllocation)
+
+	_, err = uc.SaveAllocations(ctx, &dto.AllocationsRequest{SessionID: sessionID, Allocations: []dto.AllocationRequest{{ProjectID: project.ID, Minutes: 420}}})
+	assert.NoError(t, err)
+
+	// Correcting the check-out to 13:00 leaves 4 hours to allocate
+	_, err = uc.CheckOut(ctx, &dto.CheckOutRequest{CheckOutTime: at(t, "2025-10-13", 13, 0)})
+	assert.NoError(t, err)
+	allocations, err := uc.GetAllocations(ctx, sessionID)
+	assert.NoError(t, err)
+	assert.Equal(t, 240, allocations.WorkedMinutes)
+	assert.Equal(t, 240, allocations.AllocatedMinutes)
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+)
+
+// recordPunch applies a clock event to the session it concerns and records the punch together with the session
+// Returns the session and the state it was in before, empty for a session the punch started
+// A punch the source precedence keeps from changing a session is still recorded, and ErrOutranked returned
+func (uc *WorkUsecase) recordPunch(ctx context.Context, config *domain.WorkConfig, kind domain.PunchKind, at time.Time, source domain.PunchSource, sessionID string) (*domain.WorkSession, domain.SessionState, error) {
+	var sessions []*domain.WorkSession
+	if kind == domain.PunchVoid {
+		session, err := uc.repo.GetSession(ctx, sessionID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get session: %w", err)
+		}
+		sessions = append(sessions, session)
+	} else {
+		// The sessions of the work date, and the open one which may have started the day before
+		var err error
+		sessions, err = uc.repo.GetSessionsByDate(ctx, config.WorkDate(at))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get sessions: %w", err)
+		}
+		open, err := uc.repo.GetOpenSession(ctx, config.EarliestOpenDate(at))
+		if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, "", fmt.Errorf("failed to get open session: %w", err)
+		}
+		if open != nil && !slices.ContainsFunc(sessions, func(s *domain.WorkSession) bool { return s.ID == open.ID }) {
+			sessions = append(sessions, open)
+		}
+	}
+
+	punch := &domain.Punch{
+		ID:         uuid.New().String(),
+		Kind:       kind,
+		At:         at,
+		Source:     source,
+		SessionID:  sessionID,
+		RecordedAt: time.Now(),
+	}
+	session, previous, err := domain.ApplyPunch(sessions, punch, config)
+	if errors.Is(err, domain.ErrOutranked) {
+		if err := uc.repo.RecordPunch(ctx, punch, nil); err != nil {
+			return nil, "", fmt.Errorf("failed to record punch: %w", err)
+		}
+		return nil, "", err
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if err := uc.repo.RecordPunch(ctx, punch, session); err != nil {
+		return nil, "", fmt.Errorf("failed to record punch: %w", err)
+	}
+
+	// The punch may have shortened the session below the time allocated to projects
+	if err := uc.clampAllocations(ctx, session, config); err != nil {
+		slog.Info("[Allocations] Failed to clamp allocations", "session", session.ID, "error", err)
+	}
+	return session, previous, nil
+}
+
+// hasPunch returns true if a punch of the kind and source was already recorded at the time
+func (uc *WorkUsecase) hasPunch(ctx context.Context, kind domain.PunchKind, at time.Time, source domain.PunchSource) (bool, error) {
+	date := at.Format("2006-01-02")
+	punches, err := uc.repo.GetPunches(ctx, date, date)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(punches, func(p *domain.Punch) bool {
+		return p.Kind == kind && p.Source == source && p.At.Equal(at)
+	}), nil
+}
+
+// ListPunches retrieves the punch log between two dates (inclusive), defaulting to the current month up to today
+func (uc *WorkUsecase) ListPunches(ctx context.Context, from, to string) (*dto.PunchListResponse, error) {
+	now := time.Now()
+	if from == "" {
+		from = now.Format("2006-01") + "-01"
+	}
+	if to == "" {
+		to = now.Format("2006-01-02")
+	}
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	punches, err := uc.repo.GetPunches(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get punches: %w", err)
+	}
+
+	resp := &dto.PunchListResponse{From: from, To: to, Punches: make([]dto.PunchResponse, 0, len(punches))}
+	for _, punch := range punches {
+		resp.Punches = append(resp.Punches, toPunchResponse(punch))
+	}
+	return resp, nil
+}
+
+// RecordInboundPunch records a clock event received from an inbound webhook, such as a door badge reader
+func (uc *WorkUsecase) RecordInboundPunch(ctx context.Context, req *dto.PunchRequest) (*dto.InboundPunchResponse, error) {
+	var sessionID string
+	switch domain.PunchKind(req.Kind) {
+	case domain.PunchCheckIn:
+		resp, err := uc.checkIn(ctx, req.Time, domain.PunchSourceWebhook)
+		if err != nil {
+			return nil, err
+		}
+		sessionID = resp.SessionID
+	case domain.PunchCheckOut:
+		resp, err := uc.checkOut(ctx, req.Time, domain.PunchSourceWebhook)
+		if err != nil {
+			return nil, err
+		}
+		sessionID = resp.SessionID
+	case domain.PunchBreakStart:
+		resp, err := uc.startBreak(ctx, req.Time, domain.PunchSourceWebhook)
+		if err != nil {
+			return nil, err
+		}
+		sessionID = resp.SessionID
+	case domain.PunchBreakEnd:
+		resp, err := uc.endBreak(ctx, req.Time, domain.PunchSourceWebhook)
+		if err != nil {
+			return nil, err
+		}
+		sessionID = resp.SessionID
+	default:
+		return nil, fmt.Errorf("%w %q, expected check_in, check_out, break_start or break_end", domain.ErrInvalidPunchKind, req.Kind)
+	}
+
+	return &dto.InboundPunchResponse{
+		Kind:      req.Kind,
+		Time:      req.Time,
+		Source:    string(domain.PunchSourceWebhook),
+		SessionID: sessionID,
+	}, nil
+}
+
+// RebuildSessions derives every session again from the punch log under the current rules,
+// such as after the day rollover hour or the weekly schedule changed
+func (uc *WorkUsecase) RebuildSessions(ctx context.Context) (*dto.RebuildSessionsResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	punches, err := uc.repo.GetPunches(ctx, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get punches: %w", err)
+	}
+
+	sessions, skipped := domain.DeriveSessions(punches, config)
+	if err := uc.repo.ReplaceSessions(ctx, sessions); err != nil {
+		return nil, fmt.Errorf("failed to replace sessions: %w", err)
+	}
+
+	// Allocations of the sessions kept must fit their rebuilt times
+	for _, session := range sessions {
+		if err := uc.clampAllocations(ctx, session, config); err != nil {
+			return nil, fmt.Errorf("failed to clamp allocations of %s: %w", session.ID, err)
+		}
+	}
+
+	// Daily time bank entries follow the sessions, a date that lost all of its sessions loses its entry
+	if _, err := uc.recalculateDailyBalances(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to update time bank: %w", err)
+	}
+
+	slog.Info("[RebuildSessions] Sessions rebuilt", "punches", len(punches), "sessions", len(sessions), "skipped", len(skipped))
+
+	resp := &dto.RebuildSessionsResponse{
+		Punches:  len(punches),
+		Sessions: len(sessions),
+		Skipped:  make([]dto.PunchResponse, 0, len(skipped)),
+	}
+	for _, punch := range skipped {
+		resp.Skipped = append(resp.Skipped, toPunchResponse(punch))
+	}
+	return resp, nil
+}
+
+// ListConflicts retrieves the days between two dates (inclusive) where a session time differs from the latest
+// value the HR system reported, defaulting to the current month up to today
+func (uc *WorkUsecase) ListConflicts(ctx context.Context, from, to string) (*dto.ConflictListResponse, error) {
+	now := time.Now()
+	if from == "" {
+		from = now.Format("2006-01") + "-01"
+	}
+	if to == "" {
+		to = now.Format("2006-01-02")
+	}
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	sessions, err := uc.repo.GetSessionsByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	punches, err := uc.repo.GetPunches(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get punches: %w", err)
+	}
+
+	resp := &dto.ConflictListResponse{From: from, To: to, Conflicts: make([]dto.Conflict, 0)}
+	for _, conflict := range domain.FindConflicts(sessions, punches, config) {
+		// A punch after midnight may belong to a workday outside the range
+		if conflict.Date < from || conflict.Date > to {
+			continue
+		}
+		resp.Conflicts = append(resp.Conflicts, dto.Conflict{
+			Date:        conflict.Date,
+			Kind:        string(conflict.Kind),
+			LocalTime:   conflict.Local,
+			LocalSource: string(conflict.LocalSource),
+			HRTime:      conflict.HR,
+			HRSource:    string(conflict.HRSource),
+			DiffMinutes: conflict.DiffMinutes(),
+			KeptSource:  string(conflict.KeptSource(config.SourcePrecedence)),
+		})
+	}
+	return resp, nil
+}
+
+// toPunchResponse converts a punch to its DTO
+func toPunchResponse(punch *domain.Punch) dto.PunchResponse {
+	return dto.PunchResponse{
+		ID:         punch.ID,
+		Kind:       string(punch.Kind),
+		Time:       punch.At,
+		Source:     string(punch.Source),
+		SessionID:  punch.SessionID,
+		RecordedAt: punch.RecordedAt,
+	}
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuildSessions_FollowsRuleChange(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestUsecase(t)
+
+	// A night shift from 02:00 to 06:00 belongs to its calendar date without a rollover hour
+	workSession(t, uc, "2025-10-14", 2, 6)
+	minutes, ok := dailyBalance(t, repo, "2025-10-14")
+	assert.True(t, ok)
+	assert.Equal(t, -240, minutes)
+
+	config, err := repo.GetConfig(ctx)
+	assert.NoError(t, err)
+	config.DayRolloverHour = 4
+	assert.NoError(t, repo.SaveConfig(ctx, config))
+
+	resp, err := uc.RebuildSessions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Punches)
+	assert.Equal(t, 1, resp.Sessions)
+	assert.Empty(t, resp.Skipped)
+
+	// The shift moved to the previous workday, the date it left keeps no stale entry
+	sessions, err := repo.GetSessionsByDate(ctx, "2025-10-13")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	minutes, ok = dailyBalance(t, repo, "2025-10-13")
+	assert.True(t, ok)
+	assert.Equal(t, -240, minutes)
+	_, ok = dailyBalance(t, repo, "2025-10-14")
+	assert.False(t, ok)
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+)
+
+// UpdateSessionNote replaces the note and tags of a session
+func (uc *WorkUsecase) UpdateSessionNote(ctx context.Context, req *dto.SessionNoteRequest) (*dto.SessionNoteResponse, error) {
+	session, err := uc.repo.GetSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if err := session.SetNote(req.Note, req.Tags); err != nil {
+		return nil, fmt.Errorf("invalid note: %w", err)
+	}
+	if err := uc.repo.SaveSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	uc.events.Publish(domain.SessionEdited{At: time.Now(), Session: session, Change: domain.SessionChangeNote})
+
+	slog.Info("[UpdateSessionNote] Note saved", "session", session.ID, "tags", session.Tags)
+
+	return &dto.SessionNoteResponse{
+		SessionID: session.ID,
+		Date:      session.Date,
+		Note:      session.Note,
+		Tags:      nonNilTags(session.Tags),
+	}, nil
+}
+
+// VoidSession marks a session as recorded by mistake, leaving it out of every calculation
+func (uc *WorkUsecase) VoidSession(ctx context.Context, req *dto.VoidSessionRequest) (*dto.VoidSessionResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	session, _, err := uc.recordPunch(ctx, config, domain.PunchVoid, time.Now(), domain.PunchSourceManual, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	uc.events.Publish(domain.SessionEdited{At: time.Now(), Session: session, Change: domain.SessionChangeVoid})
+
+	// The day's time bank entry no longer counts the session
+	if err := uc.recordDailyBalance(ctx, session.Date, config); err != nil {
+		slog.Info("[VoidSession] Failed to update time bank", "error", err)
+	}
+
+	slog.Info("[VoidSession] Session voided", "session", session.ID, "date", session.Date)
+
+	return &dto.VoidSessionResponse{
+		SessionID: session.ID,
+		Date:      session.Date,
+		State:     string(session.State()),
+	}, nil
+}
+
+// SearchSessions finds the sessions between two dates matching a tag, a text and a minimum overtime of their day,
+// the range defaults to the current month up to today
+func (uc *WorkUsecase) SearchSessions(ctx context.Context, req *dto.SessionSearchRequest) (*dto.SessionSearchResponse, error) {
+	now := time.Now()
+	filter := domain.SessionFilter{
+		From:               req.From,
+		To:                 req.To,
+		Tag:                req.Tag,
+		Text:               req.Text,
+		MinOvertimeMinutes: req.MinOvertimeMinutes,
+	}
+	if filter.From == "" {
+		filter.From = now.Format("2006-01") + "-01"
+	}
+	if filter.To == "" {
+		filter.To = now.Format("2006-01-02")
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	sessions, err := uc.repo.GetSessionsByDateRange(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	absences, err := uc.repo.GetAbsencesByDateRange(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences: %w", err)
+	}
+
+	raw := make(map[string]*domain.WorkSession, len(sessions))
+	for _, session := range sessions {
+		raw[session.ID] = session
+	}
+
+	resp := &dto.SessionSearchResponse{
+		From:     filter.From,
+		To:       filter.To,
+		Sessions: make([]dto.SessionSearchResult, 0),
+	}
+	for _, day := range domain.BuildWorkDays(sessions, absences, config) {
+		overtime := day.CalculateOvertime(config.OvertimePolicy)
+		matched := false
+		// Worked minutes and overtime follow the rounded punches, the raw ones are shown
+		for _, rounded := range day.Sessions {
+			if !filter.Matches(rounded, overtime) {
+				continue
+			}
+			matched = true
+			session := raw[rounded.ID]
+			resp.Sessions = append(resp.Sessions, dto.SessionSearchResult{
+				SessionID:          session.ID,
+				Date:               session.Date,
+				CheckInTime:        session.CheckIn,
+				CheckOutTime:       session.CheckOut,
+				WorkedMinutes:      rounded.CalculateActualWorkMinutes(),
+				DayOvertimeMinutes: overtime,
+				Note:               session.Note,
+				Tags:               nonNilTags(session.Tags),
+			})
+		}
+		if matched && overtime > 0 {
+			resp.OvertimeMinutes += overtime
+		}
+	}
+	return resp, nil
+}
+
+// nonNilTags returns an empty list for a session without tags so that it encodes as []
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
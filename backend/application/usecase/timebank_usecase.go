@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+)
+
+// GetTimeBankBalance retrieves the current balance of the overtime time bank
+func (uc *WorkUsecase) GetTimeBankBalance(ctx context.Context) (*dto.TimeBankBalanceResponse, error) {
+	balance, err := uc.repo.SumTimeBankMinutes(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time bank balance: %w", err)
+	}
+
+	return &dto.TimeBankBalanceResponse{
+		BalanceMinutes: balance,
+		AsOf:           time.Now(),
+	}, nil
+}
+
+// GetTimeBankHistory retrieves the time bank entries between two dates with the running balance
+func (uc *WorkUsecase) GetTimeBankHistory(ctx context.Context, from, to string) (*dto.TimeBankHistoryResponse, error) {
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	opening := 0
+	if from != "" {
+		var err error
+		opening, err = uc.repo.SumTimeBankMinutes(ctx, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get opening balance: %w", err)
+		}
+	}
+
+	entries, err := uc.repo.GetTimeBankEntries(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time bank entries: %w", err)
+	}
+
+	resp := &dto.TimeBankHistoryResponse{
+		From:                  from,
+		To:                    to,
+		OpeningBalanceMinutes: opening,
+		ClosingBalanceMinutes: opening,
+		Entries:               make([]dto.TimeBankEntryResponse, 0, len(entries)),
+	}
+	for _, point := range domain.RunningBalance(opening, entries) {
+		resp.Entries = append(resp.Entries, toTimeBankEntryResponse(point.Entry, point.BalanceMinutes))
+		resp.ClosingBalanceMinutes = point.BalanceMinutes
+	}
+	return resp, nil
+}
+
+// AddTimeBankEntry records a manual adjustment or a comp-off debit
+func (uc *WorkUsecase) AddTimeBankEntry(ctx context.Context, req *dto.TimeBankEntryRequest) (*dto.TimeBankEntryResponse, error) {
+	entry := &domain.TimeBankEntry{
+		ID:        uuid.New().String(),
+		Date:      req.Date,
+		Kind:      domain.TimeBankEntryKind(req.Kind),
+		Minutes:   req.Minutes,
+		Note:      req.Note,
+		CreatedAt: time.Now(),
+	}
+	if entry.Date == "" {
+		entry.Date = entry.CreatedAt.Format("2006-01-02")
+	}
+	// Comp-off is requested as the time taken and always debits the bank
+	if entry.Kind == domain.TimeBankCompOff && entry.Minutes > 0 {
+		entry.Minutes = -entry.Minutes
+	}
+	if err := entry.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid time bank entry: %w", err)
+	}
+
+	if err := uc.repo.SaveTimeBankEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to save time bank entry: %w", err)
+	}
+
+	balance, err := uc.repo.SumTimeBankMinutes(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time bank balance: %w", err)
+	}
+
+	slog.Info("[TimeBank] Entry added", "date", entry.Date, "kind", entry.Kind, "minutes", entry.Minutes, "balance", balance)
+
+	resp := toTimeBankEntryResponse(entry, balance)
+	return &resp, nil
+}
+
+// DeleteTimeBankEntry deletes a manual time bank entry, daily entries follow the sessions
+func (uc *WorkUsecase) DeleteTimeBankEntry(ctx context.Context, id string) error {
+	if domain.IsDailyTimeBankEntryID(id) {
+		return fmt.Errorf("daily entries cannot be deleted, edit the sessions instead")
+	}
+	if err := uc.repo.DeleteTimeBankEntry(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete time bank entry: %w", err)
+	}
+
+	slog.Info("[TimeBank] Entry deleted", "id", id)
+	return nil
+}
+
+// recordDailyBalance credits or debits a day's surplus or deficit to the time bank once it is checked out,
+// and removes the day's entry while it is open again
+func (uc *WorkUsecase) recordDailyBalance(ctx context.Context, date string, config *domain.WorkConfig) error {
+	day, err := uc.GetWorkDay(ctx, date, config)
+	if err != nil {
+		return err
+	}
+
+	entry := domain.NewDailyTimeBankEntry(day.Rounded(config.RoundingPolicy), time.Now())
+	if entry == nil {
+		err := uc.repo.DeleteTimeBankEntry(ctx, domain.DailyTimeBankEntryID(date))
+		if err != nil && !errors.Is(err, domain.ErrTimeBankEntryNotFound) {
+			return fmt.Errorf("failed to remove daily time bank entry: %w", err)
+		}
+		return nil
+	}
+
+	if err := uc.repo.SaveTimeBankEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to save daily time bank entry: %w", err)
+	}
+	return nil
+}
+
+// RecalculateTimeBank records the daily time bank entry of every date again under the current config,
+// such as for the days worked before the time bank kept daily entries
+// Returns the number of daily entries recorded
+func (uc *WorkUsecase) RecalculateTimeBank(ctx context.Context) (int, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get config: %w", err)
+	}
+	return uc.recalculateDailyBalances(ctx, config)
+}
+
+// recalculateDailyBalances records the daily entry of every checked-out date from all sessions and absences,
+// and removes the daily entries of dates that have none. Returns the number of daily entries recorded
+func (uc *WorkUsecase) recalculateDailyBalances(ctx context.Context, config *domain.WorkConfig) (int, error) {
+	sessions, err := uc.repo.GetSessionsByDateRange(ctx, "", "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	var absences []*domain.Absence
+	if len(sessions) > 0 {
+		absences, err = uc.repo.GetAbsencesByDateRange(ctx, sessions[0].Date, sessions[len(sessions)-1].Date)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get absences: %w", err)
+		}
+	}
+
+	now := time.Now()
+	recorded := make(map[string]bool)
+	for _, day := range domain.BuildWorkDays(sessions, absences, config) {
+		entry := domain.NewDailyTimeBankEntry(day, now)
+		if entry == nil {
+			continue
+		}
+		if err := uc.repo.SaveTimeBankEntry(ctx, entry); err != nil {
+			return 0, fmt.Errorf("failed to save daily time bank entry for %s: %w", day.Date, err)
+		}
+		recorded[day.Date] = true
+	}
+
+	entries, err := uc.repo.GetTimeBankEntries(ctx, "", "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get time bank entries: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Kind != domain.TimeBankDaily || recorded[entry.Date] {
+			continue
+		}
+		if err := uc.repo.DeleteTimeBankEntry(ctx, entry.ID); err != nil {
+			return 0, fmt.Errorf("failed to remove daily time bank entry for %s: %w", entry.Date, err)
+		}
+	}
+	return len(recorded), nil
+}
+
+// toTimeBankEntryResponse converts a time bank entry to its DTO
+func toTimeBankEntryResponse(entry *domain.TimeBankEntry, balance int) dto.TimeBankEntryResponse {
+	return dto.TimeBankEntryResponse{
+		ID:             entry.ID,
+		Date:           entry.Date,
+		Kind:           string(entry.Kind),
+		Minutes:        entry.Minutes,
+		Note:           entry.Note,
+		CreatedAt:      entry.CreatedAt,
+		BalanceMinutes: balance,
+	}
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeBank_DailyEntries(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestUsecase(t)
+
+	// An exact 8h day books nothing, a 10h day credits two hours
+	workSession(t, uc, "2025-10-13", 9, 17)
+	minutes, ok := dailyBalance(t, repo, "2025-10-13")
+	assert.True(t, ok)
+	assert.Equal(t, 0, minutes)
+	workSession(t, uc, "2025-10-14", 9, 19)
+	minutes, _ = dailyBalance(t, repo, "2025-10-14")
+	assert.Equal(t, 120, minutes)
+
+	// Checking in again reopens the day, its entry waits for the next check-out
+	_, err := uc.CheckIn(ctx, &dto.CheckInRequest{CheckInTime: at(t, "2025-10-14", 20, 0)})
+	assert.NoError(t, err)
+	_, ok = dailyBalance(t, repo, "2025-10-14")
+	assert.False(t, ok)
+	_, err = uc.CheckOut(ctx, &dto.CheckOutRequest{CheckOutTime: at(t, "2025-10-14", 21, 0)})
+	assert.NoError(t, err)
+	minutes, _ = dailyBalance(t, repo, "2025-10-14")
+	assert.Equal(t, 180, minutes)
+
+	entry, err := uc.AddTimeBankEntry(ctx, &dto.TimeBankEntryRequest{Date: "2025-10-15", Kind: string(domain.TimeBankCompOff), Minutes: 60})
+	assert.NoError(t, err)
+	assert.Equal(t, -60, entry.Minutes)
+	assert.Equal(t, 120, entry.BalanceMinutes)
+
+	history, err := uc.GetTimeBankHistory(ctx, "2025-10-14", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, history.OpeningBalanceMinutes)
+	assert.Equal(t, 120, history.ClosingBalanceMinutes)
+	assert.Len(t, history.Entries, 2)
+
+	// Daily entries follow the sessions, only manual ones can be deleted
+	assert.Error(t, uc.DeleteTimeBankEntry(ctx, domain.DailyTimeBankEntryID("2025-10-14")))
+	assert.NoError(t, uc.DeleteTimeBankEntry(ctx, entry.ID))
+	balance, err := uc.GetTimeBankBalance(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 180, balance.BalanceMinutes)
+}
+
+func TestTimeBank_Recalculate(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestUsecase(t)
+
+	_, err := uc.CheckIn(ctx, &dto.CheckInRequest{CheckInTime: at(t, "2025-10-13", 9, 10)})
+	assert.NoError(t, err)
+	_, err = uc.CheckOut(ctx, &dto.CheckOutRequest{CheckOutTime: at(t, "2025-10-13", 17, 20)})
+	assert.NoError(t, err)
+	minutes, _ := dailyBalance(t, repo, "2025-10-13")
+	assert.Equal(t, 10, minutes)
+
+	// A new rounding policy applies to the days already booked
+	config, err := uc.GetConfig(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, uc.UpdateConfig(ctx, &dto.ConfigRequest{
+		WorkHours:      config.WorkHours,
+		RoundingPolicy: &dto.RoundingPolicy{IntervalMinutes: 15, CheckIn: string(domain.RoundingUp), CheckOut: string(domain.RoundingDown)},
+	}))
+	minutes, _ = dailyBalance(t, repo, "2025-10-13")
+	assert.Equal(t, 0, minutes)
+
+	// Days worked before the time bank get their entries, a daily entry without sessions is removed
+	workSession(t, uc, "2025-10-14", 9, 19)
+	assert.NoError(t, repo.DeleteTimeBankEntry(ctx, domain.DailyTimeBankEntryID("2025-10-14")))
+	assert.NoError(t, repo.SaveTimeBankEntry(ctx, &domain.TimeBankEntry{ID: domain.DailyTimeBankEntryID("2025-10-15"), Date: "2025-10-15", Kind: domain.TimeBankDaily, Minutes: 60}))
+	days, err := uc.RecalculateTimeBank(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, days)
+	minutes, ok := dailyBalance(t, repo, "2025-10-14")
+	assert.True(t, ok)
+	assert.Equal(t, 120, minutes)
+	_, ok = dailyBalance(t, repo, "2025-10-15")
+	assert.False(t, ok)
+}
@@ -2,137 +2,427 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/simon0-o/offline_me/backend/domain"
 	"github.com/simon0-o/offline_me/backend/infrastructure/client"
 	"github.com/simon0-o/offline_me/backend/interfaces/dto"
 )
 
+// eventTimeout bounds the storage work of an event subscriber, which has no request context to follow
+const eventTimeout = 10 * time.Second
+
 // WorkUsecase handles work tracking business logic
 type WorkUsecase struct {
 	repo               domain.Repository
+	events             domain.EventPublisher
 	attendanceProvider domain.AttendanceProvider
+	holidayProvider    domain.HolidayProvider
 }
 
-// NewWorkUsecase creates a new work usecase instance
-func NewWorkUsecase(repo domain.Repository) *WorkUsecase {
+// NewWorkUsecase creates a new work usecase instance, changes to the work record are published to events
+func NewWorkUsecase(repo domain.Repository, events domain.EventPublisher) *WorkUsecase {
 	return &WorkUsecase{
 		repo:               repo,
+		events:             events,
 		attendanceProvider: client.NewHRAPIClient(),
+		holidayProvider:    client.NewHolidayAPIClient(),
 	}
 }
 
 // CheckIn processes a check-in request
-func (uc *WorkUsecase) CheckIn(req *dto.CheckInRequest) (*dto.CheckInResponse, error) {
-	today := req.CheckInTime.Format("2006-01-02")
-	config, err := uc.repo.GetConfig()
+// Checking in again while the latest session is open corrects its check-in time,
+// checking in after a check-out starts another session of the same day
+func (uc *WorkUsecase) CheckIn(ctx context.Context, req *dto.CheckInRequest) (*dto.CheckInResponse, error) {
+	return uc.checkIn(ctx, req.CheckInTime, domain.PunchSourceManual)
+}
+
+// checkIn records a check-in punch from the given source
+func (uc *WorkUsecase) checkIn(ctx context.Context, at time.Time, source domain.PunchSource) (*dto.CheckInResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
-	// Check if already checked in today
-	existingSession := uc.repo.GetTodaySession(today)
-
-	var session *domain.WorkSession
-	if existingSession != nil {
-		// Re-check-in: update existing session
-		existingSession.CheckIn = req.CheckInTime
-		existingSession.WorkHours = config.DefaultWorkHours
-		existingSession.CheckOut = nil // Reset checkout time
-		session = existingSession
-		slog.Info("[CheckIn] Re-checking in", "date", today, "time", req.CheckInTime)
+	session, previous, err := uc.recordPunch(ctx, config, domain.PunchCheckIn, at, source, "")
+	if err != nil {
+		return nil, err
+	}
+	today := session.Date
+	if previous == "" {
+		slog.Info("[CheckIn] New check-in", "date", today, "time", at, "source", source)
+		uc.events.Publish(domain.SessionCheckedIn{At: time.Now(), Session: session})
 	} else {
-		// New check-in: create new session
-		session = &domain.WorkSession{
-			ID:        uuid.New().String(),
-			Date:      today,
-			CheckIn:   req.CheckInTime,
-			WorkHours: config.DefaultWorkHours,
-		}
-		slog.Info("[CheckIn] New check-in", "date", today, "time", req.CheckInTime)
+		slog.Info("[CheckIn] Re-checking in", "date", today, "time", at, "source", source)
+		uc.events.Publish(domain.SessionEdited{At: time.Now(), Session: session, Change: domain.SessionChangeCheckIn})
 	}
 
-	if err := uc.repo.SaveSession(session); err != nil {
-		return nil, fmt.Errorf("failed to save session: %w", err)
+	// A re-check-in reopens the day, its time bank entry waits for the next check-out
+	if err := uc.recordDailyBalance(ctx, today, config); err != nil {
+		slog.Info("[CheckIn] Failed to update time bank", "error", err)
+	}
+
+	day, err := uc.GetWorkDay(ctx, today, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the first check-in of a day can be late, not the return of a split shift
+	rounded := session.Rounded(config.RoundingPolicy)
+	lateMinutes := 0
+	if first := day.FirstSession(); first != nil && first.ID == session.ID {
+		lateMinutes = day.Rounded(config.RoundingPolicy).LateMinutes(config.CoreHours)
 	}
 
 	return &dto.CheckInResponse{
-		SessionID:    session.ID,
-		CheckInTime:  session.CheckIn,
-		CheckOutTime: session.CalculateExpectedCheckOut(),
-		WorkHours:    session.WorkHours,
+		SessionID:          session.ID,
+		CheckInTime:        session.CheckIn,
+		RoundedCheckInTime: rounded.CheckIn,
+		CheckOutTime:       day.Rounded(config.RoundingPolicy).CalculateExpectedCheckOut(at),
+		WorkHours:          session.WorkHours,
+		LateMinutes:        lateMinutes,
 	}, nil
 }
 
-// CheckOut processes a check-out request
-func (uc *WorkUsecase) CheckOut(req *dto.CheckOutRequest) (*dto.CheckOutResponse, error) {
-	today := req.CheckOutTime.Format("2006-01-02")
+// CheckOut processes a check-out request for the latest open session,
+// which may have started on the previous date for an overnight shift
+func (uc *WorkUsecase) CheckOut(ctx context.Context, req *dto.CheckOutRequest) (*dto.CheckOutResponse, error) {
+	return uc.checkOut(ctx, req.CheckOutTime, domain.PunchSourceManual)
+}
 
-	// Get today's session
-	session := uc.repo.GetTodaySession(today)
-	if session == nil {
-		return nil, fmt.Errorf("no check-in found for %s", today)
+// checkOut records a check-out punch from the given source, any source but the user auto-closes the session
+func (uc *WorkUsecase) checkOut(ctx context.Context, at time.Time, source domain.PunchSource) (*dto.CheckOutResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	// Checking out ends a break in progress, a correction of an earlier check-out does not cross into overtime again
+	session, previous, err := uc.recordPunch(ctx, config, domain.PunchCheckOut, at, source, "")
+	if err != nil {
+		return nil, err
 	}
+	wasOpen := previous == domain.SessionOpen || previous == domain.SessionOnBreak
 
-	// Update checkout time
-	session.CheckOut = &req.CheckOutTime
-	if err := uc.repo.SaveSession(session); err != nil {
-		return nil, fmt.Errorf("failed to save check-out: %w", err)
+	raw, err := uc.GetWorkDay(ctx, session.Date, config)
+	if err != nil {
+		return nil, err
+	}
+	day := raw.Rounded(config.RoundingPolicy)
+	overtime := day.CalculateOvertime(config.OvertimePolicy)
+
+	if err := uc.recordDailyBalance(ctx, session.Date, config); err != nil {
+		slog.Info("[CheckOut] Failed to update time bank", "error", err)
 	}
 
-	slog.Info("[CheckOut] Checked out", "time", req.CheckOutTime, "overtime_minutes", session.CalculateOvertime())
+	slog.Info("[CheckOut] Checked out", "time", at, "source", source, "sessions", len(day.Sessions), "overtime_minutes", overtime)
+
+	rounded := session.Rounded(config.RoundingPolicy)
+	worked := day.CalculateActualWorkMinutes()
+	now := time.Now()
+	uc.events.Publish(domain.SessionCheckedOut{
+		At:              now,
+		Session:         session,
+		WorkedMinutes:   worked,
+		OvertimeMinutes: overtime,
+	})
+	if wasOpen && domain.CrossesOvertime(overtime, rounded.CalculateActualWorkMinutes()) {
+		uc.events.Publish(domain.OvertimeThresholdCrossed{At: now, Date: session.Date, OvertimeMinutes: overtime})
+	}
 
 	return &dto.CheckOutResponse{
-		SessionID:       session.ID,
-		CheckInTime:     session.CheckIn,
-		CheckOutTime:    req.CheckOutTime,
-		OvertimeMinutes: session.CalculateOvertime(),
+		SessionID:           session.ID,
+		CheckInTime:         session.CheckIn,
+		CheckOutTime:        at,
+		RoundedCheckInTime:  rounded.CheckIn,
+		RoundedCheckOutTime: *rounded.CheckOut,
+		WorkedMinutes:       worked,
+		OvertimeMinutes:     overtime,
+	}, nil
+}
+
+// OnAttendanceSynced checks out the latest session of a date with the check-out time reported by the HR system,
+// a check-out from a higher ranked source is kept and the HR time only recorded
+func (uc *WorkUsecase) OnAttendanceSynced(event domain.Event) {
+	synced, ok := event.(domain.AttendanceSynced)
+	if !ok || synced.CheckOut == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+	defer cancel()
+
+	session, err := uc.repo.GetTodaySession(ctx, synced.Date)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return
+	}
+	if err != nil {
+		slog.Info("[AttendanceSynced] Failed to get session", "date", synced.Date, "error", err)
+		return
+	}
+	if !synced.CheckOut.After(session.CheckIn) {
+		return
+	}
+	if session.HasCheckedOut() && session.CheckOut.Equal(*synced.CheckOut) {
+		return
+	}
+	// The scheduler reports the same check-out on every run
+	recorded, err := uc.hasPunch(ctx, domain.PunchCheckOut, *synced.CheckOut, domain.PunchSourceSchedulerSync)
+	if err != nil {
+		slog.Info("[AttendanceSynced] Failed to get punches", "date", synced.Date, "error", err)
+		return
+	}
+	if recorded {
+		return
+	}
+
+	_, err = uc.checkOut(ctx, *synced.CheckOut, domain.PunchSourceSchedulerSync)
+	switch {
+	case errors.Is(err, domain.ErrOutranked):
+		slog.Info("[AttendanceSynced] Kept local check-out", "date", synced.Date, "reason", err)
+	case err != nil:
+		slog.Info("[AttendanceSynced] Failed to check out", "date", synced.Date, "error", err)
+	}
+}
+
+// StartBreak begins a break in the current session
+func (uc *WorkUsecase) StartBreak(ctx context.Context, req *dto.BreakStartRequest) (*dto.BreakResponse, error) {
+	return uc.startBreak(ctx, req.BreakStartTime, domain.PunchSourceManual)
+}
+
+// startBreak records a break start punch from the given source
+func (uc *WorkUsecase) startBreak(ctx context.Context, at time.Time, source domain.PunchSource) (*dto.BreakResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	session, _, err := uc.recordPunch(ctx, config, domain.PunchBreakStart, at, source, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start break: %w", err)
+	}
+	uc.events.Publish(domain.SessionEdited{At: time.Now(), Session: session, Change: domain.SessionChangeBreakStart})
+
+	slog.Info("[StartBreak] Break started", "time", at, "source", source)
+
+	day, err := uc.GetWorkDay(ctx, session.Date, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.BreakResponse{
+		SessionID:        session.ID,
+		BreakStartTime:   at,
+		BreakMinutes:     day.CalculateBreakMinutes(at),
+		ExpectedCheckOut: day.Rounded(config.RoundingPolicy).CalculateExpectedCheckOut(at),
+	}, nil
+}
+
+// EndBreak ends the break in progress in the current session
+func (uc *WorkUsecase) EndBreak(ctx context.Context, req *dto.BreakEndRequest) (*dto.BreakResponse, error) {
+	return uc.endBreak(ctx, req.BreakEndTime, domain.PunchSourceManual)
+}
+
+// endBreak records a break end punch from the given source
+func (uc *WorkUsecase) endBreak(ctx context.Context, at time.Time, source domain.PunchSource) (*dto.BreakResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	session, _, err := uc.recordPunch(ctx, config, domain.PunchBreakEnd, at, source, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to end break: %w", err)
+	}
+	current := session.Breaks[len(session.Breaks)-1]
+	uc.events.Publish(domain.SessionEdited{At: time.Now(), Session: session, Change: domain.SessionChangeBreakEnd})
+
+	day, err := uc.GetWorkDay(ctx, session.Date, config)
+	if err != nil {
+		return nil, err
+	}
+
+	breakMinutes := day.CalculateBreakMinutes(at)
+	slog.Info("[EndBreak] Break ended", "time", at, "source", source, "break_minutes", breakMinutes)
+
+	return &dto.BreakResponse{
+		SessionID:        session.ID,
+		BreakStartTime:   current.Start,
+		BreakEndTime:     current.End,
+		BreakMinutes:     breakMinutes,
+		ExpectedCheckOut: day.Rounded(config.RoundingPolicy).CalculateExpectedCheckOut(at),
 	}, nil
 }
 
 // GetStatus retrieves the current work status
-func (uc *WorkUsecase) GetStatus() (*dto.StatusResponse, error) {
+func (uc *WorkUsecase) GetStatus(ctx context.Context) (*dto.StatusResponse, error) {
 	now := time.Now()
-	today := now.Format("2006-01-02")
-	session := uc.repo.GetTodaySession(today)
-	config, err := uc.repo.GetConfig()
+	config, err := uc.repo.GetConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
+	// An overnight session still open belongs to the workday it started on
+	today := config.WorkDate(now)
+	open, err := uc.repo.GetOpenSession(ctx, config.EarliestOpenDate(now))
+	switch {
+	case err == nil:
+		today = open.Date
+	case !errors.Is(err, domain.ErrSessionNotFound):
+		return nil, fmt.Errorf("failed to get open session: %w", err)
+	}
+	// Older sessions were never checked out, they need correcting rather than counting as in progress
+	unclosed, err := uc.repo.GetOpenSessionsBefore(ctx, config.EarliestOpenDate(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unclosed sessions: %w", err)
+	}
+
+	raw, err := uc.GetWorkDay(ctx, today, config)
+	if err != nil {
+		return nil, err
+	}
+	// Worked minutes and overtime follow the rounded punches, the raw ones are shown alongside
+	day := raw.Rounded(config.RoundingPolicy)
+
+	// Compliance warnings are informative, failing to evaluate them does not fail the status
+	issues, err := uc.EvaluateCompliance(ctx, config, now)
+	if err != nil {
+		slog.Info("[GetStatus] Failed to evaluate compliance", "error", err)
+	}
+
+	session := raw.LastSession()
 	if session == nil {
 		return &dto.StatusResponse{
 			HasCheckedIn:    false,
 			CurrentTime:     now,
-			WorkHours:       config.DefaultWorkHours,
+			WorkHours:       domain.ExpectedWorkMinutes(config.ExpectedMinutes(today), day.Absences),
 			IsCheckOutTime:  false,
 			OvertimeMinutes: 0,
+			Compliance:      toComplianceIssues(issues),
+			Unclosed:        toUnclosedSessions(unclosed),
 		}, nil
 	}
 
-	expectedCheckOut := session.CalculateExpectedCheckOut()
-	isCheckOutTime := now.After(expectedCheckOut)
+	expectedCheckOut := day.CalculateExpectedCheckOut(now)
+	isCheckOutTime := now.After(expectedCheckOut) && !session.IsOnBreak()
 
+	carryOver, err := uc.calculateCarryOver(ctx, today, config)
+	if err != nil {
+		return nil, err
+	}
+	var balancedCheckOut *time.Time
+	if config.BalancePolicy.Enabled() {
+		balanced := day.CalculateBalancedCheckOut(carryOver, now)
+		balancedCheckOut = &balanced
+	}
+
+	var breakStart *time.Time
+	if current := session.CurrentBreak(); current != nil {
+		breakStart = &current.Start
+	}
+
+	// A split shift is late only at its first check-in and leaves early only at its last check-out
+	lateMinutes := day.LateMinutes(config.CoreHours)
+	earlyDepartureMinutes := day.EarlyDepartureMinutes(config.CoreHours)
+	sessions := make([]dto.SessionSummary, 0, len(raw.Sessions))
+	for i, s := range raw.Sessions {
+		rounded := day.Sessions[i]
+		summary := dto.SessionSummary{
+			SessionID:           s.ID,
+			State:               string(s.State()),
+			CheckInTime:         s.CheckIn,
+			CheckOutTime:        s.CheckOut,
+			CheckInSource:       string(s.CheckInSource),
+			CheckOutSource:      string(s.CheckOutSource),
+			RoundedCheckInTime:  rounded.CheckIn,
+			RoundedCheckOutTime: rounded.CheckOut,
+			WorkedMinutes:       rounded.CalculateActualWorkMinutes(),
+			BreakMinutes:        s.CalculateBreakMinutes(now),
+			Note:                s.Note,
+			Tags:                s.Tags,
+		}
+		if i == 0 {
+			summary.LateMinutes = lateMinutes
+			summary.IsLate = lateMinutes > 0
+		}
+		if i == len(raw.Sessions)-1 {
+			summary.EarlyDepartureMinutes = earlyDepartureMinutes
+			summary.IsEarlyDeparture = earlyDepartureMinutes > 0
+		}
+		sessions = append(sessions, summary)
+	}
+
+	rounded := day.LastSession()
 	return &dto.StatusResponse{
-		HasCheckedIn:     true,
-		CheckInTime:      &session.CheckIn,
-		CheckOutTime:     session.CheckOut,
-		ExpectedCheckOut: &expectedCheckOut,
-		CurrentTime:      now,
-		WorkHours:        session.WorkHours,
-		IsCheckOutTime:   isCheckOutTime,
-		OvertimeMinutes:  session.CalculateOvertime(),
+		HasCheckedIn:        true,
+		CheckInTime:         &session.CheckIn,
+		CheckOutTime:        session.CheckOut,
+		RoundedCheckInTime:  &rounded.CheckIn,
+		RoundedCheckOutTime: rounded.CheckOut,
+		ExpectedCheckOut:    &expectedCheckOut,
+		CurrentTime:         now,
+		WorkHours:           day.ExpectedMinutes(),
+		IsCheckOutTime:      isCheckOutTime,
+		OvertimeMinutes:     day.CalculateOvertime(config.OvertimePolicy),
+		IsOnBreak:           session.IsOnBreak(),
+		BreakStartTime:      breakStart,
+		BreakMinutes:        day.CalculateBreakMinutes(now),
+		WorkedMinutes:       day.CalculateActualWorkMinutes(),
+		Sessions:            sessions,
+		Compliance:          toComplianceIssues(issues),
+		CarryOverMinutes:    carryOver,
+		BalancedCheckOut:    balancedCheckOut,
+		Unclosed:            toUnclosedSessions(unclosed),
 	}, nil
 }
 
+// toUnclosedSessions converts sessions left without check-out to their DTOs
+func toUnclosedSessions(sessions []*domain.WorkSession) []dto.UnclosedSession {
+	resp := make([]dto.UnclosedSession, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, dto.UnclosedSession{
+			SessionID:   session.ID,
+			Date:        session.Date,
+			CheckInTime: session.CheckIn,
+		})
+	}
+	return resp
+}
+
+// calculateCarryOver sums the surplus or deficit of the earlier days in the balance period
+func (uc *WorkUsecase) calculateCarryOver(ctx context.Context, today string, config *domain.WorkConfig) (int, error) {
+	from, to, ok := config.BalancePolicy.Period(today)
+	if !ok {
+		return 0, nil
+	}
+	sessions, err := uc.repo.GetSessionsByDateRange(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	absences, err := uc.repo.GetAbsencesByDateRange(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get absences: %w", err)
+	}
+	return config.BalancePolicy.CarryOver(domain.BuildWorkDays(sessions, absences, config)), nil
+}
+
 // GetTodayCheckIn retrieves or auto-fetches today's check-in information
-func (uc *WorkUsecase) GetTodayCheckIn(req *dto.TodayCheckInRequest) (*dto.TodayCheckInResponse, error) {
-	// Check if already checked in
-	session := uc.repo.GetTodaySession(req.Date)
+func (uc *WorkUsecase) GetTodayCheckIn(ctx context.Context, req *dto.TodayCheckInRequest) (*dto.TodayCheckInResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	// Check if already checked in, the HR API only knows the first check-in of the day
+	day, err := uc.GetWorkDay(ctx, req.Date, config)
+	if err != nil {
+		return nil, err
+	}
+	session := day.FirstSession()
 	if session != nil && !req.ReCheckIn {
 		return &dto.TodayCheckInResponse{
 			HasCheckedIn: true,
@@ -140,14 +430,9 @@ func (uc *WorkUsecase) GetTodayCheckIn(req *dto.TodayCheckInRequest) (*dto.Today
 		}, nil
 	}
 
-	config, err := uc.repo.GetConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config: %w", err)
-	}
-
 	// Try to auto-fetch from HR API if enabled
 	if config.ShouldAutoFetch() {
-		return uc.autoFetchCheckIn(req.Date, session, config)
+		return uc.autoFetchCheckIn(ctx, req.Date, config)
 	}
 
 	return &dto.TodayCheckInResponse{
@@ -159,7 +444,7 @@ func (uc *WorkUsecase) GetTodayCheckIn(req *dto.TodayCheckInRequest) (*dto.Today
 }
 
 // autoFetchCheckIn fetches check-in time from HR API and creates a session
-func (uc *WorkUsecase) autoFetchCheckIn(date string, existingSession *domain.WorkSession, config *domain.WorkConfig) (*dto.TodayCheckInResponse, error) {
+func (uc *WorkUsecase) autoFetchCheckIn(ctx context.Context, date string, config *domain.WorkConfig) (*dto.TodayCheckInResponse, error) {
 	checkInTime, _, err := uc.attendanceProvider.FetchAttendanceStatus(config, date)
 	if err != nil {
 		slog.Info("[AutoFetch] Failed to fetch check-in time", "error", err)
@@ -174,22 +459,24 @@ func (uc *WorkUsecase) autoFetchCheckIn(date string, existingSession *domain.Wor
 
 	slog.Info("[AutoFetch] Successfully fetched check-in time", "time", *checkInTime)
 
-	// Determine session ID
-	sessionID := uuid.New().String()
-	if existingSession != nil {
-		sessionID = existingSession.ID
-	}
-
-	// Create/update session with fetched time
-	session := &domain.WorkSession{
-		ID:        sessionID,
-		Date:      date,
-		CheckIn:   *checkInTime,
-		WorkHours: config.DefaultWorkHours,
+	// The fetched time corrects the first session of the day, or starts it
+	session, previous, err := uc.recordPunch(ctx, config, domain.PunchCheckIn, *checkInTime, domain.PunchSourceHRAutoFetch, "")
+	if errors.Is(err, domain.ErrOutranked) {
+		// A check-in from a higher ranked source is kept, the conflict list shows the HR time
+		slog.Info("[AutoFetch] Kept local check-in", "reason", err)
+		day, err := uc.GetWorkDay(ctx, date, config)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.TodayCheckInResponse{
+			HasCheckedIn:     true,
+			CheckInTime:      &day.FirstSession().CheckIn,
+			CanAutoFetch:     true,
+			AutoFetchEnabled: true,
+		}, nil
 	}
-
-	if err := uc.repo.SaveSession(session); err != nil {
-		slog.Info("[AutoFetch] Failed to save session", "error", err)
+	if err != nil {
+		slog.Info("[AutoFetch] Failed to record check-in", "error", err)
 		return &dto.TodayCheckInResponse{
 			HasCheckedIn:     false,
 			CheckInTime:      checkInTime,
@@ -198,6 +485,11 @@ func (uc *WorkUsecase) autoFetchCheckIn(date string, existingSession *domain.Wor
 			APIError:         fmt.Sprintf("Failed to save session: %v", err),
 		}, nil
 	}
+	if previous == "" {
+		uc.events.Publish(domain.SessionCheckedIn{At: time.Now(), Session: session})
+	} else {
+		uc.events.Publish(domain.SessionEdited{At: time.Now(), Session: session, Change: domain.SessionChangeCheckIn})
+	}
 
 	return &dto.TodayCheckInResponse{
 		HasCheckedIn:     true,
@@ -208,8 +500,8 @@ func (uc *WorkUsecase) autoFetchCheckIn(date string, existingSession *domain.Wor
 }
 
 // UpdateConfig updates the work configuration
-func (uc *WorkUsecase) UpdateConfig(req *dto.ConfigRequest) error {
-	config, err := uc.repo.GetConfig()
+func (uc *WorkUsecase) UpdateConfig(ctx context.Context, req *dto.ConfigRequest) error {
+	config, err := uc.repo.GetConfig(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get config: %w", err)
 	}
@@ -229,29 +521,152 @@ func (uc *WorkUsecase) UpdateConfig(req *dto.ConfigRequest) error {
 	config.CheckInWebhookURL = req.CheckInWebhookURL
 	config.CheckOutWebhookURL = req.CheckOutWebhookURL
 
-	// Update existing session's work hours if checked in today
-	if req.WorkHours > 0 {
-		today := time.Now().Format("2006-01-02")
-		if session := uc.repo.GetTodaySession(today); session != nil {
-			session.WorkHours = req.WorkHours
-			if err := uc.repo.SaveSession(session); err != nil {
+	if req.DayRolloverHour != nil {
+		if *req.DayRolloverHour < 0 || *req.DayRolloverHour > 23 {
+			return fmt.Errorf("day rollover hour must be between 0 and 23")
+		}
+		config.DayRolloverHour = *req.DayRolloverHour
+	}
+
+	if req.OvertimePolicy != nil {
+		policy, err := toOvertimePolicy(req.OvertimePolicy)
+		if err != nil {
+			return err
+		}
+		config.OvertimePolicy = policy
+	}
+
+	if req.WeeklySchedule != nil {
+		schedule, err := toWeeklySchedule(*req.WeeklySchedule)
+		if err != nil {
+			return err
+		}
+		config.WeeklySchedule = schedule
+	}
+
+	if req.WorkCycle != nil {
+		cycle, err := toWorkCycle(req.WorkCycle)
+		if err != nil {
+			return err
+		}
+		config.WorkCycle = cycle
+	}
+
+	if req.OvertimeHourlyRate != nil {
+		if *req.OvertimeHourlyRate < 0 {
+			return fmt.Errorf("overtime hourly rate cannot be negative")
+		}
+		config.OvertimeHourlyRate = *req.OvertimeHourlyRate
+	}
+
+	if req.ComplianceRules != nil {
+		rules, err := toComplianceRules(req.ComplianceRules)
+		if err != nil {
+			return err
+		}
+		config.ComplianceRules = rules
+	}
+
+	if req.CoreHours != nil {
+		core := domain.CoreHours{Start: req.CoreHours.Start, End: req.CoreHours.End}
+		if err := core.Validate(); err != nil {
+			return fmt.Errorf("invalid core hours: %w", err)
+		}
+		config.CoreHours = core
+	}
+
+	if req.CheckInReminderLeadMinutes != nil {
+		if *req.CheckInReminderLeadMinutes < 0 || *req.CheckInReminderLeadMinutes > domain.MinutesPerHour*3 {
+			return fmt.Errorf("check-in reminder lead must be between 0 and %d minutes", domain.MinutesPerHour*3)
+		}
+		config.CheckInReminderLeadMinutes = *req.CheckInReminderLeadMinutes
+	}
+
+	if req.CheckOutRule != nil {
+		rule := domain.CheckOutRule{
+			Mode:        domain.CheckOutMode(req.CheckOutRule.Mode),
+			EarliestEnd: req.CheckOutRule.EarliestEnd,
+			LatestStart: req.CheckOutRule.LatestStart,
+		}
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid check-out rule: %w", err)
+		}
+		config.CheckOutRule = rule
+	}
+
+	if req.SourcePrecedence != nil {
+		precedence := make(domain.SourcePrecedence, 0, len(req.SourcePrecedence))
+		for _, source := range req.SourcePrecedence {
+			precedence = append(precedence, domain.PunchSource(source))
+		}
+		if err := precedence.Validate(); err != nil {
+			return fmt.Errorf("invalid source precedence: %w", err)
+		}
+		config.SourcePrecedence = precedence
+	}
+
+	if req.RoundingPolicy != nil {
+		policy := domain.RoundingPolicy{
+			IntervalMinutes: req.RoundingPolicy.IntervalMinutes,
+			CheckIn:         domain.RoundingDirection(req.RoundingPolicy.CheckIn),
+			CheckOut:        domain.RoundingDirection(req.RoundingPolicy.CheckOut),
+			GraceMinutes:    req.RoundingPolicy.GraceMinutes,
+		}
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("invalid rounding policy: %w", err)
+		}
+		config.RoundingPolicy = policy
+	}
+
+	if req.BalancePolicy != nil {
+		policy := domain.BalancePolicy{
+			Mode:                domain.BalanceMode(req.BalancePolicy.Mode),
+			MaxCarryOverMinutes: req.BalancePolicy.MaxCarryOverMinutes,
+		}
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("invalid balance policy: %w", err)
+		}
+		config.BalancePolicy = policy
+	}
+
+	// Update existing sessions' work hours if checked in today
+	if req.WorkHours > 0 || req.WeeklySchedule != nil || req.WorkCycle != nil {
+		today := config.WorkDate(time.Now())
+		workHours := config.ExpectedMinutes(today)
+		sessions, err := uc.repo.GetSessionsByDate(ctx, today)
+		if err != nil {
+			return fmt.Errorf("failed to get today's sessions: %w", err)
+		}
+		for _, session := range sessions {
+			session.WorkHours = workHours
+			if err := uc.repo.SaveSession(ctx, session); err != nil {
 				return fmt.Errorf("failed to update session work hours: %w", err)
 			}
-			slog.Info("[UpdateConfig] Updated today's session work hours", "minutes", req.WorkHours)
+		}
+		if len(sessions) > 0 {
+			slog.Info("[UpdateConfig] Updated today's session work hours", "minutes", workHours)
 		}
 	}
 
-	if err := uc.repo.SaveConfig(config); err != nil {
+	if err := uc.repo.SaveConfig(ctx, config); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
+	// Daily time bank entries follow the expected work and the rounding of the config
+	if req.WorkHours > 0 || req.WeeklySchedule != nil || req.WorkCycle != nil || req.RoundingPolicy != nil {
+		if _, err := uc.recalculateDailyBalances(ctx, config); err != nil {
+			return fmt.Errorf("failed to recalculate time bank: %w", err)
+		}
+	}
+	uc.events.Publish(domain.ConfigChanged{At: time.Now(), Config: config})
+
 	slog.Info("[UpdateConfig] Configuration updated successfully")
 	return nil
 }
 
 // GetConfig retrieves the current work configuration
-func (uc *WorkUsecase) GetConfig() (*dto.ConfigResponse, error) {
-	config, err := uc.repo.GetConfig()
+func (uc *WorkUsecase) GetConfig(ctx context.Context) (*dto.ConfigResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
@@ -264,43 +679,230 @@ func (uc *WorkUsecase) GetConfig() (*dto.ConfigResponse, error) {
 		PRToken:            config.PRToken,
 		CheckInWebhookURL:  config.CheckInWebhookURL,
 		CheckOutWebhookURL: config.CheckOutWebhookURL,
+		OvertimePolicy:     fromOvertimePolicy(config.OvertimePolicy),
+		DayRolloverHour:    config.DayRolloverHour,
+		WeeklySchedule:     fromWeeklySchedule(config.WeeklySchedule),
+		WorkCycle:          fromWorkCycle(config.WorkCycle),
+		OvertimeHourlyRate: config.OvertimeHourlyRate,
+		ComplianceRules:    fromComplianceRules(config.ComplianceRules),
+		BalancePolicy: dto.BalancePolicy{
+			Mode:                string(config.BalancePolicy.Mode),
+			MaxCarryOverMinutes: config.BalancePolicy.MaxCarryOverMinutes,
+		},
+		RoundingPolicy: dto.RoundingPolicy{
+			IntervalMinutes: config.RoundingPolicy.IntervalMinutes,
+			CheckIn:         string(config.RoundingPolicy.CheckIn),
+			CheckOut:        string(config.RoundingPolicy.CheckOut),
+			GraceMinutes:    config.RoundingPolicy.GraceMinutes,
+		},
+		CoreHours:                  fromCoreHours(config.CoreHours),
+		CheckInReminderLeadMinutes: config.CheckInReminderLeadMinutes,
+		CheckOutRule: dto.CheckOutRule{
+			Mode:        string(config.CheckOutRule.Mode),
+			EarliestEnd: config.CheckOutRule.EarliestEnd,
+			LatestStart: config.CheckOutRule.LatestStart,
+		},
+		SourcePrecedence: fromSourcePrecedence(config.SourcePrecedence),
 	}, nil
 }
 
-// GetMonthlyStats retrieves monthly overtime statistics
-func (uc *WorkUsecase) GetMonthlyStats() (*dto.MonthlyStatsResponse, error) {
-	now := time.Now()
-	currentMonth := now.Format("2006-01")
-	lastMonth := now.AddDate(0, -1, 0).Format("2006-01")
+// fromSourcePrecedence converts the source precedence to its DTO, the default when none is configured
+func fromSourcePrecedence(precedence domain.SourcePrecedence) []string {
+	if len(precedence) == 0 {
+		precedence = domain.DefaultSourcePrecedence()
+	}
+	sources := make([]string, 0, len(precedence))
+	for _, source := range precedence {
+		sources = append(sources, string(source))
+	}
+	return sources
+}
 
-	// Get current month stats
-	currentSessions, err := uc.repo.GetSessionsByMonth(currentMonth)
+// fromCoreHours converts core hours to their DTO, nil when none are configured
+func fromCoreHours(core domain.CoreHours) *dto.CoreHours {
+	if !core.Enabled() {
+		return nil
+	}
+	return &dto.CoreHours{Start: core.Start, End: core.End}
+}
+
+// toOvertimePolicy converts and validates a requested overtime policy
+func toOvertimePolicy(req *dto.OvertimePolicy) (domain.OvertimePolicy, error) {
+	policy := domain.OvertimePolicy{
+		Mode:             domain.OvertimeMode(req.Mode),
+		ThresholdMinutes: req.ThresholdMinutes,
+		GraceMinutes:     req.GraceMinutes,
+	}
+	if len(req.WeekdayThresholds) > 0 {
+		if len(req.WeekdayThresholds) != len(policy.WeekdayThresholds) {
+			return policy, fmt.Errorf("weekday thresholds must have %d values starting on Sunday", len(policy.WeekdayThresholds))
+		}
+		copy(policy.WeekdayThresholds[:], req.WeekdayThresholds)
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid overtime policy: %w", err)
+	}
+	return policy, nil
+}
+
+// toWeeklySchedule converts and validates a requested weekly schedule, an empty list removes it
+func toWeeklySchedule(minutes []int) (*domain.WeekdayMinutes, error) {
+	if len(minutes) == 0 {
+		return nil, nil
+	}
+
+	var schedule domain.WeekdayMinutes
+	if len(minutes) != len(schedule) {
+		return nil, fmt.Errorf("weekly schedule must have %d values starting on Sunday", len(schedule))
+	}
+	for i, value := range minutes {
+		if value < 0 || value > domain.MaxWorkMinutesPerDay {
+			return nil, fmt.Errorf("scheduled minutes must be between 0 and %d", domain.MaxWorkMinutesPerDay)
+		}
+		schedule[i] = value
+	}
+	return &schedule, nil
+}
+
+// fromWeeklySchedule converts a weekly schedule to its DTO, nil when none is configured
+func fromWeeklySchedule(schedule *domain.WeekdayMinutes) []int {
+	if schedule == nil {
+		return nil
+	}
+	return schedule[:]
+}
+
+// toWorkCycle converts and validates a requested work cycle, a cycle without weeks removes it
+func toWorkCycle(req *dto.WorkCycle) (*domain.WorkCycle, error) {
+	if len(req.Weeks) == 0 {
+		return nil, nil
+	}
+
+	cycle := &domain.WorkCycle{
+		AnchorDate: req.AnchorDate,
+		Weeks:      make([][]time.Weekday, len(req.Weeks)),
+	}
+	for i, week := range req.Weeks {
+		cycle.Weeks[i] = make([]time.Weekday, len(week))
+		for j, weekday := range week {
+			cycle.Weeks[i][j] = time.Weekday(weekday)
+		}
+	}
+	if err := cycle.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid work cycle: %w", err)
+	}
+	return cycle, nil
+}
+
+// fromWorkCycle converts a work cycle to its DTO, nil when none is configured
+func fromWorkCycle(cycle *domain.WorkCycle) *dto.WorkCycle {
+	if cycle == nil {
+		return nil
+	}
+	weeks := make([][]int, len(cycle.Weeks))
+	for i, week := range cycle.Weeks {
+		weeks[i] = make([]int, len(week))
+		for j, weekday := range week {
+			weeks[i][j] = int(weekday)
+		}
+	}
+	return &dto.WorkCycle{
+		AnchorDate: cycle.AnchorDate,
+		Weeks:      weeks,
+	}
+}
+
+// fromOvertimePolicy converts an overtime policy to its DTO
+func fromOvertimePolicy(policy domain.OvertimePolicy) dto.OvertimePolicy {
+	return dto.OvertimePolicy{
+		Mode:              string(policy.Mode),
+		ThresholdMinutes:  policy.ThresholdMinutes,
+		WeekdayThresholds: policy.WeekdayThresholds[:],
+		GraceMinutes:      policy.GraceMinutes,
+	}
+}
+
+// GetMonthlyStats retrieves monthly overtime statistics
+func (uc *WorkUsecase) GetMonthlyStats(ctx context.Context) (*dto.MonthlyStatsResponse, error) {
+	config, err := uc.repo.GetConfig(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current month sessions: %w", err)
+		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
-	// Get last month stats
-	lastSessions, err := uc.repo.GetSessionsByMonth(lastMonth)
+	// Months follow the workday rule, the early hours of the 1st still belong to last month
+	currentMonth := config.WorkDate(time.Now())[:7]
+	firstOfMonth, err := time.Parse("2006-01", currentMonth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last month sessions: %w", err)
+		return nil, fmt.Errorf("failed to parse month: %w", err)
 	}
+	lastMonth := firstOfMonth.AddDate(0, -1, 0).Format("2006-01")
 
 	// Calculate statistics
-	currentStats := domain.CalculateStats(currentSessions, currentMonth)
-	lastStats := domain.CalculateStats(lastSessions, lastMonth)
+	currentStats, err := uc.calculateMonthStats(ctx, currentMonth, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current month stats: %w", err)
+	}
+	lastStats, err := uc.calculateMonthStats(ctx, lastMonth, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last month stats: %w", err)
+	}
 
 	return &dto.MonthlyStatsResponse{
-		CurrentMonth: dto.MonthStats{
-			YearMonth:       currentStats.YearMonth,
-			TotalDays:       currentStats.TotalDays,
-			CheckedOutDays:  currentStats.CheckedOutDays,
-			OvertimeMinutes: currentStats.OvertimeMinutes,
-		},
-		LastMonth: dto.MonthStats{
-			YearMonth:       lastStats.YearMonth,
-			TotalDays:       lastStats.TotalDays,
-			CheckedOutDays:  lastStats.CheckedOutDays,
-			OvertimeMinutes: lastStats.OvertimeMinutes,
-		},
+		CurrentMonth: toMonthStats(currentStats),
+		LastMonth:    toMonthStats(lastStats),
 	}, nil
 }
+
+// toMonthStats converts domain statistics to the response DTO
+func toMonthStats(stats *domain.MonthlyStats) dto.MonthStats {
+	return dto.MonthStats{
+		YearMonth:       stats.YearMonth,
+		TotalDays:       stats.TotalDays,
+		TotalSessions:   stats.TotalSessions,
+		CheckedOutDays:  stats.CheckedOutDays,
+		WorkMinutes:     stats.WorkMinutes,
+		OvertimeMinutes: stats.OvertimeMinutes,
+
+		AttendedDays:     stats.AttendedDays,
+		AbsentDays:       stats.AbsentDays,
+		ExpectedWorkdays: stats.ExpectedWorkdays,
+
+		WeekendOvertimeMinutes: stats.WeekendOvertimeMinutes,
+
+		LateArrivals:          stats.LateArrivals,
+		LateMinutes:           stats.LateMinutes,
+		EarlyDepartures:       stats.EarlyDepartures,
+		EarlyDepartureMinutes: stats.EarlyDepartureMinutes,
+	}
+}
+
+// calculateMonthStats loads a month's sessions and absences and aggregates them
+func (uc *WorkUsecase) calculateMonthStats(ctx context.Context, yearMonth string, config *domain.WorkConfig) (*domain.MonthlyStats, error) {
+	sessions, err := uc.repo.GetSessionsByMonth(ctx, yearMonth)
+	if err != nil {
+		return nil, err
+	}
+	absences, err := uc.repo.GetAbsencesByMonth(ctx, yearMonth)
+	if err != nil {
+		return nil, err
+	}
+	days := domain.BuildWorkDays(sessions, absences, config)
+	return domain.CalculateStats(days, absences, yearMonth, config.CountWorkdays(yearMonth), config.CoreHours, config.OvertimePolicy), nil
+}
+
+// GetWorkDay loads all sessions and absences of a date as a work day under the config
+func (uc *WorkUsecase) GetWorkDay(ctx context.Context, date string, config *domain.WorkConfig) (*domain.WorkDay, error) {
+	sessions, err := uc.repo.GetSessionsByDate(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions for %s: %w", date, err)
+	}
+	absences, err := uc.repo.GetAbsencesByDate(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absences for %s: %w", date, err)
+	}
+	day := domain.NewWorkDay(date, sessions)
+	day.Absences = absences
+	day.RestDay = config.IsDayOff(date)
+	day.CheckOutRule = config.CheckOutRule
+	return day, nil
+}
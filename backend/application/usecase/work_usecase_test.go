@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/application/eventbus"
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/infrastructure/persistence"
+	"github.com/simon0-o/offline_me/backend/interfaces/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestUsecase creates a usecase on an empty in-memory store with the default config
+func newTestUsecase(t *testing.T) (*WorkUsecase, domain.Repository) {
+	repo := persistence.NewMemoryStore()
+	t.Cleanup(func() { repo.Close() })
+	bus := eventbus.New()
+	t.Cleanup(bus.Close)
+	return NewWorkUsecase(repo, bus), repo
+}
+
+// at returns a local time on a date (YYYY-MM-DD)
+func at(t *testing.T, date string, hour, minute int) time.Time {
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	assert.NoError(t, err)
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+// workSession checks in and out manually on a date, between two hours
+func workSession(t *testing.T, uc *WorkUsecase, date string, checkInHour, checkOutHour int) {
+	ctx := context.Background()
+	_, err := uc.CheckIn(ctx, &dto.CheckInRequest{CheckInTime: at(t, date, checkInHour, 0)})
+	assert.NoError(t, err)
+	_, err = uc.CheckOut(ctx, &dto.CheckOutRequest{CheckOutTime: at(t, date, checkOutHour, 0)})
+	assert.NoError(t, err)
+}
+
+// dailyBalance returns the minutes of a date's daily time bank entry, false if it has none
+func dailyBalance(t *testing.T, repo domain.Repository, date string) (int, bool) {
+	entries, err := repo.GetTimeBankEntries(context.Background(), date, date)
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		if entry.ID == domain.DailyTimeBankEntryID(date) {
+			return entry.Minutes, true
+		}
+	}
+	return 0, false
+}
+
+func TestGetStatus_UnclosedSession(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	// Checked in three days ago and never checked out
+	forgotten := time.Now().AddDate(0, 0, -3)
+	_, err := uc.CheckIn(ctx, &dto.CheckInRequest{CheckInTime: forgotten})
+	assert.NoError(t, err)
+
+	status, err := uc.GetStatus(ctx)
+	assert.NoError(t, err)
+	assert.False(t, status.HasCheckedIn)
+	assert.Len(t, status.Unclosed, 1)
+	assert.Equal(t, forgotten.Format("2006-01-02"), status.Unclosed[0].Date)
+
+	// Checking out today does not close it
+	_, err = uc.CheckOut(ctx, &dto.CheckOutRequest{CheckOutTime: time.Now()})
+	assert.ErrorIs(t, err, domain.ErrNoCheckIn)
+}
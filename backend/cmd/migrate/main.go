@@ -0,0 +1,63 @@
+// Command migrate applies or rolls back the numbered schema migrations of the SQLite or PostgreSQL database.
+// The server applies every pending migration at startup, run this to inspect the schema version,
+// preview a migration with -dry-run or roll one back with -to. Dropping every table with -to 0 also takes -force.
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/simon0-o/offline_me/backend/infrastructure/persistence"
+)
+
+func main() {
+	dsn := flag.String("db", persistence.DefaultDSN(), "path of the SQLite database or a postgres:// URL")
+	target := flag.Int("to", persistence.LatestSchemaVersion(), "schema version to migrate to, 0 drops every table")
+	dryRun := flag.Bool("dry-run", false, "run the migrations in a transaction that is rolled back")
+	status := flag.Bool("status", false, "print the schema version and exit")
+	force := flag.Bool("force", false, "allow -to 0, which drops every table and its data")
+	flag.Parse()
+
+	logger := log.NewStdLogger(os.Stdout)
+	helper := log.NewHelper(logger)
+
+	db, dialect, err := persistence.Open(*dsn)
+	if err != nil {
+		helper.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrator := persistence.NewMigrator(db, dialect)
+	current, err := migrator.Version()
+	if err != nil {
+		helper.Fatalf("Failed to read schema version: %v", err)
+	}
+	if *status {
+		helper.Infof("Schema version %d, the latest is %d", current, persistence.LatestSchemaVersion())
+		return
+	}
+	if *target == 0 && !*dryRun && !*force {
+		helper.Fatalf("Migrating to version 0 drops every table and its data, pass -force to do it")
+	}
+
+	steps, err := migrator.Migrate(*target, *dryRun)
+	if err != nil {
+		helper.Fatalf("Failed to migrate: %v", err)
+	}
+
+	verb := "Applied"
+	if *dryRun {
+		verb = "Would apply"
+	}
+	for _, step := range steps {
+		helper.Infof("%s %s", verb, step)
+	}
+	if len(steps) == 0 {
+		helper.Infof("Schema is at version %d, nothing to do", current)
+		return
+	}
+	if !*dryRun {
+		helper.Infof("Schema migrated from version %d to %d", current, *target)
+	}
+}
@@ -0,0 +1,53 @@
+// Command rebuild derives every work session again from the punch log,
+// run it after a rule change such as the day rollover hour so that past days follow the new rules.
+// With -time-bank it only records the daily time bank entries again, such as for days worked before the time bank.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/simon0-o/offline_me/backend/application/eventbus"
+	"github.com/simon0-o/offline_me/backend/application/usecase"
+	"github.com/simon0-o/offline_me/backend/infrastructure/persistence"
+)
+
+func main() {
+	dsn := flag.String("db", persistence.DefaultDSN(), "path of the SQLite database or a postgres:// URL")
+	timeBank := flag.Bool("time-bank", false, "only recalculate the daily time bank entries from the sessions")
+	flag.Parse()
+
+	logger := log.NewStdLogger(os.Stdout)
+	helper := log.NewHelper(logger)
+
+	store, err := persistence.NewStore(*dsn)
+	if err != nil {
+		helper.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer store.Close()
+
+	// Rebuilding changes no live state worth notifying about, nothing subscribes to its events
+	bus := eventbus.New()
+	defer bus.Close()
+	workUsecase := usecase.NewWorkUsecase(store, bus)
+	if *timeBank {
+		days, err := workUsecase.RecalculateTimeBank(context.Background())
+		if err != nil {
+			helper.Fatalf("Failed to recalculate time bank: %v", err)
+		}
+		helper.Infof("Recorded the time bank entries of %d days", days)
+		return
+	}
+
+	resp, err := workUsecase.RebuildSessions(context.Background())
+	if err != nil {
+		helper.Fatalf("Failed to rebuild sessions: %v", err)
+	}
+
+	helper.Infof("Rebuilt %d sessions from %d punches", resp.Sessions, resp.Punches)
+	for _, punch := range resp.Skipped {
+		helper.Warnf("Skipped %s punch %s at %s from %s, it no longer applies", punch.Kind, punch.ID, punch.Time.Format("2006-01-02 15:04"), punch.Source)
+	}
+}
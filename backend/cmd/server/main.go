@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	nethttp "net/http" // Changed from nethttp "net/http"
 	"os"
 	"os/signal"
@@ -9,7 +10,11 @@ import (
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/simon0-o/offline_me/backend/application/eventbus"
+	"github.com/simon0-o/offline_me/backend/application/subscriber"
 	"github.com/simon0-o/offline_me/backend/application/usecase"
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/simon0-o/offline_me/backend/infrastructure/client"
 	"github.com/simon0-o/offline_me/backend/infrastructure/cronjob"
 	"github.com/simon0-o/offline_me/backend/infrastructure/persistence"
 	"github.com/simon0-o/offline_me/backend/interfaces/http"
@@ -17,22 +22,41 @@ import (
 )
 
 func main() {
+	dsn := flag.String("db", persistence.DefaultDSN(), "path of the SQLite database or a postgres:// URL, DATABASE_DSN sets the default")
+	ephemeral := flag.Bool("ephemeral", false, "keep all data in memory and lose it on exit, for demos and integration tests; -db is ignored")
+	flag.Parse()
+
 	logger := log.NewStdLogger(os.Stdout)
 	helper := log.NewHelper(logger)
 
-	// Initialize SQLite database
-	store, err := persistence.NewSQLiteStore("../worktime.db")
-	if err != nil {
-		helper.Fatalf("Failed to initialize database: %v", err)
+	// Initialize the database, SQLite for a single user or PostgreSQL for a shared service
+	var store domain.Repository
+	if *ephemeral {
+		helper.Warn("Running in ephemeral mode, all data is kept in memory and lost on exit")
+		store = persistence.NewMemoryStore()
+	} else {
+		var err error
+		store, err = persistence.NewStore(*dsn)
+		if err != nil {
+			helper.Fatalf("Failed to initialize database: %v", err)
+		}
 	}
 	defer store.Close()
 
 	// Initialize dependencies (Clean Architecture layers)
-	workUsecase := usecase.NewWorkUsecase(store)
+	bus := eventbus.New()
+	defer bus.Close()
+	workUsecase := usecase.NewWorkUsecase(store, bus)
 	workHandler := http.NewWorkHandler(workUsecase, logger)
 
+	// Subscribe to domain events
+	bus.SubscribeAll(subscriber.NewAuditLog().Handle)
+	bus.SubscribeAsync(domain.EventOvertimeThresholdCrossed, subscriber.NewNotifier(store, client.NewWebhookClient()).Handle)
+	bus.Subscribe(domain.EventAttendanceSynced, workUsecase.OnAttendanceSynced)
+
 	// Initialize and start cronjob scheduler
-	scheduler := cronjob.NewScheduler(store)
+	scheduler := cronjob.NewScheduler(store, bus, workUsecase)
+	bus.Subscribe(domain.EventConfigChanged, scheduler.OnConfigChanged)
 	scheduler.Start()
 	defer scheduler.Stop()
 
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrAbsenceNotFound is returned when an absence does not exist
+var ErrAbsenceNotFound = fmt.Errorf("absence %w", ErrNotFound)
+
+// AbsenceType represents the reason of an absence
+type AbsenceType string
+
+const (
+	AbsenceAnnualLeave   AbsenceType = "annual_leave"
+	AbsenceSickLeave     AbsenceType = "sick_leave"
+	AbsencePersonalLeave AbsenceType = "personal_leave"
+	AbsenceBusinessTrip  AbsenceType = "business_trip"
+)
+
+// AbsenceAmount represents how much of a day an absence covers
+type AbsenceAmount string
+
+const (
+	AbsenceFullDay AbsenceAmount = "full_day"
+	AbsenceHalfDay AbsenceAmount = "half_day"
+)
+
+// Absence represents a day or half-day away from regular work
+type Absence struct {
+	ID     string
+	Date   string // YYYY-MM-DD format
+	Type   AbsenceType
+	Amount AbsenceAmount
+	Note   string
+}
+
+// Validate checks the absence's date, type and amount
+func (a *Absence) Validate() error {
+	if _, err := time.Parse("2006-01-02", a.Date); err != nil {
+		return fmt.Errorf("invalid absence date %q, expected YYYY-MM-DD", a.Date)
+	}
+	switch a.Type {
+	case AbsenceAnnualLeave, AbsenceSickLeave, AbsencePersonalLeave, AbsenceBusinessTrip:
+	default:
+		return fmt.Errorf("unknown absence type %q", a.Type)
+	}
+	switch a.Amount {
+	case AbsenceFullDay, AbsenceHalfDay:
+	default:
+		return fmt.Errorf("unknown absence amount %q", a.Amount)
+	}
+	return nil
+}
+
+// Days returns the fraction of a day covered by the absence
+func (a *Absence) Days() float64 {
+	if a.Amount == AbsenceHalfDay {
+		return 0.5
+	}
+	return 1
+}
+
+// AbsentDays sums the days covered by the absences
+func AbsentDays(absences []*Absence) float64 {
+	total := 0.0
+	for _, absence := range absences {
+		total += absence.Days()
+	}
+	return total
+}
+
+// ExpectedWorkMinutes shortens a day's expected work minutes by the leave taken on it
+func ExpectedWorkMinutes(workMinutes int, absences []*Absence) int {
+	return int(float64(workMinutes) * attendanceFraction(absences))
+}
+
+// attendanceFraction returns the part of a day still expected to be worked, between 0 and 1
+func attendanceFraction(absences []*Absence) float64 {
+	fraction := 1 - AbsentDays(absences)
+	if fraction < 0 {
+		return 0
+	}
+	return fraction
+}
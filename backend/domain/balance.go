@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// BalanceMode selects over which period surplus and deficit carry over into today's leave time
+type BalanceMode string
+
+const (
+	BalanceModeOff   BalanceMode = "off"   // every day stands on its own
+	BalanceModeMonth BalanceMode = "month" // earlier days of the current month carry over
+	BalanceModeWeek  BalanceMode = "week"  // earlier days of the current week, Monday to Sunday, carry over
+)
+
+// BalancePolicy decides how much of earlier days' surplus or deficit moves today's leave time
+type BalancePolicy struct {
+	Mode                BalanceMode `json:"mode"`
+	MaxCarryOverMinutes int         `json:"max_carry_over_minutes"` // cap of the carry-over either way, 0 is unlimited
+}
+
+// DefaultBalancePolicy returns the policy without carry-over
+func DefaultBalancePolicy() BalancePolicy {
+	return BalancePolicy{Mode: BalanceModeOff}
+}
+
+// Validate checks that the policy can be applied
+func (p BalancePolicy) Validate() error {
+	switch p.Mode {
+	case BalanceModeOff, BalanceModeMonth, BalanceModeWeek:
+	default:
+		return fmt.Errorf("unknown balance mode %q", p.Mode)
+	}
+	if p.MaxCarryOverMinutes < 0 {
+		return fmt.Errorf("carry-over cap cannot be negative")
+	}
+	return nil
+}
+
+// Enabled returns true if earlier days carry over
+func (p BalancePolicy) Enabled() bool {
+	return p.Mode == BalanceModeMonth || p.Mode == BalanceModeWeek
+}
+
+// Period returns the earlier dates (YYYY-MM-DD, inclusive) of today's month or week that carry over,
+// ok is false when nothing carries over
+func (p BalancePolicy) Period(today string) (from, to string, ok bool) {
+	day, err := time.Parse("2006-01-02", today)
+	if err != nil || !p.Enabled() {
+		return "", "", false
+	}
+	start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if p.Mode == BalanceModeWeek {
+		start = startOfWeek(day)
+	}
+	if !start.Before(day) {
+		return "", "", false
+	}
+	return start.Format("2006-01-02"), day.AddDate(0, 0, -1).Format("2006-01-02"), true
+}
+
+// CarryOver sums the balance of earlier days, their surplus (positive) or deficit (negative)
+// against their expected work, capped at MaxCarryOverMinutes either way
+func (p BalancePolicy) CarryOver(days []*WorkDay) int {
+	total := 0
+	for _, day := range days {
+		total += day.CalculateBalance()
+	}
+	if p.MaxCarryOverMinutes > 0 {
+		total = min(max(total, -p.MaxCarryOverMinutes), p.MaxCarryOverMinutes)
+	}
+	return total
+}
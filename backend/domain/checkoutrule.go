@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// CheckOutMode selects how the expected check-out of a day is decided
+type CheckOutMode string
+
+const (
+	CheckOutModeDuration CheckOutMode = "duration"  // check-in plus the expected work minutes
+	CheckOutModeFixedEnd CheckOutMode = "fixed_end" // a fixed time of day, whatever the check-in
+	CheckOutModeMax      CheckOutMode = "max"       // the later of the duration and the fixed earliest end
+)
+
+// CheckOutRule decides the expected check-out of a regular workday, such as never before 18:00
+// Days off and days with leave always use the duration
+type CheckOutRule struct {
+	Mode        CheckOutMode `json:"mode"`
+	EarliestEnd string       `json:"earliest_end"` // HH:MM, used by fixed_end and max
+	LatestStart string       `json:"latest_start"` // HH:MM, a fixed_end day checked in after it also has to reach the duration
+}
+
+// DefaultCheckOutRule returns the rule that only counts the expected work minutes
+func DefaultCheckOutRule() CheckOutRule {
+	return CheckOutRule{Mode: CheckOutModeDuration}
+}
+
+// Validate checks that the rule can be applied
+func (r CheckOutRule) Validate() error {
+	switch r.Mode {
+	case CheckOutModeDuration:
+	case CheckOutModeFixedEnd, CheckOutModeMax:
+		if _, err := time.Parse("15:04", r.EarliestEnd); err != nil {
+			return fmt.Errorf("invalid earliest end %q, expected HH:MM", r.EarliestEnd)
+		}
+	default:
+		return fmt.Errorf("unknown check-out mode %q", r.Mode)
+	}
+	if r.LatestStart != "" {
+		if _, err := time.Parse("15:04", r.LatestStart); err != nil {
+			return fmt.Errorf("invalid latest start %q, expected HH:MM", r.LatestStart)
+		}
+	}
+	return nil
+}
+
+// Apply returns the expected check-out of a date (YYYY-MM-DD) given the first check-in of the day
+// and the check-out that reaches the expected work minutes
+func (r CheckOutRule) Apply(date string, checkIn, byDuration time.Time) time.Time {
+	if r.Mode != CheckOutModeFixedEnd && r.Mode != CheckOutModeMax {
+		return byDuration
+	}
+	end, ok := timeOn(date, r.EarliestEnd, checkIn.Location())
+	if !ok {
+		return byDuration
+	}
+
+	if r.Mode == CheckOutModeFixedEnd {
+		latestStart, ok := timeOn(date, r.LatestStart, checkIn.Location())
+		if !ok || !checkIn.After(latestStart) {
+			return end
+		}
+	}
+	if byDuration.After(end) {
+		return byDuration
+	}
+	return end
+}
+
+// timeOn returns the time of day (HH:MM) on a date (YYYY-MM-DD) in the given location
+func timeOn(date, clock string, loc *time.Location) (time.Time, bool) {
+	if clock == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default limits of the compliance rules
+const (
+	DefaultMaxMonthlyOvertimeMinutes = 36 * MinutesPerHour // statutory cap of monthly overtime
+	DefaultMaxWeeklyWorkMinutes      = 60 * MinutesPerHour
+	DefaultMinRestMinutes            = 11 * MinutesPerHour // rest between two workdays
+	DefaultComplianceWarningPercent  = 90
+)
+
+// ComplianceRule identifies a labor-law limit
+type ComplianceRule string
+
+const (
+	ComplianceMonthlyOvertime ComplianceRule = "monthly_overtime" // overtime of the month
+	ComplianceWeeklyHours     ComplianceRule = "weekly_hours"     // work of the week, Monday to Sunday
+	ComplianceDailyRest       ComplianceRule = "daily_rest"       // rest between check-out and the next workday's check-in
+)
+
+// ComplianceSeverity tells whether a limit is close or already exceeded
+type ComplianceSeverity string
+
+const (
+	ComplianceWarning   ComplianceSeverity = "warning"   // the limit is about to be reached
+	ComplianceViolation ComplianceSeverity = "violation" // the limit is exceeded
+)
+
+// ComplianceRules configures the labor-law limits, a limit of 0 disables its rule
+type ComplianceRules struct {
+	MaxMonthlyOvertimeMinutes int `json:"max_monthly_overtime_minutes"`
+	MaxWeeklyWorkMinutes      int `json:"max_weekly_work_minutes"`
+	MinRestMinutes            int `json:"min_rest_minutes"`
+	WarningPercent            int `json:"warning_percent"` // warn once this share of a limit is used
+}
+
+// DefaultComplianceRules returns the 36-hour monthly overtime cap, a 60-hour week and 11 hours of rest
+func DefaultComplianceRules() ComplianceRules {
+	return ComplianceRules{
+		MaxMonthlyOvertimeMinutes: DefaultMaxMonthlyOvertimeMinutes,
+		MaxWeeklyWorkMinutes:      DefaultMaxWeeklyWorkMinutes,
+		MinRestMinutes:            DefaultMinRestMinutes,
+		WarningPercent:            DefaultComplianceWarningPercent,
+	}
+}
+
+// Validate checks that the rules can be applied
+func (r ComplianceRules) Validate() error {
+	if r.MaxMonthlyOvertimeMinutes < 0 || r.MaxWeeklyWorkMinutes < 0 || r.MinRestMinutes < 0 {
+		return fmt.Errorf("compliance limits cannot be negative")
+	}
+	if r.WarningPercent < 0 || r.WarningPercent > 100 {
+		return fmt.Errorf("compliance warning percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// ComplianceIssue represents a limit that is close to or beyond being breached
+type ComplianceIssue struct {
+	Rule          ComplianceRule
+	Severity      ComplianceSeverity
+	Date          string // YYYY-MM-DD, the first day of the period or the day the rest ended
+	ActualMinutes int
+	LimitMinutes  int
+	Message       string
+}
+
+// CompliancePeriod returns the dates (YYYY-MM-DD) whose sessions EvaluateCompliance needs at a given time:
+// from the day before the earlier of the month start and the week start, up to the current workday
+func CompliancePeriod(config *WorkConfig, now time.Time) (from, to string) {
+	today, _ := time.Parse("2006-01-02", config.WorkDate(now))
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if week := startOfWeek(today); week.Before(start) {
+		start = week
+	}
+	return start.AddDate(0, 0, -1).Format("2006-01-02"), today.Format("2006-01-02")
+}
+
+// EvaluateCompliance checks the work days of CompliancePeriod against the rules at a given time
+// Sessions still in progress count up to now, so limits are warned about before they are breached
+func EvaluateCompliance(days []*WorkDay, config *WorkConfig, now time.Time) []ComplianceIssue {
+	rules := config.ComplianceRules
+	today, _ := time.Parse("2006-01-02", config.WorkDate(now))
+	month := today.Format("2006-01")
+	week := startOfWeek(today).Format("2006-01-02")
+
+	monthlyOvertime := 0
+	weeklyWork := 0
+	for _, day := range days {
+		if day.Date[:7] == month && day.HasCheckedOut() {
+			if overtime := day.CalculateOvertime(config.OvertimePolicy); overtime > 0 {
+				monthlyOvertime += overtime
+			}
+		}
+		if day.Date >= week {
+			weeklyWork += workedMinutesUntil(day, now)
+		}
+	}
+
+	var issues []ComplianceIssue
+	if issue := checkLimit(ComplianceMonthlyOvertime, month+"-01", monthlyOvertime, rules.MaxMonthlyOvertimeMinutes, rules.WarningPercent); issue != nil {
+		issue.Message = fmt.Sprintf("Overtime this month is %s of the %s limit", formatMinutes(monthlyOvertime), formatMinutes(rules.MaxMonthlyOvertimeMinutes))
+		issues = append(issues, *issue)
+	}
+	if issue := checkLimit(ComplianceWeeklyHours, week, weeklyWork, rules.MaxWeeklyWorkMinutes, rules.WarningPercent); issue != nil {
+		issue.Message = fmt.Sprintf("Work this week is %s of the %s limit", formatMinutes(weeklyWork), formatMinutes(rules.MaxWeeklyWorkMinutes))
+		issues = append(issues, *issue)
+	}
+	return append(issues, checkRest(days, rules.MinRestMinutes)...)
+}
+
+// checkLimit compares a usage against its limit, returning nil while it is below the warning share
+func checkLimit(rule ComplianceRule, date string, actual, limit, warningPercent int) *ComplianceIssue {
+	if limit <= 0 {
+		return nil
+	}
+	severity := ComplianceViolation
+	switch {
+	case actual > limit:
+	case actual*100 >= limit*warningPercent:
+		severity = ComplianceWarning
+	default:
+		return nil
+	}
+	return &ComplianceIssue{
+		Rule:          rule,
+		Severity:      severity,
+		Date:          date,
+		ActualMinutes: actual,
+		LimitMinutes:  limit,
+	}
+}
+
+// checkRest reports workdays that started too soon after the previous one ended
+// A rest is only judged once the next check-in ended it, the rest after the latest check-out is still running
+func checkRest(days []*WorkDay, minRest int) []ComplianceIssue {
+	if minRest <= 0 {
+		return nil
+	}
+
+	var issues []ComplianceIssue
+	for i := 1; i < len(days); i++ {
+		previous := days[i-1].LastSession()
+		next := days[i].FirstSession()
+		if previous == nil || next == nil || !previous.HasCheckedOut() {
+			continue
+		}
+		rest := int(next.CheckIn.Sub(*previous.CheckOut).Minutes())
+		if rest < minRest {
+			issues = append(issues, ComplianceIssue{
+				Rule:          ComplianceDailyRest,
+				Severity:      ComplianceViolation,
+				Date:          days[i].Date,
+				ActualMinutes: rest,
+				LimitMinutes:  minRest,
+				Message:       fmt.Sprintf("Only %s of rest before checking in on %s", formatMinutes(rest), days[i].Date),
+			})
+		}
+	}
+	return issues
+}
+
+// workedMinutesUntil sums a day's work, measuring a session still in progress up to now
+func workedMinutesUntil(day *WorkDay, now time.Time) int {
+	total := 0
+	for _, session := range day.Sessions {
+		if session.HasCheckedOut() {
+			total += session.CalculateActualWorkMinutes()
+			continue
+		}
+		if now.After(session.CheckIn) {
+			total += int((now.Sub(session.CheckIn) - session.breakDuration(now)).Minutes())
+		}
+	}
+	return total
+}
+
+// formatMinutes formats minutes as hours and minutes, such as 36h30m
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%dh%02dm", minutes/MinutesPerHour, minutes%MinutesPerHour)
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// CoreHours is the part of a flexi-time day everyone must be present, such as 10:00 to 16:00
+type CoreHours struct {
+	Start string `json:"start"` // HH:MM, empty disables core hours
+	End   string `json:"end"`   // HH:MM
+}
+
+// Enabled returns true if core hours are configured
+func (c CoreHours) Enabled() bool {
+	return c.Start != "" && c.End != ""
+}
+
+// Validate checks that the core hours are either unset or a valid range within a day
+func (c CoreHours) Validate() error {
+	if c.Start == "" && c.End == "" {
+		return nil
+	}
+	start, err := time.Parse("15:04", c.Start)
+	if err != nil {
+		return fmt.Errorf("invalid core start %q, expected HH:MM", c.Start)
+	}
+	end, err := time.Parse("15:04", c.End)
+	if err != nil {
+		return fmt.Errorf("invalid core end %q, expected HH:MM", c.End)
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("core start must be before core end")
+	}
+	return nil
+}
+
+// On returns the start and end of the core hours on a date (YYYY-MM-DD) in the given location
+func (c CoreHours) On(date string, loc *time.Location) (start, end time.Time, ok bool) {
+	if !c.Enabled() {
+		return time.Time{}, time.Time{}, false
+	}
+	start, startOK := timeOn(date, c.Start, loc)
+	end, endOK := timeOn(date, c.End, loc)
+	if !startOK || !endOK {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// LateMinutes returns how many minutes after the core start the session checked in, 0 if on time
+func (s *WorkSession) LateMinutes(core CoreHours) int {
+	start, _, ok := core.On(s.Date, s.CheckIn.Location())
+	if !ok || !s.CheckIn.After(start) {
+		return 0
+	}
+	return int(s.CheckIn.Sub(start).Minutes())
+}
+
+// EarlyDepartureMinutes returns how many minutes before the core end the session checked out,
+// 0 if it stayed or is still open
+func (s *WorkSession) EarlyDepartureMinutes(core CoreHours) int {
+	if !s.HasCheckedOut() {
+		return 0
+	}
+	_, end, ok := core.On(s.Date, s.CheckOut.Location())
+	if !ok || !s.CheckOut.Before(end) {
+		return 0
+	}
+	return int(end.Sub(*s.CheckOut).Minutes())
+}
+
+// KeepsCoreHours returns true if lateness counts on the day, days off and days with leave have no core hours
+func (d *WorkDay) KeepsCoreHours() bool {
+	return !d.RestDay && len(d.Absences) == 0
+}
+
+// LateMinutes returns how many minutes after the core start the day's first session checked in,
+// a later session of a split shift is not late. 0 if on time or the day keeps no core hours
+func (d *WorkDay) LateMinutes(core CoreHours) int {
+	first := d.FirstSession()
+	if first == nil || !d.KeepsCoreHours() {
+		return 0
+	}
+	return first.LateMinutes(core)
+}
+
+// EarlyDepartureMinutes returns how many minutes before the core end the day's last session checked out,
+// an earlier session of a split shift does not leave early. 0 if it stayed or the day keeps no core hours
+func (d *WorkDay) EarlyDepartureMinutes(core CoreHours) int {
+	last := d.LastSession()
+	if last == nil || !d.KeepsCoreHours() {
+		return 0
+	}
+	return last.EarlyDepartureMinutes(core)
+}
+
+// CheckInReminderTime returns the time of day (HH:MM) of the check-in reminder relative to the core start,
+// ok is false when the fixed reminder time is used
+func (c *WorkConfig) CheckInReminderTime() (string, bool) {
+	if !c.CoreHours.Enabled() || c.CheckInReminderLeadMinutes <= 0 {
+		return "", false
+	}
+	start, err := time.Parse("15:04", c.CoreHours.Start)
+	if err != nil {
+		return "", false
+	}
+	return start.Add(-time.Duration(c.CheckInReminderLeadMinutes) * time.Minute).Format("15:04"), true
+}
@@ -0,0 +1,105 @@
+package domain
+
+import "time"
+
+// EventType names a kind of domain event, subscribers register for it
+type EventType string
+
+const (
+	EventSessionCheckedIn         EventType = "session.checked_in"
+	EventSessionCheckedOut        EventType = "session.checked_out"
+	EventSessionEdited            EventType = "session.edited"
+	EventConfigChanged            EventType = "config.changed"
+	EventOvertimeThresholdCrossed EventType = "overtime.threshold_crossed"
+	EventAttendanceSynced         EventType = "attendance.synced"
+)
+
+// Event is something that happened to the work record, published after it is saved
+type Event interface {
+	Type() EventType
+	OccurredAt() time.Time
+}
+
+// EventPublisher delivers events to their subscribers
+// This interface is defined in the domain layer, and implemented in the application layer
+type EventPublisher interface {
+	Publish(event Event)
+}
+
+// SessionCheckedIn is published when a new session starts
+type SessionCheckedIn struct {
+	At      time.Time
+	Session *WorkSession
+}
+
+func (e SessionCheckedIn) Type() EventType       { return EventSessionCheckedIn }
+func (e SessionCheckedIn) OccurredAt() time.Time { return e.At }
+
+// SessionCheckedOut is published when a session is checked out, with the totals of its whole day
+type SessionCheckedOut struct {
+	At              time.Time
+	Session         *WorkSession
+	WorkedMinutes   int // worked in the whole day
+	OvertimeMinutes int // overtime of the whole day
+}
+
+func (e SessionCheckedOut) Type() EventType       { return EventSessionCheckedOut }
+func (e SessionCheckedOut) OccurredAt() time.Time { return e.At }
+
+// SessionEditedChange names what was changed on a session
+type SessionEditedChange string
+
+const (
+	SessionChangeCheckIn     SessionEditedChange = "check_in"
+	SessionChangeBreakStart  SessionEditedChange = "break_start"
+	SessionChangeBreakEnd    SessionEditedChange = "break_end"
+	SessionChangeNote        SessionEditedChange = "note"
+	SessionChangeAllocations SessionEditedChange = "allocations"
+	SessionChangeVoid        SessionEditedChange = "void"
+)
+
+// SessionEdited is published when an existing session changes without being checked in or out
+type SessionEdited struct {
+	At      time.Time
+	Session *WorkSession
+	Change  SessionEditedChange
+}
+
+func (e SessionEdited) Type() EventType       { return EventSessionEdited }
+func (e SessionEdited) OccurredAt() time.Time { return e.At }
+
+// ConfigChanged is published when the work configuration is saved
+type ConfigChanged struct {
+	At     time.Time
+	Config *WorkConfig
+}
+
+func (e ConfigChanged) Type() EventType       { return EventConfigChanged }
+func (e ConfigChanged) OccurredAt() time.Time { return e.At }
+
+// OvertimeThresholdCrossed is published when a check-out takes a day from no overtime into overtime
+type OvertimeThresholdCrossed struct {
+	At              time.Time
+	Date            string // YYYY-MM-DD format
+	OvertimeMinutes int
+}
+
+func (e OvertimeThresholdCrossed) Type() EventType       { return EventOvertimeThresholdCrossed }
+func (e OvertimeThresholdCrossed) OccurredAt() time.Time { return e.At }
+
+// AttendanceSynced is published when the HR system reports punches for a date
+type AttendanceSynced struct {
+	At       time.Time
+	Date     string     // YYYY-MM-DD format
+	CheckIn  *time.Time // nil when the HR system has no check-in yet
+	CheckOut *time.Time // nil when the HR system has no check-out yet
+}
+
+func (e AttendanceSynced) Type() EventType       { return EventAttendanceSynced }
+func (e AttendanceSynced) OccurredAt() time.Time { return e.At }
+
+// CrossesOvertime returns true if a check-out that added sessionMinutes of work
+// took its day from no overtime to the given overtime
+func CrossesOvertime(overtimeMinutes, sessionMinutes int) bool {
+	return overtimeMinutes > 0 && overtimeMinutes-sessionMinutes <= 0
+}
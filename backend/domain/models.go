@@ -1,7 +1,12 @@
 // Package domain contains the core business entities and repository interfaces.
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
 
 // Constants for work time calculations
 const (
@@ -9,18 +14,140 @@ const (
 	StandardWorkHours        = 8
 	StandardWorkMinutes      = StandardWorkHours * MinutesPerHour // 480 minutes
 	OvertimeThresholdHours   = 10
-	OvertimeThresholdMinutes = OvertimeThresholdHours * MinutesPerHour // 600 minutes
+	OvertimeThresholdMinutes = OvertimeThresholdHours * MinutesPerHour // 600 minutes, default fixed threshold
 	MaxWorkHoursPerDay       = 24
 	MaxWorkMinutesPerDay     = MaxWorkHoursPerDay * MinutesPerHour // 1440 minutes
 )
 
+// Errors returned by break operations
+var (
+	ErrSessionClosed  = errors.New("session already checked out")
+	ErrAlreadyOnBreak = errors.New("already on a break")
+	ErrNotOnBreak     = errors.New("not on a break")
+	ErrBreakTooEarly  = errors.New("break cannot start before check-in")
+)
+
+// OvertimeMode selects how the overtime threshold of a day is determined
+type OvertimeMode string
+
+const (
+	OvertimeModeFixed    OvertimeMode = "fixed"    // a fixed number of minutes for every day
+	OvertimeModeRelative OvertimeMode = "relative" // the day's expected work minutes
+	OvertimeModeWeekday  OvertimeMode = "weekday"  // a number of minutes per weekday
+)
+
+// WeekdayMinutes holds a number of minutes for each weekday, indexed by time.Weekday (Sunday first)
+type WeekdayMinutes [7]int
+
+// OvertimePolicy decides how much of a day's work counts as overtime
+type OvertimePolicy struct {
+	Mode              OvertimeMode   `json:"mode"`
+	ThresholdMinutes  int            `json:"threshold_minutes"`  // used by fixed mode
+	WeekdayThresholds WeekdayMinutes `json:"weekday_thresholds"` // used by weekday mode
+	GraceMinutes      int            `json:"grace_minutes"`      // overtime up to this many minutes is not counted
+}
+
+// DefaultOvertimePolicy returns the policy of a fixed 10-hour threshold without grace period
+func DefaultOvertimePolicy() OvertimePolicy {
+	return OvertimePolicy{
+		Mode:             OvertimeModeFixed,
+		ThresholdMinutes: OvertimeThresholdMinutes,
+	}
+}
+
+// Validate checks that the policy can be applied
+func (p OvertimePolicy) Validate() error {
+	switch p.Mode {
+	case OvertimeModeFixed:
+		if p.ThresholdMinutes < 0 || p.ThresholdMinutes > MaxWorkMinutesPerDay {
+			return fmt.Errorf("overtime threshold must be between 0 and %d minutes", MaxWorkMinutesPerDay)
+		}
+	case OvertimeModeRelative:
+	case OvertimeModeWeekday:
+		for _, minutes := range p.WeekdayThresholds {
+			if minutes < 0 || minutes > MaxWorkMinutesPerDay {
+				return fmt.Errorf("weekday overtime threshold must be between 0 and %d minutes", MaxWorkMinutesPerDay)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown overtime mode %q", p.Mode)
+	}
+	if p.GraceMinutes < 0 {
+		return fmt.Errorf("overtime grace period cannot be negative")
+	}
+	return nil
+}
+
+// Threshold returns the minutes of work after which a day counts as overtime
+func (p OvertimePolicy) Threshold(date string, workMinutes int) int {
+	switch p.Mode {
+	case OvertimeModeRelative:
+		return workMinutes
+	case OvertimeModeWeekday:
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return p.ThresholdMinutes
+		}
+		return p.WeekdayThresholds[day.Weekday()]
+	default:
+		return p.ThresholdMinutes
+	}
+}
+
+// Overtime calculates overtime of a day from its actual work minutes
+// Returns positive for overtime, negative for under-time, overtime within the grace period counts as zero
+func (p OvertimePolicy) Overtime(actualMinutes int, date string, workMinutes int) int {
+	return p.OvertimeAbove(actualMinutes, p.Threshold(date, workMinutes))
+}
+
+// OvertimeAbove calculates overtime above an already determined threshold, applying the grace period
+func (p OvertimePolicy) OvertimeAbove(actualMinutes int, threshold int) int {
+	overtime := actualMinutes - threshold
+	if overtime > 0 && overtime <= p.GraceMinutes {
+		return 0
+	}
+	return overtime
+}
+
+// Break represents a pause (lunch, personal break) inside a work session
+type Break struct {
+	ID    string
+	Start time.Time
+	End   *time.Time
+}
+
+// IsOpen returns true if the break has not ended yet
+func (b *Break) IsOpen() bool {
+	return b.End == nil
+}
+
+// Duration returns the length of the break, measuring an open break up to the given time
+func (b *Break) Duration(until time.Time) time.Duration {
+	end := until
+	if b.End != nil {
+		end = *b.End
+	}
+	if end.Before(b.Start) {
+		return 0
+	}
+	return end.Sub(b.Start)
+}
+
 // WorkSession represents a single work session for a specific date
 type WorkSession struct {
-	ID        string
-	Date      string // YYYY-MM-DD format
-	CheckIn   time.Time
-	CheckOut  *time.Time
-	WorkHours int // Expected work hours in minutes
+	ID         string
+	Date       string // YYYY-MM-DD format
+	CheckIn    time.Time
+	CheckOut   *time.Time
+	WorkHours  int // Expected work hours in minutes
+	Breaks     []Break
+	Note       string   // markdown context such as why overtime was needed
+	Tags       []string // lowercase labels such as "on-call" or "release-night"
+	AutoClosed bool     // checked out from the HR system's punches rather than by the user
+	Voided     bool     // recorded by mistake, left out of every calculation
+
+	CheckInSource  PunchSource // where the check-in time came from
+	CheckOutSource PunchSource // where the check-out time came from, empty until checked out
 }
 
 // HasCheckedOut returns true if the session has a check-out time
@@ -28,27 +155,278 @@ func (s *WorkSession) HasCheckedOut() bool {
 	return s.CheckOut != nil
 }
 
+// CurrentBreak returns the break in progress, or nil if not on a break
+func (s *WorkSession) CurrentBreak() *Break {
+	if len(s.Breaks) == 0 {
+		return nil
+	}
+	last := &s.Breaks[len(s.Breaks)-1]
+	if !last.IsOpen() {
+		return nil
+	}
+	return last
+}
+
+// IsOnBreak returns true if a break is currently in progress
+func (s *WorkSession) IsOnBreak() bool {
+	return s.CurrentBreak() != nil
+}
+
+// StartBreak opens a new break at the given time
+func (s *WorkSession) StartBreak(id string, at time.Time) error {
+	if s.Voided {
+		return s.transition(SessionOnBreak)
+	}
+	if s.HasCheckedOut() {
+		return ErrSessionClosed
+	}
+	if s.IsOnBreak() {
+		return ErrAlreadyOnBreak
+	}
+	if at.Before(s.CheckIn) {
+		return ErrBreakTooEarly
+	}
+	s.Breaks = append(s.Breaks, Break{ID: id, Start: at})
+	return nil
+}
+
+// EndBreak closes the break in progress at the given time
+func (s *WorkSession) EndBreak(at time.Time) error {
+	if err := s.transition(SessionOpen); err != nil {
+		return err
+	}
+	current := s.CurrentBreak()
+	if current == nil {
+		return ErrNotOnBreak
+	}
+	if at.Before(current.Start) {
+		at = current.Start
+	}
+	current.End = &at
+	return nil
+}
+
+// CalculateBreakMinutes calculates the total break time, measuring an open break up to the given time
+func (s *WorkSession) CalculateBreakMinutes(until time.Time) int {
+	return int(s.breakDuration(until).Minutes())
+}
+
+// breakDuration sums all breaks, an open break ends at check-out or the given time
+func (s *WorkSession) breakDuration(until time.Time) time.Duration {
+	if s.HasCheckedOut() {
+		until = *s.CheckOut
+	}
+	var total time.Duration
+	for i := range s.Breaks {
+		total += s.Breaks[i].Duration(until)
+	}
+	return total
+}
+
 // CalculateExpectedCheckOut calculates when the user should check out
-func (s *WorkSession) CalculateExpectedCheckOut() time.Time {
-	return s.CheckIn.Add(time.Duration(s.WorkHours) * time.Minute)
+// Break time pushes the expected check-out later by the same amount, a break in progress counts up to now
+func (s *WorkSession) CalculateExpectedCheckOut(now time.Time) time.Time {
+	return s.CheckIn.
+		Add(time.Duration(s.WorkHours) * time.Minute).
+		Add(s.breakDuration(now))
 }
 
-// CalculateActualWorkMinutes calculates how many minutes were actually worked
+// CalculateActualWorkMinutes calculates how many minutes were actually worked, excluding breaks
 func (s *WorkSession) CalculateActualWorkMinutes() int {
 	if !s.HasCheckedOut() {
 		return 0
 	}
-	return int(s.CheckOut.Sub(s.CheckIn).Minutes())
+	worked := s.CheckOut.Sub(s.CheckIn) - s.breakDuration(*s.CheckOut)
+	return int(worked.Minutes())
 }
 
-// CalculateOvertime calculates overtime relative to 10-hour threshold
-// Returns positive for overtime (worked > 10h), negative for under-time
-func (s *WorkSession) CalculateOvertime() int {
+// CalculateOvertime calculates overtime relative to the policy's threshold
+// Returns positive for overtime, negative for under-time
+func (s *WorkSession) CalculateOvertime(policy OvertimePolicy) int {
 	if !s.HasCheckedOut() {
 		return 0
 	}
-	actualMinutes := s.CalculateActualWorkMinutes()
-	return actualMinutes - OvertimeThresholdMinutes
+	return policy.Overtime(s.CalculateActualWorkMinutes(), s.Date, s.WorkHours)
+}
+
+// WorkDay groups the work sessions of a single calendar day, a split shift has several
+type WorkDay struct {
+	Date     string         // YYYY-MM-DD format
+	Sessions []*WorkSession // ordered by check-in time
+	Absences []*Absence     // leave taken on the same date, shortens the expected work
+	RestDay  bool           // a day off in the schedule, all work counts as overtime
+
+	CheckOutRule CheckOutRule // decides the expected check-out of a regular workday
+}
+
+// NewWorkDay creates a work day from the sessions of a single date
+func NewWorkDay(date string, sessions []*WorkSession) *WorkDay {
+	ordered := make([]*WorkSession, len(sessions))
+	copy(ordered, sessions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CheckIn.Before(ordered[j].CheckIn)
+	})
+	return &WorkDay{Date: date, Sessions: ordered}
+}
+
+// GroupByDay groups sessions into work days, ordered by date
+func GroupByDay(sessions []*WorkSession) []*WorkDay {
+	byDate := make(map[string][]*WorkSession)
+	var dates []string
+	for _, session := range sessions {
+		if _, ok := byDate[session.Date]; !ok {
+			dates = append(dates, session.Date)
+		}
+		byDate[session.Date] = append(byDate[session.Date], session)
+	}
+	sort.Strings(dates)
+
+	days := make([]*WorkDay, 0, len(dates))
+	for _, date := range dates {
+		days = append(days, NewWorkDay(date, byDate[date]))
+	}
+	return days
+}
+
+// BuildWorkDays groups sessions into work days ordered by date, attaching the absences of each date,
+// marking the days off of the config's schedule and rounding the punches by its rounding policy
+func BuildWorkDays(sessions []*WorkSession, absences []*Absence, config *WorkConfig) []*WorkDay {
+	absencesByDate := make(map[string][]*Absence)
+	for _, absence := range absences {
+		absencesByDate[absence.Date] = append(absencesByDate[absence.Date], absence)
+	}
+
+	days := GroupByDay(sessions)
+	for i, day := range days {
+		day.Absences = absencesByDate[day.Date]
+		day.RestDay = config.IsDayOff(day.Date)
+		day.CheckOutRule = config.CheckOutRule
+		days[i] = day.Rounded(config.RoundingPolicy)
+	}
+	return days
+}
+
+// FirstSession returns the earliest session of the day, or nil if there is none
+func (d *WorkDay) FirstSession() *WorkSession {
+	if len(d.Sessions) == 0 {
+		return nil
+	}
+	return d.Sessions[0]
+}
+
+// LastSession returns the latest session of the day, or nil if there is none
+func (d *WorkDay) LastSession() *WorkSession {
+	if len(d.Sessions) == 0 {
+		return nil
+	}
+	return d.Sessions[len(d.Sessions)-1]
+}
+
+// HasCheckedOut returns true if every session of the day has a check-out time
+func (d *WorkDay) HasCheckedOut() bool {
+	if len(d.Sessions) == 0 {
+		return false
+	}
+	for _, session := range d.Sessions {
+		if !session.HasCheckedOut() {
+			return false
+		}
+	}
+	return true
+}
+
+// WorkHours returns the expected work minutes of the day, taken from its first session
+func (d *WorkDay) WorkHours() int {
+	if first := d.FirstSession(); first != nil {
+		return first.WorkHours
+	}
+	return 0
+}
+
+// ExpectedMinutes returns the work minutes expected for the day after leave is taken into account
+func (d *WorkDay) ExpectedMinutes() int {
+	return ExpectedWorkMinutes(d.WorkHours(), d.Absences)
+}
+
+// CalculateActualWorkMinutes sums the minutes worked in all checked-out sessions
+func (d *WorkDay) CalculateActualWorkMinutes() int {
+	total := 0
+	for _, session := range d.Sessions {
+		total += session.CalculateActualWorkMinutes()
+	}
+	return total
+}
+
+// CalculateBreakMinutes sums the break time of all sessions
+func (d *WorkDay) CalculateBreakMinutes(until time.Time) int {
+	total := 0
+	for _, session := range d.Sessions {
+		total += session.CalculateBreakMinutes(until)
+	}
+	return total
+}
+
+// CalculateExpectedCheckOut calculates when the latest session should check out
+// so that the whole day reaches its expected work minutes, moved by the check-out rule
+func (d *WorkDay) CalculateExpectedCheckOut(now time.Time) time.Time {
+	byDuration := d.checkOutAfter(d.ExpectedMinutes(), now)
+	if byDuration.IsZero() || d.RestDay || len(d.Absences) > 0 {
+		return byDuration
+	}
+	return d.CheckOutRule.Apply(d.Date, d.FirstSession().CheckIn, byDuration)
+}
+
+// CalculateBalancedCheckOut calculates when the latest session can check out so that
+// earlier days' surplus (positive carry-over) or deficit (negative) is evened out, regardless of the check-out rule
+func (d *WorkDay) CalculateBalancedCheckOut(carryOverMinutes int, now time.Time) time.Time {
+	return d.checkOutAfter(d.ExpectedMinutes()-carryOverMinutes, now)
+}
+
+// checkOutAfter calculates when the latest session should check out for the day to reach the given work minutes,
+// a break in progress counts up to now
+func (d *WorkDay) checkOutAfter(targetMinutes int, now time.Time) time.Time {
+	last := d.LastSession()
+	if last == nil {
+		return time.Time{}
+	}
+
+	remaining := targetMinutes
+	for _, session := range d.Sessions[:len(d.Sessions)-1] {
+		remaining -= session.CalculateActualWorkMinutes()
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	current := *last
+	current.WorkHours = remaining
+	return current.CalculateExpectedCheckOut(now)
+}
+
+// CalculateOvertime calculates the day's overtime relative to the policy's threshold
+// A half-day leave halves the threshold, a rest day has none. Returns 0 while any session is still open
+func (d *WorkDay) CalculateOvertime(policy OvertimePolicy) int {
+	if !d.HasCheckedOut() {
+		return 0
+	}
+	if d.RestDay {
+		return policy.OvertimeAbove(d.CalculateActualWorkMinutes(), 0)
+	}
+	threshold := policy.Threshold(d.Date, d.WorkHours())
+	threshold = int(float64(threshold) * attendanceFraction(d.Absences))
+	return policy.OvertimeAbove(d.CalculateActualWorkMinutes(), threshold)
+}
+
+// CalculateBalance calculates the day's surplus (positive) or deficit (negative) against its expected work,
+// which leave shortens and a rest day does not have. Returns 0 while any session is still open
+func (d *WorkDay) CalculateBalance() int {
+	if !d.HasCheckedOut() {
+		return 0
+	}
+	if d.RestDay {
+		return d.CalculateActualWorkMinutes()
+	}
+	return d.CalculateActualWorkMinutes() - d.ExpectedMinutes()
 }
 
 // WorkConfig represents the global work configuration
@@ -61,6 +439,23 @@ type WorkConfig struct {
 	PRToken            string `json:"p_rtoken"`              // P-Rtoken header for HR API
 	CheckInWebhookURL  string `json:"check_in_webhook_url"`  // Webhook for check-in reminders
 	CheckOutWebhookURL string `json:"check_out_webhook_url"` // Webhook for check-out reminders
+
+	OvertimePolicy  OvertimePolicy  `json:"overtime_policy"`   // How overtime is counted
+	DayRolloverHour int             `json:"day_rollover_hour"` // Punches before this hour belong to the previous workday
+	WeeklySchedule  *WeekdayMinutes `json:"weekly_schedule"`   // Expected minutes per weekday, 0 is a day off; nil uses DefaultWorkHours
+	WorkCycle       *WorkCycle      `json:"work_cycle"`        // Rotation of work weeks, nil works the same days every week
+
+	OvertimeHourlyRate float64         `json:"overtime_hourly_rate"` // Base hourly wage that overtime pay multiplies
+	ComplianceRules    ComplianceRules `json:"compliance_rules"`     // Labor-law limits to warn about
+	BalancePolicy      BalancePolicy   `json:"balance_policy"`       // Carry-over of earlier days into today's leave time
+	RoundingPolicy     RoundingPolicy  `json:"rounding_policy"`      // Rounding of punches for worked minutes and overtime
+
+	CoreHours                  CoreHours `json:"core_hours"`                     // Flexi-time core hours that lateness is measured against
+	CheckInReminderLeadMinutes int       `json:"check_in_reminder_lead_minutes"` // Remind this long before the core start, 0 keeps the fixed 9:55 reminder
+
+	CheckOutRule CheckOutRule `json:"check_out_rule"` // Expected check-out by duration, fixed end time or the later of both
+
+	SourcePrecedence SourcePrecedence `json:"source_precedence"` // Which punch source may replace a time set by another, empty uses the default
 }
 
 // HasAPIConfig returns true if HR API is configured
@@ -75,34 +470,163 @@ func (c *WorkConfig) ShouldAutoFetch() bool {
 	return c.AutoFetchEnabled && c.HasAPIConfig()
 }
 
-// CalculateExpectedCheckOut calculates when the user should check out
+// WorkDate returns the workday (YYYY-MM-DD) a punch at the given time belongs to
+// With a rollover hour of 4, a punch at 01:30 still belongs to the previous day
+func (c *WorkConfig) WorkDate(t time.Time) string {
+	return t.Add(-time.Duration(c.DayRolloverHour) * time.Hour).Format("2006-01-02")
+}
+
+// EarliestOpenDate returns the earliest workday (YYYY-MM-DD) whose session may still be open at the given time
+// An overnight shift runs into the next workday but no further, an older open session was never checked out
+func (c *WorkConfig) EarliestOpenDate(t time.Time) string {
+	return dayBefore(c.WorkDate(t))
+}
+
+// dayBefore returns the date (YYYY-MM-DD) before a date
+func dayBefore(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return day.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// ExpectedMinutes returns the work minutes expected on a date (YYYY-MM-DD format)
+// A rest day of the work cycle expects none, a cycle workday the weekly schedule
+// leaves off (the Saturday of a long week) expects DefaultWorkHours
+func (c *WorkConfig) ExpectedMinutes(date string) int {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return c.DefaultWorkHours
+	}
+	if c.WorkCycle != nil && !c.WorkCycle.IsWorkday(day) {
+		return 0
+	}
+	if c.WeeklySchedule == nil {
+		return c.DefaultWorkHours
+	}
+	if minutes := c.WeeklySchedule[day.Weekday()]; minutes > 0 || c.WorkCycle == nil {
+		return minutes
+	}
+	return c.DefaultWorkHours
+}
+
+// hasSchedule returns true if a weekly schedule or work cycle decides the days off
+func (c *WorkConfig) hasSchedule() bool {
+	return c.WeeklySchedule != nil || c.WorkCycle != nil
+}
+
+// IsDayOff returns true if the weekly schedule or work cycle expects no work on a date
+func (c *WorkConfig) IsDayOff(date string) bool {
+	return c.hasSchedule() && c.ExpectedMinutes(date) == 0
+}
+
+// IsScheduledWorkday returns true if work is expected on a date,
+// Monday to Friday when no weekly schedule or work cycle is configured
+func (c *WorkConfig) IsScheduledWorkday(date string) bool {
+	if c.hasSchedule() {
+		return c.ExpectedMinutes(date) > 0
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false
+	}
+	return IsWeekday(day)
+}
+
+// IsWeekendWorkday returns true if the work cycle makes a Saturday or Sunday a workday,
+// which the holiday API still reports as a rest day
+func (c *WorkConfig) IsWeekendWorkday(date string) bool {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil || c.WorkCycle == nil || IsWeekday(day) {
+		return false
+	}
+	return c.WorkCycle.IsWorkday(day)
+}
+
+// IsWeekday returns true if the date falls on Monday to Friday
+func IsWeekday(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+// CountWorkdays counts the scheduled workdays of a month (YYYY-MM format)
+func (c *WorkConfig) CountWorkdays(yearMonth string) int {
+	first, err := time.Parse("2006-01", yearMonth)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		if c.IsScheduledWorkday(day.Format("2006-01-02")) {
+			count++
+		}
+	}
+	return count
+}
+
+// CalculateExpectedCheckOut calculates when the user should check out,
+// using the expected minutes of the check-in's workday and the check-out rule
 func (c *WorkConfig) CalculateExpectedCheckOut(checkInTime time.Time) time.Time {
-	return checkInTime.Add(time.Duration(c.DefaultWorkHours) * time.Minute)
+	date := c.WorkDate(checkInTime)
+	byDuration := checkInTime.Add(time.Duration(c.ExpectedMinutes(date)) * time.Minute)
+	if c.IsDayOff(date) {
+		return byDuration
+	}
+	return c.CheckOutRule.Apply(date, checkInTime, byDuration)
 }
 
 // MonthlyStats represents aggregated statistics for a month
 type MonthlyStats struct {
-	YearMonth       string // YYYY-MM format
-	TotalDays       int
-	CheckedOutDays  int
-	OvertimeMinutes int
+	YearMonth              string // YYYY-MM format
+	TotalDays              int    // days with at least one session, same as AttendedDays
+	TotalSessions          int
+	CheckedOutDays         int
+	WorkMinutes            int
+	OvertimeMinutes        int
+	AttendedDays           int     // days with at least one session
+	AbsentDays             float64 // days of leave, a half-day counts as 0.5
+	ExpectedWorkdays       int     // scheduled workdays of the month
+	WeekendOvertimeMinutes int     // part of OvertimeMinutes worked on days off
+	LateArrivals           int     // days first checked in after the core start
+	LateMinutes            int     // minutes of all late arrivals
+	EarlyDepartures        int     // days last checked out before the core end
+	EarlyDepartureMinutes  int     // minutes of all early departures
 }
 
-// CalculateStats aggregates statistics from multiple sessions
-func CalculateStats(sessions []*WorkSession, yearMonth string) *MonthlyStats {
+// CalculateStats aggregates statistics from the work days of a month, as built by BuildWorkDays, and its absences
+// Lateness is measured against the core hours and overtime follows the policy,
+// expectedWorkdays is the number of workdays the schedule has in the month
+func CalculateStats(days []*WorkDay, absences []*Absence, yearMonth string, expectedWorkdays int, core CoreHours, policy OvertimePolicy) *MonthlyStats {
 	stats := &MonthlyStats{
-		YearMonth:       yearMonth,
-		TotalDays:       len(sessions),
-		CheckedOutDays:  0,
-		OvertimeMinutes: 0,
+		YearMonth:        yearMonth,
+		TotalDays:        len(days),
+		CheckedOutDays:   0,
+		OvertimeMinutes:  0,
+		AttendedDays:     len(days),
+		AbsentDays:       AbsentDays(absences),
+		ExpectedWorkdays: expectedWorkdays,
 	}
 
-	for _, session := range sessions {
-		if session.HasCheckedOut() {
+	for _, day := range days {
+		stats.TotalSessions += len(day.Sessions)
+		stats.WorkMinutes += day.CalculateActualWorkMinutes()
+		if late := day.LateMinutes(core); late > 0 {
+			stats.LateArrivals++
+			stats.LateMinutes += late
+		}
+		if early := day.EarlyDepartureMinutes(core); early > 0 {
+			stats.EarlyDepartures++
+			stats.EarlyDepartureMinutes += early
+		}
+		if day.HasCheckedOut() {
 			stats.CheckedOutDays++
-			overtime := session.CalculateOvertime()
+			overtime := day.CalculateOvertime(policy)
 			if overtime > 0 {
 				stats.OvertimeMinutes += overtime
+				if day.RestDay {
+					stats.WeekendOvertimeMinutes += overtime
+				}
 			}
 		}
 	}
@@ -0,0 +1,798 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkSession_Breaks(t *testing.T) {
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	session := &WorkSession{
+		ID:        "session-1",
+		Date:      "2025-10-13",
+		CheckIn:   checkIn,
+		WorkHours: 480,
+	}
+
+	assert.ErrorIs(t, session.StartBreak("b0", checkIn.Add(-time.Minute)), ErrBreakTooEarly)
+	assert.ErrorIs(t, session.EndBreak(checkIn), ErrNotOnBreak)
+
+	assert.NoError(t, session.StartBreak("b1", checkIn.Add(3*time.Hour)))
+	assert.True(t, session.IsOnBreak())
+	assert.ErrorIs(t, session.StartBreak("b2", checkIn.Add(3*time.Hour)), ErrAlreadyOnBreak)
+	assert.Equal(t, 30, session.CalculateBreakMinutes(checkIn.Add(3*time.Hour+30*time.Minute)))
+	assert.Equal(t, checkIn.Add(8*time.Hour+30*time.Minute), session.CalculateExpectedCheckOut(checkIn.Add(3*time.Hour+30*time.Minute)))
+
+	assert.NoError(t, session.EndBreak(checkIn.Add(4*time.Hour)))
+	assert.False(t, session.IsOnBreak())
+	assert.Equal(t, 60, session.CalculateBreakMinutes(checkIn.Add(10*time.Hour)))
+	assert.Equal(t, checkIn.Add(9*time.Hour), session.CalculateExpectedCheckOut(checkIn.Add(10*time.Hour)))
+
+	checkOut := checkIn.Add(11 * time.Hour)
+	session.CheckOut = &checkOut
+	assert.Equal(t, 600, session.CalculateActualWorkMinutes())
+	assert.Equal(t, 0, session.CalculateOvertime(DefaultOvertimePolicy()))
+	assert.ErrorIs(t, session.StartBreak("b3", checkOut), ErrSessionClosed)
+}
+
+func TestWorkSession_OpenBreakEndsAtCheckOut(t *testing.T) {
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	checkOut := checkIn.Add(10 * time.Hour)
+	session := &WorkSession{
+		CheckIn:  checkIn,
+		CheckOut: &checkOut,
+		Breaks:   []Break{{ID: "b1", Start: checkIn.Add(9 * time.Hour)}},
+	}
+
+	assert.Equal(t, 60, session.CalculateBreakMinutes(checkOut.Add(5*time.Hour)))
+	assert.Equal(t, 540, session.CalculateActualWorkMinutes())
+}
+
+func TestWorkDay_SplitShift(t *testing.T) {
+	morningIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	morningOut := morningIn.Add(4 * time.Hour)
+	eveningIn := time.Date(2025, 10, 13, 19, 0, 0, 0, time.Local)
+
+	evening := &WorkSession{ID: "s2", Date: "2025-10-13", CheckIn: eveningIn, WorkHours: 480}
+	morning := &WorkSession{ID: "s1", Date: "2025-10-13", CheckIn: morningIn, CheckOut: &morningOut, WorkHours: 480}
+	day := NewWorkDay("2025-10-13", []*WorkSession{evening, morning})
+
+	assert.Equal(t, morning, day.FirstSession())
+	assert.Equal(t, evening, day.LastSession())
+	assert.False(t, day.HasCheckedOut())
+	assert.Equal(t, 0, day.CalculateOvertime(DefaultOvertimePolicy()))
+	assert.Equal(t, eveningIn.Add(4*time.Hour), day.CalculateExpectedCheckOut(eveningIn))
+
+	eveningOut := eveningIn.Add(7 * time.Hour)
+	evening.CheckOut = &eveningOut
+	assert.True(t, day.HasCheckedOut())
+	assert.Equal(t, 660, day.CalculateActualWorkMinutes())
+	assert.Equal(t, 60, day.CalculateOvertime(DefaultOvertimePolicy()))
+}
+
+func TestCalculateStats_GroupsSessionsByDay(t *testing.T) {
+	session := func(id, date string, in time.Time, minutes int) *WorkSession {
+		out := in.Add(time.Duration(minutes) * time.Minute)
+		return &WorkSession{ID: id, Date: date, CheckIn: in, CheckOut: &out, WorkHours: 480}
+	}
+	day1 := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+
+	stats := monthStats([]*WorkSession{
+		session("a", "2025-10-13", day1, 360),
+		session("b", "2025-10-13", day1.Add(8*time.Hour), 300),
+		session("c", "2025-10-14", day2, 540),
+	}, []*Absence{
+		{ID: "leave", Date: "2025-10-15", Type: AbsenceAnnualLeave, Amount: AbsenceHalfDay},
+	}, "2025-10", &WorkConfig{OvertimePolicy: DefaultOvertimePolicy()})
+
+	assert.Equal(t, 2, stats.TotalDays)
+	assert.Equal(t, 3, stats.TotalSessions)
+	assert.Equal(t, 2, stats.CheckedOutDays)
+	assert.Equal(t, 1200, stats.WorkMinutes)
+	assert.Equal(t, 60, stats.OvertimeMinutes)
+	assert.Equal(t, 2, stats.AttendedDays)
+	assert.Equal(t, 0.5, stats.AbsentDays)
+	assert.Equal(t, 23, stats.ExpectedWorkdays)
+}
+
+func TestWorkDay_HalfDayLeave(t *testing.T) {
+	checkIn := time.Date(2025, 10, 13, 13, 0, 0, 0, time.Local)
+	checkOut := checkIn.Add(5 * time.Hour)
+	day := NewWorkDay("2025-10-13", []*WorkSession{
+		{ID: "s1", Date: "2025-10-13", CheckIn: checkIn, CheckOut: &checkOut, WorkHours: 480},
+	})
+	day.Absences = []*Absence{{ID: "a1", Date: "2025-10-13", Type: AbsenceSickLeave, Amount: AbsenceHalfDay}}
+
+	assert.Equal(t, 240, day.ExpectedMinutes())
+	assert.Equal(t, 0, day.CalculateOvertime(DefaultOvertimePolicy()))
+	assert.Equal(t, 60, day.CalculateOvertime(OvertimePolicy{Mode: OvertimeModeRelative}))
+}
+
+func TestOvertimePolicy(t *testing.T) {
+	monday := "2025-10-13"
+	friday := "2025-10-17"
+	weekday := WeekdayMinutes{}
+	weekday[time.Monday] = 540
+	weekday[time.Friday] = 420
+
+	tests := []struct {
+		name   string
+		policy OvertimePolicy
+		date   string
+		actual int
+		want   int
+	}{
+		{"fixed threshold", DefaultOvertimePolicy(), monday, 630, 30},
+		{"fixed under-time", DefaultOvertimePolicy(), monday, 540, -60},
+		{"relative to work hours", OvertimePolicy{Mode: OvertimeModeRelative}, monday, 510, 30},
+		{"per weekday", OvertimePolicy{Mode: OvertimeModeWeekday, WeekdayThresholds: weekday}, friday, 480, 60},
+		{"within grace period", OvertimePolicy{Mode: OvertimeModeRelative, GraceMinutes: 15}, monday, 490, 0},
+		{"beyond grace period", OvertimePolicy{Mode: OvertimeModeRelative, GraceMinutes: 15}, monday, 500, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.policy.Validate())
+			assert.Equal(t, tt.want, tt.policy.Overtime(tt.actual, tt.date, 480))
+		})
+	}
+
+	assert.Error(t, OvertimePolicy{Mode: "hourly"}.Validate())
+	assert.Error(t, OvertimePolicy{Mode: OvertimeModeFixed, GraceMinutes: -1}.Validate())
+}
+
+func TestWorkConfig_WorkDate(t *testing.T) {
+	config := &WorkConfig{DayRolloverHour: 4}
+
+	assert.Equal(t, "2025-10-13", config.WorkDate(time.Date(2025, 10, 13, 20, 0, 0, 0, time.Local)))
+	assert.Equal(t, "2025-10-13", config.WorkDate(time.Date(2025, 10, 14, 1, 30, 0, 0, time.Local)))
+	assert.Equal(t, "2025-10-14", config.WorkDate(time.Date(2025, 10, 14, 4, 0, 0, 0, time.Local)))
+	assert.Equal(t, "2025-10-14", (&WorkConfig{}).WorkDate(time.Date(2025, 10, 14, 1, 30, 0, 0, time.Local)))
+
+	// A session of the workday before may still be open, an overnight shift runs into the next one
+	assert.Equal(t, "2025-10-12", config.EarliestOpenDate(time.Date(2025, 10, 14, 1, 30, 0, 0, time.Local)))
+	assert.Equal(t, "2025-10-13", config.EarliestOpenDate(time.Date(2025, 10, 14, 4, 0, 0, 0, time.Local)))
+	assert.Equal(t, "2025-02-28", config.EarliestOpenDate(time.Date(2025, 3, 1, 9, 0, 0, 0, time.Local)))
+}
+
+func TestTimeBank_RunningBalance(t *testing.T) {
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	checkOut := checkIn.Add(11 * time.Hour)
+	day := NewWorkDay("2025-10-13", []*WorkSession{
+		{ID: "s1", Date: "2025-10-13", CheckIn: checkIn, CheckOut: &checkOut, WorkHours: 480},
+	})
+
+	daily := NewDailyTimeBankEntry(day, checkOut)
+	assert.Equal(t, "daily-2025-10-13", daily.ID)
+	assert.Equal(t, 180, daily.Minutes)
+
+	compOff := &TimeBankEntry{ID: "c1", Date: "2025-10-14", Kind: TimeBankCompOff, Minutes: -90}
+	assert.NoError(t, compOff.Validate())
+	assert.Error(t, (&TimeBankEntry{Date: "2025-10-14", Kind: TimeBankCompOff, Minutes: 90}).Validate())
+
+	points := RunningBalance(120, []*TimeBankEntry{daily, compOff})
+	assert.Equal(t, 300, points[0].BalanceMinutes)
+	assert.Equal(t, 210, points[1].BalanceMinutes)
+
+	day.Sessions[0].CheckOut = nil
+	assert.Nil(t, NewDailyTimeBankEntry(day, checkOut))
+}
+
+func TestTimeBank_DailyEntryAgainstExpectedWork(t *testing.T) {
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	checkOut := checkIn.Add(8 * time.Hour)
+	day := NewWorkDay("2025-10-13", []*WorkSession{
+		{ID: "s1", Date: "2025-10-13", CheckIn: checkIn, CheckOut: &checkOut, WorkHours: 480},
+	})
+
+	// An exact 8h day books nothing, whatever the overtime threshold of the default policy
+	assert.Equal(t, 0, NewDailyTimeBankEntry(day, checkOut).Minutes)
+	assert.Equal(t, -120, day.CalculateOvertime(DefaultOvertimePolicy()))
+
+	// A half day of leave halves the expected work
+	day.Absences = []*Absence{{ID: "a1", Date: "2025-10-13", Type: AbsenceAnnualLeave, Amount: AbsenceHalfDay}}
+	assert.Equal(t, 240, NewDailyTimeBankEntry(day, checkOut).Minutes)
+
+	// A rest day credits all of its work
+	day.Absences = nil
+	day.RestDay = true
+	assert.Equal(t, 480, NewDailyTimeBankEntry(day, checkOut).Minutes)
+}
+
+func TestWorkConfig_WeeklySchedule(t *testing.T) {
+	config := &WorkConfig{DefaultWorkHours: 480}
+	assert.Equal(t, 480, config.ExpectedMinutes("2025-10-17"))
+	assert.False(t, config.IsDayOff("2025-10-18"))
+	assert.False(t, config.IsScheduledWorkday("2025-10-18"))
+	assert.Equal(t, 23, config.CountWorkdays("2025-10"))
+
+	config.WeeklySchedule = &WeekdayMinutes{0, 480, 480, 240, 480, 420, 0}
+	assert.Equal(t, 420, config.ExpectedMinutes("2025-10-17"))
+	assert.Equal(t, 240, config.ExpectedMinutes("2025-10-15"))
+	assert.True(t, config.IsDayOff("2025-10-19"))
+	assert.Equal(t, 23, config.CountWorkdays("2025-10"))
+
+	checkIn := time.Date(2025, 10, 17, 9, 0, 0, 0, time.Local)
+	assert.Equal(t, checkIn.Add(7*time.Hour), config.CalculateExpectedCheckOut(checkIn))
+}
+
+func TestWorkConfig_WorkCycle(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	config := &WorkConfig{
+		DefaultWorkHours: 480,
+		WorkCycle: &WorkCycle{
+			AnchorDate: "2025-10-08",
+			Weeks:      [][]time.Weekday{weekdays, append(weekdays, time.Saturday)},
+		},
+	}
+	assert.NoError(t, config.WorkCycle.Validate())
+
+	assert.True(t, config.IsDayOff("2025-10-11"))
+	assert.Equal(t, 480, config.ExpectedMinutes("2025-10-18"))
+	assert.True(t, config.IsWeekendWorkday("2025-10-18"))
+	assert.True(t, config.IsWeekendWorkday("2025-10-04"))
+	assert.False(t, config.IsWeekendWorkday("2025-10-17"))
+	assert.Equal(t, 25, config.CountWorkdays("2025-10"))
+
+	checkIn := time.Date(2025, 10, 11, 10, 0, 0, 0, time.Local)
+	checkOut := checkIn.Add(3 * time.Hour)
+	sessions := []*WorkSession{
+		{ID: "s1", Date: "2025-10-11", CheckIn: checkIn, CheckOut: &checkOut, WorkHours: 0},
+	}
+	stats := monthStats(sessions, nil, "2025-10", config)
+	assert.Equal(t, 180, stats.OvertimeMinutes)
+	assert.Equal(t, 180, stats.WeekendOvertimeMinutes)
+}
+
+func TestCalculateOvertimePay(t *testing.T) {
+	workday := time.Date(2025, 9, 30, 9, 0, 0, 0, time.Local)
+	workdayOut := workday.Add(11 * time.Hour)
+	holiday := time.Date(2025, 10, 1, 10, 0, 0, 0, time.Local)
+	holidayOut := holiday.Add(2 * time.Hour)
+	restDay := time.Date(2025, 10, 11, 10, 0, 0, 0, time.Local)
+	restDayOut := restDay.Add(90 * time.Minute)
+
+	days := GroupByDay([]*WorkSession{
+		{ID: "s1", Date: "2025-09-30", CheckIn: workday, CheckOut: &workdayOut, WorkHours: 480},
+		{ID: "s2", Date: "2025-10-01", CheckIn: holiday, CheckOut: &holidayOut, WorkHours: 480},
+		{ID: "s3", Date: "2025-10-11", CheckIn: restDay, CheckOut: &restDayOut, WorkHours: 480},
+	})
+	dayTypes := map[string]DayType{
+		"2025-10-01": DayTypeHoliday,
+		"2025-10-11": DayTypeRestDay,
+	}
+
+	pay := CalculateOvertimePay(days, dayTypes, "2025-10", DefaultOvertimePolicy(), 40)
+	assert.Equal(t, 60, pay.WorkdayMinutes)
+	assert.Equal(t, 90, pay.RestDayMinutes)
+	assert.Equal(t, 120, pay.HolidayMinutes)
+	assert.Equal(t, 60.0, pay.WorkdayPay)
+	assert.Equal(t, 120.0, pay.RestDayPay)
+	assert.Equal(t, 240.0, pay.HolidayPay)
+	assert.Equal(t, 420.0, pay.TotalPay)
+	assert.Len(t, pay.Days, 3)
+}
+
+func TestEvaluateCompliance(t *testing.T) {
+	config := &WorkConfig{
+		DefaultWorkHours: 480,
+		OvertimePolicy:   DefaultOvertimePolicy(),
+		ComplianceRules:  DefaultComplianceRules(),
+	}
+	config.ComplianceRules.MaxMonthlyOvertimeMinutes = 120
+
+	var sessions []*WorkSession
+	for _, date := range []string{"2025-10-13", "2025-10-14"} {
+		checkIn, _ := time.ParseInLocation("2006-01-02 15:04", date+" 09:00", time.Local)
+		checkOut := checkIn.Add(11*time.Hour + 30*time.Minute)
+		sessions = append(sessions, &WorkSession{ID: date, Date: date, CheckIn: checkIn, CheckOut: &checkOut, WorkHours: 480})
+	}
+	// Checked out at 20:30 and back at 06:00, only 9.5 hours of rest
+	early := time.Date(2025, 10, 15, 6, 0, 0, 0, time.Local)
+	sessions = append(sessions, &WorkSession{ID: "2025-10-15", Date: "2025-10-15", CheckIn: early, WorkHours: 480})
+
+	now := early.Add(2 * time.Hour)
+	from, to := CompliancePeriod(config, now)
+	assert.Equal(t, "2025-09-30", from)
+	assert.Equal(t, "2025-10-15", to)
+
+	// The rest after an ordinary check-out is not judged before the next check-in
+	issues := EvaluateCompliance(BuildWorkDays(sessions[:2], nil, config), config, sessions[1].CheckOut.Add(time.Hour))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, ComplianceMonthlyOvertime, issues[0].Rule)
+
+	issues = EvaluateCompliance(BuildWorkDays(sessions, nil, config), config, now)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, ComplianceMonthlyOvertime, issues[0].Rule)
+	assert.Equal(t, ComplianceViolation, issues[0].Severity)
+	assert.Equal(t, 180, issues[0].ActualMinutes)
+	assert.Equal(t, ComplianceDailyRest, issues[1].Rule)
+	assert.Equal(t, 570, issues[1].ActualMinutes)
+
+	config.ComplianceRules.MaxWeeklyWorkMinutes = 1500
+	issues = EvaluateCompliance(BuildWorkDays(sessions, nil, config), config, now)
+	assert.Equal(t, ComplianceWeeklyHours, issues[1].Rule)
+	assert.Equal(t, ComplianceWarning, issues[1].Severity)
+	assert.Equal(t, 1500, issues[1].LimitMinutes)
+}
+
+func TestBalancePolicy_CarryOver(t *testing.T) {
+	policy := BalancePolicy{Mode: BalanceModeWeek, MaxCarryOverMinutes: 90}
+	from, to, ok := policy.Period("2025-10-16")
+	assert.True(t, ok)
+	assert.Equal(t, "2025-10-13", from)
+	assert.Equal(t, "2025-10-15", to)
+	_, _, ok = policy.Period("2025-10-13")
+	assert.False(t, ok)
+
+	// Left two hours early yesterday
+	yesterday := time.Date(2025, 10, 15, 9, 0, 0, 0, time.Local)
+	yesterdayOut := yesterday.Add(6 * time.Hour)
+	days := GroupByDay([]*WorkSession{
+		{ID: "s1", Date: "2025-10-15", CheckIn: yesterday, CheckOut: &yesterdayOut, WorkHours: 480},
+	})
+	assert.Equal(t, -90, policy.CarryOver(days))
+	policy.MaxCarryOverMinutes = 0
+	assert.Equal(t, -120, policy.CarryOver(days))
+	// Work on a rest day counts in full
+	restDayIn := time.Date(2025, 10, 12, 10, 0, 0, 0, time.Local)
+	restDayOut := restDayIn.Add(time.Hour)
+	restDay := NewWorkDay("2025-10-12", []*WorkSession{
+		{ID: "s0", Date: "2025-10-12", CheckIn: restDayIn, CheckOut: &restDayOut, WorkHours: 480},
+	})
+	restDay.RestDay = true
+	assert.Equal(t, -60, policy.CarryOver(append(days, restDay)))
+
+	checkIn := time.Date(2025, 10, 16, 9, 0, 0, 0, time.Local)
+	today := NewWorkDay("2025-10-16", []*WorkSession{
+		{ID: "s2", Date: "2025-10-16", CheckIn: checkIn, WorkHours: 480},
+	})
+	assert.Equal(t, checkIn.Add(8*time.Hour), today.CalculateExpectedCheckOut(checkIn))
+	assert.Equal(t, checkIn.Add(10*time.Hour), today.CalculateBalancedCheckOut(-120, checkIn))
+}
+
+func TestRoundingPolicy(t *testing.T) {
+	policy := RoundingPolicy{IntervalMinutes: 15, CheckIn: RoundingUp, CheckOut: RoundingDown, GraceMinutes: 3}
+	assert.NoError(t, policy.Validate())
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 10, 16, hour, minute, 0, 0, time.Local)
+	}
+	assert.Equal(t, at(9, 15), policy.Round(at(9, 7), RoundingUp))
+	assert.Equal(t, at(9, 0), policy.Round(at(9, 2), RoundingUp)) // within the grace window
+	assert.Equal(t, at(18, 0), policy.Round(at(18, 10), RoundingDown))
+	assert.Equal(t, at(18, 30), policy.Round(at(18, 28), RoundingDown)) // within the grace window
+	assert.Equal(t, at(18, 15), policy.Round(at(18, 20), RoundingNearest))
+	assert.Equal(t, at(18, 20), policy.Round(at(18, 20), RoundingNone))
+
+	checkOut := at(19, 10)
+	session := &WorkSession{ID: "s1", Date: "2025-10-16", CheckIn: at(9, 7), CheckOut: &checkOut, WorkHours: 480}
+	rounded := session.Rounded(policy)
+	assert.Equal(t, at(9, 7), session.CheckIn)
+	assert.Equal(t, at(9, 15), rounded.CheckIn)
+	assert.Equal(t, at(19, 0), *rounded.CheckOut)
+	assert.Equal(t, 603, session.CalculateActualWorkMinutes())
+	assert.Equal(t, 585, rounded.CalculateActualWorkMinutes())
+
+	stats := monthStats([]*WorkSession{session}, nil, "2025-10", &WorkConfig{
+		DefaultWorkHours: 480,
+		OvertimePolicy:   DefaultOvertimePolicy(),
+		RoundingPolicy:   policy,
+	})
+	assert.Equal(t, 585, stats.WorkMinutes)
+	assert.Equal(t, 0, stats.OvertimeMinutes)
+}
+
+func TestCoreHours_Lateness(t *testing.T) {
+	core := CoreHours{Start: "10:00", End: "16:00"}
+	assert.NoError(t, core.Validate())
+	assert.Error(t, CoreHours{Start: "16:00", End: "10:00"}.Validate())
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 10, day, hour, minute, 0, 0, time.Local)
+	}
+	lateOut := at(16, 15, 30)
+	late := &WorkSession{ID: "s1", Date: "2025-10-16", CheckIn: at(16, 10, 20), CheckOut: &lateOut, WorkHours: 480}
+	assert.Equal(t, 20, late.LateMinutes(core))
+	assert.Equal(t, 30, late.EarlyDepartureMinutes(core))
+
+	onTimeOut := at(17, 19, 0)
+	onTime := &WorkSession{ID: "s2", Date: "2025-10-17", CheckIn: at(17, 9, 30), CheckOut: &onTimeOut, WorkHours: 480}
+	assert.Equal(t, 0, onTime.LateMinutes(core))
+	assert.Equal(t, 0, onTime.EarlyDepartureMinutes(core))
+
+	// Saturday is a day off in the schedule, lateness does not count
+	weekendOut := at(18, 12, 0)
+	weekend := &WorkSession{ID: "s3", Date: "2025-10-18", CheckIn: at(18, 11, 0), CheckOut: &weekendOut, WorkHours: 480}
+
+	schedule := WeekdayMinutes{0, 480, 480, 480, 480, 480, 0}
+	config := &WorkConfig{
+		DefaultWorkHours:           480,
+		OvertimePolicy:             DefaultOvertimePolicy(),
+		WeeklySchedule:             &schedule,
+		CoreHours:                  core,
+		CheckInReminderLeadMinutes: 15,
+	}
+	stats := monthStats([]*WorkSession{late, onTime, weekend}, nil, "2025-10", config)
+	assert.Equal(t, 1, stats.LateArrivals)
+	assert.Equal(t, 20, stats.LateMinutes)
+	assert.Equal(t, 1, stats.EarlyDepartures)
+	assert.Equal(t, 30, stats.EarlyDepartureMinutes)
+
+	// A split shift leaving at lunch and coming back is neither late nor leaving early
+	morningOut := at(20, 12, 0)
+	eveningOut := at(20, 18, 0)
+	split := []*WorkSession{
+		{ID: "s4", Date: "2025-10-20", CheckIn: at(20, 9, 0), CheckOut: &morningOut, WorkHours: 480},
+		{ID: "s5", Date: "2025-10-20", CheckIn: at(20, 13, 0), CheckOut: &eveningOut, WorkHours: 480},
+	}
+	stats = monthStats(split, nil, "2025-10", config)
+	assert.Equal(t, 0, stats.LateArrivals)
+	assert.Equal(t, 0, stats.EarlyDepartures)
+	day := NewWorkDay("2025-10-20", split)
+	assert.Equal(t, 0, day.LateMinutes(core))
+	assert.Equal(t, 0, day.EarlyDepartureMinutes(core))
+
+	reminder, ok := config.CheckInReminderTime()
+	assert.True(t, ok)
+	assert.Equal(t, "09:45", reminder)
+}
+
+func TestCheckOutRule(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 10, 16, hour, minute, 0, 0, time.Local)
+	}
+	day := func(checkIn time.Time, rule CheckOutRule) *WorkDay {
+		d := NewWorkDay("2025-10-16", []*WorkSession{
+			{ID: "s1", Date: "2025-10-16", CheckIn: checkIn, WorkHours: 480},
+		})
+		d.CheckOutRule = rule
+		return d
+	}
+
+	assert.Error(t, CheckOutRule{Mode: CheckOutModeMax}.Validate())
+	assert.Equal(t, at(17, 0), day(at(9, 0), DefaultCheckOutRule()).CalculateExpectedCheckOut(at(12, 0)))
+
+	fixed := CheckOutRule{Mode: CheckOutModeFixedEnd, EarliestEnd: "18:00", LatestStart: "10:00"}
+	assert.NoError(t, fixed.Validate())
+	assert.Equal(t, at(18, 0), day(at(9, 0), fixed).CalculateExpectedCheckOut(at(12, 0)))
+	assert.Equal(t, at(18, 0), day(at(10, 0), fixed).CalculateExpectedCheckOut(at(12, 0)))
+	// Starting after the latest start also has to reach the work hours
+	assert.Equal(t, at(18, 30), day(at(10, 30), fixed).CalculateExpectedCheckOut(at(12, 0)))
+
+	latest := CheckOutRule{Mode: CheckOutModeMax, EarliestEnd: "18:00"}
+	assert.Equal(t, at(18, 0), day(at(9, 0), latest).CalculateExpectedCheckOut(at(12, 0)))
+	assert.Equal(t, at(18, 45), day(at(10, 45), latest).CalculateExpectedCheckOut(at(12, 0)))
+	// Evening out a surplus is not held back by the earliest end
+	assert.Equal(t, at(16, 0), day(at(9, 0), latest).CalculateBalancedCheckOut(60, at(12, 0)))
+
+	// Leave shortens the day, the fixed end no longer applies
+	halfDay := day(at(13, 0), latest)
+	halfDay.Absences = []*Absence{{ID: "a1", Date: "2025-10-16", Type: AbsenceAnnualLeave, Amount: AbsenceHalfDay}}
+	assert.Equal(t, at(17, 0), halfDay.CalculateExpectedCheckOut(at(13, 0)))
+
+	config := &WorkConfig{DefaultWorkHours: 480, CheckOutRule: latest}
+	assert.Equal(t, at(18, 0), config.CalculateExpectedCheckOut(at(9, 0)))
+}
+
+func TestAllocations(t *testing.T) {
+	checkOut := time.Date(2025, 10, 16, 18, 0, 0, 0, time.Local)
+	session := &WorkSession{ID: "s1", Date: "2025-10-16", CheckIn: checkOut.Add(-8 * time.Hour), CheckOut: &checkOut, WorkHours: 480}
+	worked := session.CalculateActualWorkMinutes()
+
+	allocations := []*Allocation{
+		{ID: "a1", SessionID: "s1", ProjectID: "p1", Task: "review", Minutes: 120},
+		{ID: "a2", SessionID: "s1", ProjectID: "p2", Percent: 50},
+		{ID: "a3", SessionID: "s1", ProjectID: "p1", Task: "build", Minutes: 60},
+	}
+	assert.NoError(t, ValidateAllocations(allocations, worked))
+	assert.Error(t, ValidateAllocations(append(allocations, &Allocation{ProjectID: "p3", Minutes: 61}), worked))
+	assert.ErrorIs(t, ValidateAllocations([]*Allocation{{ProjectID: "p1", Minutes: 10, Percent: 10}}, worked), ErrInvalidAllocation)
+	assert.ErrorIs(t, ValidateAllocations([]*Allocation{{ProjectID: "p1", Percent: 60}, {ProjectID: "p2", Percent: 60}}, 0), ErrInvalidAllocation)
+
+	// A session shortened to 5 hours keeps half of it for p2, the minutes are cut from the last allocation first
+	clamped, changed := ClampAllocations(allocations, 300)
+	assert.True(t, changed)
+	assert.NoError(t, ValidateAllocations(clamped, 300))
+	assert.Equal(t, 120, clamped[0].Minutes)
+	assert.Equal(t, 30, clamped[2].Minutes)
+	assert.Equal(t, 60, allocations[2].Minutes)
+	clamped, _ = ClampAllocations(allocations, 150)
+	assert.Len(t, clamped, 2)
+	assert.Equal(t, 75, clamped[0].Minutes)
+	assert.Equal(t, "a2", clamped[1].ID)
+	_, changed = ClampAllocations(allocations, worked)
+	assert.False(t, changed)
+
+	totals, unallocated := SumAllocations([]*WorkSession{session}, allocations)
+	assert.Len(t, totals, 2)
+	assert.Equal(t, "p2", totals[0].ProjectID)
+	assert.Equal(t, 240, totals[0].Minutes)
+	assert.Equal(t, 180, totals[1].Minutes)
+	assert.Equal(t, 120, totals[1].Tasks["review"])
+	assert.Equal(t, 60, unallocated)
+}
+
+func TestSessionNotesAndFilter(t *testing.T) {
+	session := &WorkSession{ID: "s1", Date: "2025-10-16", CheckIn: time.Date(2025, 10, 16, 9, 0, 0, 0, time.Local), WorkHours: 480}
+	assert.NoError(t, session.SetNote("Hotfix for the **payment** outage", []string{" Release Night ", "on-call", "release night", ""}))
+	assert.Equal(t, []string{"release-night", "on-call"}, session.Tags)
+	assert.True(t, session.HasTag("Release night"))
+	assert.Error(t, session.SetNote("", []string{strings.Repeat("x", MaxTagLength+1)}))
+
+	filter := SessionFilter{From: "2025-10-01", To: "2025-10-31"}
+	assert.NoError(t, filter.Validate())
+	assert.True(t, filter.Matches(session, 0))
+
+	filter.Tag = "on-call"
+	filter.Text = "PAYMENT"
+	assert.True(t, filter.Matches(session, 0))
+
+	filter.MinOvertimeMinutes = 60
+	assert.False(t, filter.Matches(session, 30))
+	assert.True(t, filter.Matches(session, 90))
+
+	filter.Text = "client"
+	assert.False(t, filter.Matches(session, 90))
+
+	assert.False(t, SessionFilter{From: "2025-11-01", To: "2025-11-30"}.Matches(session, 0))
+	assert.Error(t, SessionFilter{From: "2025-11-30", To: "2025-11-01"}.Validate())
+}
+
+func TestCrossesOvertime(t *testing.T) {
+	assert.True(t, CrossesOvertime(30, 240))  // day went from 210 minutes short to 30 over
+	assert.True(t, CrossesOvertime(30, 30))   // day was exactly at its hours before
+	assert.False(t, CrossesOvertime(30, 20))  // day was already 10 minutes over
+	assert.False(t, CrossesOvertime(0, 240))  // day is not in overtime
+	assert.False(t, CrossesOvertime(-60, 60)) // day is still short
+}
+
+func TestSessionState(t *testing.T) {
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	now := checkIn.Add(10 * time.Hour)
+	session := &WorkSession{ID: "s1", Date: "2025-10-13", CheckIn: checkIn}
+	assert.Equal(t, SessionOpen, session.State())
+
+	// Check-ins and check-outs must be in order and not in the future
+	assert.ErrorIs(t, ValidateCheckIn(now.Add(time.Hour), now), ErrCheckInInFuture)
+	assert.NoError(t, ValidateCheckIn(now.Add(MaxClockSkew), now))
+	assert.ErrorIs(t, session.Close(checkIn.Add(-time.Minute), now, false), ErrCheckOutBeforeCheckIn)
+	assert.ErrorIs(t, session.Close(now.Add(time.Hour), now, false), ErrCheckOutInFuture)
+	assert.False(t, session.HasCheckedOut())
+
+	assert.NoError(t, session.StartBreak("b1", checkIn.Add(3*time.Hour)))
+	assert.Equal(t, SessionOnBreak, session.State())
+	assert.ErrorIs(t, session.Close(checkIn.Add(2*time.Hour), now, false), ErrCheckOutBeforeBreak)
+
+	// Closing ends the break in progress
+	assert.NoError(t, session.Close(checkIn.Add(9*time.Hour), now, true))
+	assert.Equal(t, SessionAutoClosed, session.State())
+	assert.False(t, session.Breaks[0].IsOpen())
+
+	// A user correction turns an auto-close into a close, the break it ended now ends earlier too
+	assert.NoError(t, session.Close(checkIn.Add(8*time.Hour), now, false))
+	assert.Equal(t, SessionClosed, session.State())
+	assert.Equal(t, checkIn.Add(8*time.Hour), *session.Breaks[0].End)
+	assert.Equal(t, 3*60, session.CalculateActualWorkMinutes())
+
+	// Whether the HR system may replace the user's check-out is up to the source precedence
+	assert.True(t, SessionClosed.CanTransitionTo(SessionAutoClosed))
+
+	assert.NoError(t, session.Reopen(checkIn.Add(time.Hour), now))
+	assert.Equal(t, SessionOpen, session.State())
+	assert.Empty(t, session.Breaks)
+
+	// Nothing happens to a void session
+	assert.NoError(t, session.Void())
+	assert.Equal(t, SessionVoid, session.State())
+	var transitionErr *TransitionError
+	err := session.Close(now, now, false)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, SessionVoid, transitionErr.From)
+	assert.ErrorIs(t, session.Reopen(checkIn, now), ErrInvalidTransition)
+	assert.ErrorIs(t, session.StartBreak("b2", now), ErrInvalidTransition)
+	assert.ErrorIs(t, session.EndBreak(now), ErrInvalidTransition)
+	assert.ErrorIs(t, session.Void(), ErrInvalidTransition)
+}
+
+func TestDeriveSessions(t *testing.T) {
+	config := &WorkConfig{DefaultWorkHours: 480}
+	day := time.Date(2025, 10, 13, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	punch := func(id string, kind PunchKind, when time.Time, source PunchSource, recorded time.Time) *Punch {
+		return &Punch{ID: id, Kind: kind, At: when, Source: source, RecordedAt: recorded}
+	}
+
+	punches := []*Punch{
+		punch("p1", PunchCheckIn, at(9, 10), PunchSourceManual, at(9, 10)),
+		// Corrected later to an earlier check-in, the session keeps the ID of its first punch
+		punch("p2", PunchCheckIn, at(9, 0), PunchSourceManual, at(9, 30)),
+		punch("p3", PunchBreakStart, at(12, 0), PunchSourceManual, at(12, 0)),
+		punch("p4", PunchBreakEnd, at(12, 30), PunchSourceManual, at(12, 30)),
+		punch("p5", PunchCheckOut, at(17, 0), PunchSourceManual, at(17, 0)),
+		// Back in the evening for a second session, the HR system checks it out
+		punch("p6", PunchCheckIn, at(22, 0), PunchSourceManual, at(22, 0)),
+		punch("p7", PunchCheckOut, at(25, 0), PunchSourceSchedulerSync, at(25, 5)),
+		// A break outside any session no longer applies
+		punch("p8", PunchBreakEnd, at(26, 0), PunchSourceManual, at(26, 0)),
+	}
+
+	sessions, skipped := DeriveSessions(punches, config)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, []*Punch{punches[7]}, skipped)
+
+	first := sessions[0]
+	assert.Equal(t, "p1", first.ID)
+	assert.Equal(t, "2025-10-13", first.Date)
+	assert.Equal(t, at(9, 0), first.CheckIn)
+	assert.Equal(t, SessionClosed, first.State())
+	assert.Equal(t, "p3", first.Breaks[0].ID)
+	assert.Equal(t, 7*60+30, first.CalculateActualWorkMinutes())
+
+	second := sessions[1]
+	assert.Equal(t, "p6", second.ID)
+	assert.Equal(t, SessionAutoClosed, second.State())
+
+	// Replaying is deterministic, and follows a rule change: with a rollover at 4:00 the night stays on the 13th
+	config.DayRolloverHour = 4
+	sessions, _ = DeriveSessions(append(punches[:7:7], punch("p9", PunchCheckIn, at(27, 0), PunchSourceManual, at(27, 0))), config)
+	assert.Len(t, sessions, 3)
+	assert.Equal(t, "2025-10-13", sessions[1].Date)
+	assert.Equal(t, "2025-10-13", sessions[2].Date)
+
+	// A session left open for days is not checked out by a later day's punch, that one has no check-in
+	config.DayRolloverHour = 0
+	forgotten := []*Punch{
+		punch("f1", PunchCheckIn, at(9, 0), PunchSourceManual, at(9, 0)),
+		punch("f2", PunchCheckOut, at(2*24+18, 0), PunchSourceManual, at(2*24+18, 0)),
+	}
+	sessions, skipped = DeriveSessions(forgotten, config)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, SessionOpen, sessions[0].State())
+	assert.Equal(t, []*Punch{forgotten[1]}, skipped)
+	config.DayRolloverHour = 4
+
+	// A check-in before a checked-out session does not reopen it, one within it corrects its check-in
+	checkedOut := at(17, 0)
+	closed := []*WorkSession{{ID: "c1", Date: "2025-10-13", CheckIn: at(13, 0), CheckOut: &checkedOut, WorkHours: 480}}
+	_, _, err := ApplyPunch(closed, punch("c2", PunchCheckIn, at(12, 0), PunchSourceManual, at(18, 0)), config)
+	assert.ErrorIs(t, err, ErrCheckInBeforeSession)
+	assert.True(t, closed[0].HasCheckedOut())
+	session, previous, err := ApplyPunch(closed, punch("c3", PunchCheckIn, at(14, 0), PunchSourceManual, at(18, 0)), config)
+	assert.NoError(t, err)
+	assert.Equal(t, SessionClosed, previous)
+	assert.Equal(t, at(14, 0), session.CheckIn)
+
+	// Correcting the check-in of an open session keeps its breaks, the one in progress too,
+	// but not a check-in after one of them started
+	session, _, err = ApplyPunch(nil, punch("o1", PunchCheckIn, at(9, 30), PunchSourceManual, at(9, 30)), config)
+	assert.NoError(t, err)
+	open := []*WorkSession{session}
+	for _, p := range []*Punch{
+		punch("o2", PunchBreakStart, at(10, 30), PunchSourceManual, at(10, 30)),
+		punch("o3", PunchBreakEnd, at(11, 0), PunchSourceManual, at(11, 0)),
+		punch("o4", PunchBreakStart, at(12, 0), PunchSourceManual, at(12, 0)),
+	} {
+		_, _, err = ApplyPunch(open, p, config)
+		assert.NoError(t, err)
+	}
+	session, previous, err = ApplyPunch(open, punch("o5", PunchCheckIn, at(9, 0), PunchSourceManual, at(12, 30)), config)
+	assert.NoError(t, err)
+	assert.Equal(t, SessionOnBreak, previous)
+	assert.Equal(t, at(9, 0), session.CheckIn)
+	assert.Len(t, session.Breaks, 2)
+	assert.Equal(t, SessionOnBreak, session.State())
+	_, _, err = ApplyPunch(open, punch("o6", PunchCheckIn, at(11, 0), PunchSourceManual, at(12, 30)), config)
+	assert.ErrorIs(t, err, ErrCheckInAfterBreak)
+	assert.Equal(t, at(9, 0), open[0].CheckIn)
+
+	// Voiding a session replays as well
+	sessions, skipped = DeriveSessions(append(punches[:5:5], &Punch{ID: "v1", Kind: PunchVoid, SessionID: "p1", At: at(18, 0), Source: PunchSourceManual, RecordedAt: at(18, 0)}), config)
+	assert.Empty(t, skipped)
+	assert.Equal(t, SessionVoid, sessions[0].State())
+}
+
+func TestSourcePrecedence(t *testing.T) {
+	config := &WorkConfig{DefaultWorkHours: 480}
+	day := time.Date(2025, 10, 13, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	punch := func(id string, kind PunchKind, when time.Time, source PunchSource) *Punch {
+		return &Punch{ID: id, Kind: kind, At: when, Source: source, RecordedAt: at(23, 0)}
+	}
+
+	assert.NoError(t, DefaultSourcePrecedence().Validate())
+	assert.Error(t, SourcePrecedence{PunchSourceManual, PunchSourceWebhook}.Validate())
+	assert.Error(t, SourcePrecedence{PunchSourceManual, PunchSourceManual, PunchSourceHRAutoFetch, PunchSourceSchedulerSync}.Validate())
+	assert.True(t, SourcePrecedence(nil).Outranks(PunchSourceManual, PunchSourceHRAutoFetch))
+	assert.False(t, SourcePrecedence(nil).Outranks(PunchSourceManual, PunchSourceManual))
+
+	// The user's check-in and check-out are kept over the HR system's by default
+	var sessions []*WorkSession
+	session, _, err := ApplyPunch(sessions, punch("p1", PunchCheckIn, at(9, 0), PunchSourceManual), config)
+	assert.NoError(t, err)
+	sessions = append(sessions, session)
+	_, _, err = ApplyPunch(sessions, punch("p2", PunchCheckOut, at(18, 0), PunchSourceManual), config)
+	assert.NoError(t, err)
+	assert.Equal(t, PunchSourceManual, session.CheckOutSource)
+
+	var outranked *OutrankedError
+	_, _, err = ApplyPunch(sessions, punch("p3", PunchCheckIn, at(9, 20), PunchSourceHRAutoFetch), config)
+	assert.ErrorIs(t, err, ErrOutranked)
+	assert.ErrorAs(t, err, &outranked)
+	assert.Equal(t, PunchSourceManual, outranked.KeptBy)
+	_, _, err = ApplyPunch(sessions, punch("p4", PunchCheckOut, at(18, 30), PunchSourceSchedulerSync), config)
+	assert.ErrorIs(t, err, ErrOutranked)
+	assert.Equal(t, at(9, 0), session.CheckIn)
+	assert.Equal(t, at(18, 0), *session.CheckOut)
+
+	// Ranking the HR system first lets it correct both
+	config.SourcePrecedence = SourcePrecedence{PunchSourceHRAutoFetch, PunchSourceSchedulerSync, PunchSourceManual, PunchSourceWebhook}
+	_, _, err = ApplyPunch(sessions, punch("p5", PunchCheckIn, at(9, 20), PunchSourceHRAutoFetch), config)
+	assert.NoError(t, err)
+	_, _, err = ApplyPunch(sessions, punch("p6", PunchCheckOut, at(18, 30), PunchSourceSchedulerSync), config)
+	assert.NoError(t, err)
+	assert.Equal(t, at(9, 20), session.CheckIn)
+	assert.Equal(t, PunchSourceHRAutoFetch, session.CheckInSource)
+	assert.Equal(t, SessionAutoClosed, session.State())
+	assert.Equal(t, PunchSourceSchedulerSync, session.CheckOutSource)
+	_, _, err = ApplyPunch(sessions, punch("p7", PunchCheckOut, at(18, 0), PunchSourceManual), config)
+	assert.ErrorIs(t, err, ErrOutranked)
+
+	// A re-check-in may not drop a check-out kept by a higher ranked source, the session stays as it was
+	checkOut := at(18, 0)
+	synced := &WorkSession{ID: "s2", Date: "2025-10-13", CheckIn: at(9, 0), CheckInSource: PunchSourceManual,
+		CheckOut: &checkOut, CheckOutSource: PunchSourceSchedulerSync, AutoClosed: true, WorkHours: 480}
+	_, _, err = ApplyPunch([]*WorkSession{synced}, punch("p8", PunchCheckIn, at(9, 10), PunchSourceManual), config)
+	assert.ErrorAs(t, err, &outranked)
+	assert.Equal(t, PunchSourceSchedulerSync, outranked.KeptBy)
+	assert.Equal(t, at(9, 0), synced.CheckIn)
+	assert.Equal(t, SessionAutoClosed, synced.State())
+}
+
+func TestFindConflicts(t *testing.T) {
+	config := &WorkConfig{DefaultWorkHours: 480}
+	day := time.Date(2025, 10, 13, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	checkOut := at(18, 0)
+	sessions := []*WorkSession{{
+		ID: "s1", Date: "2025-10-13", CheckIn: at(9, 0), CheckInSource: PunchSourceManual,
+		CheckOut: &checkOut, CheckOutSource: PunchSourceManual,
+	}}
+	punches := []*Punch{
+		{ID: "p1", Kind: PunchCheckIn, At: at(9, 20), Source: PunchSourceHRAutoFetch, RecordedAt: at(9, 30)},
+		// The latest HR check-out agrees with the local one at minute precision
+		{ID: "p2", Kind: PunchCheckOut, At: at(18, 40), Source: PunchSourceSchedulerSync, RecordedAt: at(19, 0)},
+		{ID: "p3", Kind: PunchCheckOut, At: at(18, 0).Add(30 * time.Second), Source: PunchSourceSchedulerSync, RecordedAt: at(20, 0)},
+		// A day the HR system knows of and the app does not
+		{ID: "p4", Kind: PunchCheckIn, At: at(33, 0), Source: PunchSourceHRAutoFetch, RecordedAt: at(33, 0)},
+		{ID: "p5", Kind: PunchCheckOut, At: at(18, 0), Source: PunchSourceManual, RecordedAt: at(18, 0)},
+	}
+
+	conflicts := FindConflicts(sessions, punches, config)
+	assert.Len(t, conflicts, 2)
+	assert.Equal(t, "2025-10-13", conflicts[0].Date)
+	assert.Equal(t, PunchCheckIn, conflicts[0].Kind)
+	assert.Equal(t, 20, conflicts[0].DiffMinutes())
+	assert.Equal(t, PunchSourceManual, conflicts[0].KeptSource(config.SourcePrecedence))
+
+	assert.Equal(t, "2025-10-14", conflicts[1].Date)
+	assert.Nil(t, conflicts[1].Local)
+	assert.Equal(t, PunchSourceHRAutoFetch, conflicts[1].KeptSource(config.SourcePrecedence))
+}
+
+// monthStats calculates the stats of a month's sessions and absences under a config, as the usecase does
+func monthStats(sessions []*WorkSession, absences []*Absence, yearMonth string, config *WorkConfig) *MonthlyStats {
+	days := BuildWorkDays(sessions, absences, config)
+	return CalculateStats(days, absences, yearMonth, config.CountWorkdays(yearMonth), config.CoreHours, config.OvertimePolicy)
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Limits of the context stored with a session
+const (
+	MaxNoteLength = 10000
+	MaxTagLength  = 32
+	MaxTags       = 10
+)
+
+// NormalizeTags trims and lowercases tags, turns inner spaces into dashes and drops empty and duplicate ones
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		if len(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("a session can have at most %d tags", MaxTags)
+	}
+	return normalized, nil
+}
+
+// SetNote replaces the note and tags of the session
+func (s *WorkSession) SetNote(note string, tags []string) error {
+	if len(note) > MaxNoteLength {
+		return fmt.Errorf("note is longer than %d characters", MaxNoteLength)
+	}
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	s.Note = note
+	s.Tags = normalized
+	return nil
+}
+
+// HasTag returns true if the session carries the tag, compared the way tags are stored
+func (s *WorkSession) HasTag(tag string) bool {
+	normalized, err := NormalizeTags([]string{tag})
+	if err != nil || len(normalized) == 0 {
+		return false
+	}
+	return slices.Contains(s.Tags, normalized[0])
+}
+
+// SessionFilter selects sessions by their context and their day's overtime, zero values match everything
+type SessionFilter struct {
+	From               string // YYYY-MM-DD, inclusive
+	To                 string // YYYY-MM-DD, inclusive
+	Tag                string
+	Text               string // case-insensitive, matched against the note and the tags
+	MinOvertimeMinutes int    // minimum overtime of the session's day, 0 matches every day
+}
+
+// Validate checks the filter's date range
+func (f SessionFilter) Validate() error {
+	for _, date := range []string{f.From, f.To} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if f.From > f.To {
+		return fmt.Errorf("from %s is after to %s", f.From, f.To)
+	}
+	if f.MinOvertimeMinutes < 0 {
+		return fmt.Errorf("minimum overtime cannot be negative")
+	}
+	return nil
+}
+
+// Matches returns true if the session, whose day has the given overtime, passes the filter
+func (f SessionFilter) Matches(s *WorkSession, dayOvertimeMinutes int) bool {
+	if s.Date < f.From || s.Date > f.To {
+		return false
+	}
+	if f.Tag != "" && !s.HasTag(f.Tag) {
+		return false
+	}
+	if f.Text != "" {
+		text := strings.ToLower(f.Text)
+		if !strings.Contains(strings.ToLower(s.Note), text) &&
+			!slices.ContainsFunc(s.Tags, func(tag string) bool { return strings.Contains(tag, text) }) {
+			return false
+		}
+	}
+	return f.MinOvertimeMinutes == 0 || dayOvertimeMinutes >= f.MinOvertimeMinutes
+}
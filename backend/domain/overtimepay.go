@@ -0,0 +1,121 @@
+package domain
+
+import "math"
+
+// DayType classifies a date in the official calendar
+type DayType string
+
+const (
+	DayTypeWorkday DayType = "workday"  // a regular or make-up workday
+	DayTypeRestDay DayType = "rest_day" // a weekend or other rest day
+	DayTypeHoliday DayType = "holiday"  // a statutory public holiday
+)
+
+// Overtime pay multipliers of the Chinese labor law
+const (
+	WorkdayOvertimeMultiplier = 1.5
+	RestDayOvertimeMultiplier = 2.0
+	HolidayOvertimeMultiplier = 3.0
+)
+
+// Multiplier returns the overtime pay multiplier of the day type
+func (t DayType) Multiplier() float64 {
+	switch t {
+	case DayTypeRestDay:
+		return RestDayOvertimeMultiplier
+	case DayTypeHoliday:
+		return HolidayOvertimeMultiplier
+	default:
+		return WorkdayOvertimeMultiplier
+	}
+}
+
+// DayOvertimePay represents the paid overtime of a single day
+type DayOvertimePay struct {
+	Date            string
+	DayType         DayType
+	OvertimeMinutes int
+	Pay             float64
+}
+
+// OvertimePay represents a month's overtime split into the statutory pay buckets
+type OvertimePay struct {
+	YearMonth      string // YYYY-MM format
+	HourlyRate     float64
+	WorkdayMinutes int
+	RestDayMinutes int
+	HolidayMinutes int
+	WorkdayPay     float64
+	RestDayPay     float64
+	HolidayPay     float64
+	TotalPay       float64
+	Days           []DayOvertimePay // checked-out days with paid overtime, ordered by date
+}
+
+// PaidOvertimeMinutes returns the overtime of a checked-out day that is paid at its day type's rate
+// Every minute worked on a rest day or holiday counts, a workday only counts its positive overtime
+func PaidOvertimeMinutes(day *WorkDay, dayType DayType, policy OvertimePolicy) int {
+	if !day.HasCheckedOut() {
+		return 0
+	}
+	if dayType != DayTypeWorkday {
+		return day.CalculateActualWorkMinutes()
+	}
+	overtime := day.CalculateOvertime(policy)
+	if overtime < 0 {
+		return 0
+	}
+	return overtime
+}
+
+// CalculateOvertimePay classifies each day's overtime by its day type and prices it at the hourly rate
+// Days missing from dayTypes are treated as workdays
+func CalculateOvertimePay(days []*WorkDay, dayTypes map[string]DayType, yearMonth string, policy OvertimePolicy, hourlyRate float64) *OvertimePay {
+	pay := &OvertimePay{
+		YearMonth:  yearMonth,
+		HourlyRate: hourlyRate,
+		Days:       []DayOvertimePay{},
+	}
+
+	for _, day := range days {
+		dayType, ok := dayTypes[day.Date]
+		if !ok {
+			dayType = DayTypeWorkday
+		}
+		minutes := PaidOvertimeMinutes(day, dayType, policy)
+		if minutes == 0 {
+			continue
+		}
+
+		amount := roundCents(float64(minutes) / MinutesPerHour * hourlyRate * dayType.Multiplier())
+		switch dayType {
+		case DayTypeRestDay:
+			pay.RestDayMinutes += minutes
+			pay.RestDayPay += amount
+		case DayTypeHoliday:
+			pay.HolidayMinutes += minutes
+			pay.HolidayPay += amount
+		default:
+			pay.WorkdayMinutes += minutes
+			pay.WorkdayPay += amount
+		}
+		pay.TotalPay += amount
+		pay.Days = append(pay.Days, DayOvertimePay{
+			Date:            day.Date,
+			DayType:         dayType,
+			OvertimeMinutes: minutes,
+			Pay:             amount,
+		})
+	}
+
+	pay.WorkdayPay = roundCents(pay.WorkdayPay)
+	pay.RestDayPay = roundCents(pay.RestDayPay)
+	pay.HolidayPay = roundCents(pay.HolidayPay)
+	pay.TotalPay = roundCents(pay.TotalPay)
+	return pay
+}
+
+// roundCents rounds an amount of money to two decimals
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrOutranked is returned when a punch would replace a session time set by a source that ranks higher
+var ErrOutranked = errors.New("session time was set by a higher ranked source")
+
+// OutrankedError is returned when a punch cannot replace a session time because of the source precedence
+type OutrankedError struct {
+	Kind    PunchKind
+	Source  PunchSource // the source of the rejected punch
+	KeptBy  PunchSource // the source of the time that is kept
+	KeptAt  time.Time
+	Session string
+}
+
+func (e *OutrankedError) Error() string {
+	return fmt.Sprintf("%s from %s cannot replace the %s at %s set by %s",
+		e.Kind, e.Source, e.Kind, e.KeptAt.Format(time.RFC3339), e.KeptBy)
+}
+
+// Is makes every outranked error match ErrOutranked
+func (e *OutrankedError) Is(target error) bool {
+	return target == ErrOutranked
+}
+
+// SourcePrecedence orders the punch sources from the highest ranked to the lowest
+// A punch may replace a check-in or check-out set by a source of the same or a lower rank
+type SourcePrecedence []PunchSource
+
+// DefaultSourcePrecedence returns the policy where the user's own corrections always win over the HR system
+func DefaultSourcePrecedence() SourcePrecedence {
+	return SourcePrecedence{PunchSourceManual, PunchSourceWebhook, PunchSourceHRAutoFetch, PunchSourceSchedulerSync}
+}
+
+// Validate checks that the policy ranks every known source exactly once
+func (p SourcePrecedence) Validate() error {
+	known := DefaultSourcePrecedence()
+	if len(p) != len(known) {
+		return fmt.Errorf("source precedence must rank all of %v", known)
+	}
+	seen := make(map[PunchSource]bool, len(p))
+	for _, source := range p {
+		if rankOf(known, source) == len(known) {
+			return fmt.Errorf("unknown punch source %q", source)
+		}
+		if seen[source] {
+			return fmt.Errorf("punch source %q is ranked twice", source)
+		}
+		seen[source] = true
+	}
+	return nil
+}
+
+// Outranks returns true if a time set by source a may not be replaced by source b
+// An empty policy uses the default one, unknown sources rank lowest
+func (p SourcePrecedence) Outranks(a, b PunchSource) bool {
+	order := p
+	if len(order) == 0 {
+		order = DefaultSourcePrecedence()
+	}
+	return rankOf(order, a) < rankOf(order, b)
+}
+
+// rankOf returns the position of a source in the order, or the length of the order if it is not ranked
+func rankOf(order []PunchSource, source PunchSource) int {
+	for i, ranked := range order {
+		if ranked == source {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// Conflict is a day where a session time differs from the latest value the HR system reported
+type Conflict struct {
+	Date        string
+	Kind        PunchKind  // check_in or check_out
+	Local       *time.Time // nil if the day has no such time yet
+	LocalSource PunchSource
+	HR          time.Time
+	HRSource    PunchSource
+}
+
+// DiffMinutes returns how many minutes the HR time is after the local one, 0 if there is no local time
+func (c Conflict) DiffMinutes() int {
+	if c.Local == nil {
+		return 0
+	}
+	return int(c.HR.Truncate(time.Minute).Sub(c.Local.Truncate(time.Minute)).Minutes())
+}
+
+// KeptSource returns the source whose time the precedence policy keeps
+func (c Conflict) KeptSource(precedence SourcePrecedence) PunchSource {
+	if c.Local == nil || precedence.Outranks(c.HRSource, c.LocalSource) {
+		return c.HRSource
+	}
+	return c.LocalSource
+}
+
+// FindConflicts compares the first check-in and last check-out of each day with the latest ones the HR system reported
+// and returns the days that disagree at minute precision, ordered by date with check-ins first
+func FindConflicts(sessions []*WorkSession, punches []*Punch, config *WorkConfig) []Conflict {
+	// The latest HR value of each day and kind
+	latest := make(map[string]map[PunchKind]*Punch)
+	for _, p := range punches {
+		var kind PunchKind
+		switch {
+		case p.Kind == PunchCheckIn && p.Source == PunchSourceHRAutoFetch:
+			kind = PunchCheckIn
+		case p.Kind == PunchCheckOut && p.Source == PunchSourceSchedulerSync:
+			kind = PunchCheckOut
+		default:
+			continue
+		}
+		date := config.WorkDate(p.At)
+		if latest[date] == nil {
+			latest[date] = make(map[PunchKind]*Punch)
+		}
+		if current := latest[date][kind]; current == nil || !p.RecordedAt.Before(current.RecordedAt) {
+			latest[date][kind] = p
+		}
+	}
+
+	var conflicts []Conflict
+	for date, byKind := range latest {
+		first := firstSessionOf(sessions, date)
+		last := latestSessionOf(sessions, date)
+		for _, kind := range []PunchKind{PunchCheckIn, PunchCheckOut} {
+			hr := byKind[kind]
+			if hr == nil {
+				continue
+			}
+			conflict := Conflict{Date: date, Kind: kind, HR: hr.At, HRSource: hr.Source}
+			switch {
+			case kind == PunchCheckIn && first != nil:
+				checkIn := first.CheckIn
+				conflict.Local, conflict.LocalSource = &checkIn, first.CheckInSource
+			case kind == PunchCheckOut && last != nil && last.HasCheckedOut():
+				conflict.Local, conflict.LocalSource = last.CheckOut, last.CheckOutSource
+			}
+			if conflict.Local != nil && conflict.Local.Truncate(time.Minute).Equal(hr.At.Truncate(time.Minute)) {
+				continue
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		if conflicts[i].Date != conflicts[j].Date {
+			return conflicts[i].Date < conflicts[j].Date
+		}
+		return conflicts[i].Kind == PunchCheckIn && conflicts[j].Kind == PunchCheckOut
+	})
+	return conflicts
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Errors returned by project and allocation operations
+var (
+	ErrProjectNotFound   = fmt.Errorf("project %w", ErrNotFound)
+	ErrProjectInUse      = errors.New("project has time allocations")
+	ErrSessionNotFound   = fmt.Errorf("session %w", ErrNotFound)
+	ErrInvalidAllocation = errors.New("invalid allocation")
+)
+
+// Project represents something worked on that hours are reported against
+type Project struct {
+	ID       string
+	Name     string
+	Code     string // cost center or ticket prefix, optional
+	Archived bool   // hidden when allocating, kept for reporting
+}
+
+// Validate checks the project's name
+func (p *Project) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("project name cannot be empty")
+	}
+	return nil
+}
+
+// Allocation assigns part of a session's worked time to a project and optionally one of its tasks
+// Either Minutes or Percent is set, a percentage is of the session's worked minutes
+type Allocation struct {
+	ID        string
+	SessionID string
+	ProjectID string
+	Task      string
+	Minutes   int
+	Percent   float64
+}
+
+// Validate checks that the allocation names a project and exactly one of minutes or a percentage
+func (a *Allocation) Validate() error {
+	if a.ProjectID == "" {
+		return fmt.Errorf("%w: it needs a project", ErrInvalidAllocation)
+	}
+	if a.Minutes < 0 || a.Percent < 0 || a.Percent > 100 {
+		return fmt.Errorf("%w: it must be positive minutes or a percentage up to 100", ErrInvalidAllocation)
+	}
+	if (a.Minutes > 0) == (a.Percent > 0) {
+		return fmt.Errorf("%w: it needs either minutes or a percentage", ErrInvalidAllocation)
+	}
+	return nil
+}
+
+// ResolveMinutes returns the minutes the allocation covers of a session with the given worked minutes
+// Percentages round down so that they never add up to more than was worked
+func (a *Allocation) ResolveMinutes(workedMinutes int) int {
+	if a.Percent > 0 {
+		return int(math.Floor(float64(workedMinutes) * a.Percent / 100))
+	}
+	return a.Minutes
+}
+
+// ValidateAllocations checks every allocation of a session and that together they do not exceed its worked minutes,
+// nor its percentages 100 while it is still open
+func ValidateAllocations(allocations []*Allocation, workedMinutes int) error {
+	total := 0
+	percent := 0.0
+	for _, allocation := range allocations {
+		if err := allocation.Validate(); err != nil {
+			return err
+		}
+		total += allocation.ResolveMinutes(workedMinutes)
+		percent += allocation.Percent
+	}
+	if total > workedMinutes {
+		return fmt.Errorf("%w: allocations of %d minutes exceed the %d minutes worked in the session",
+			ErrInvalidAllocation, total, workedMinutes)
+	}
+	if percent > 100 {
+		return fmt.Errorf("%w: allocations of %.0f%% exceed the session", ErrInvalidAllocation, percent)
+	}
+	return nil
+}
+
+// ClampAllocations fits the allocations of a session to its worked minutes after the session changed,
+// shrinking the minute allocations from the last one and dropping those left with none
+// Returns the allocations unchanged and false if they still fit
+func ClampAllocations(allocations []*Allocation, workedMinutes int) ([]*Allocation, bool) {
+	excess := -workedMinutes
+	for _, allocation := range allocations {
+		excess += allocation.ResolveMinutes(workedMinutes)
+	}
+	if excess <= 0 {
+		return allocations, false
+	}
+
+	clamped := make([]*Allocation, len(allocations))
+	for i := len(allocations) - 1; i >= 0; i-- {
+		allocation := *allocations[i]
+		if cut := min(excess, allocation.Minutes); cut > 0 {
+			allocation.Minutes -= cut
+			excess -= cut
+		}
+		clamped[i] = &allocation
+	}
+	return slices.DeleteFunc(clamped, func(a *Allocation) bool {
+		return a.Minutes == 0 && a.Percent == 0
+	}), true
+}
+
+// ProjectTotal is the time allocated to a project over a period, split by task
+type ProjectTotal struct {
+	ProjectID string
+	Minutes   int
+	Tasks     map[string]int // minutes per task, "" is time without a task
+}
+
+// SumAllocations totals the allocations of the sessions per project, ordered by most minutes first
+// The sessions carry the worked minutes that percentages resolve against, unallocated is the worked time left over
+func SumAllocations(sessions []*WorkSession, allocations []*Allocation) (totals []ProjectTotal, unallocated int) {
+	bySession := make(map[string][]*Allocation)
+	for _, allocation := range allocations {
+		bySession[allocation.SessionID] = append(bySession[allocation.SessionID], allocation)
+	}
+
+	byProject := make(map[string]*ProjectTotal)
+	for _, session := range sessions {
+		worked := session.CalculateActualWorkMinutes()
+		allocated := 0
+		for _, allocation := range bySession[session.ID] {
+			minutes := allocation.ResolveMinutes(worked)
+			total, ok := byProject[allocation.ProjectID]
+			if !ok {
+				total = &ProjectTotal{ProjectID: allocation.ProjectID, Tasks: make(map[string]int)}
+				byProject[allocation.ProjectID] = total
+			}
+			total.Minutes += minutes
+			total.Tasks[allocation.Task] += minutes
+			allocated += minutes
+		}
+		unallocated += max(worked-allocated, 0)
+	}
+
+	totals = make([]ProjectTotal, 0, len(byProject))
+	for _, total := range byProject {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Minutes != totals[j].Minutes {
+			return totals[i].Minutes > totals[j].Minutes
+		}
+		return totals[i].ProjectID < totals[j].ProjectID
+	})
+	return totals, unallocated
+}
@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PunchKind is the clock event a punch records
+type PunchKind string
+
+const (
+	PunchCheckIn    PunchKind = "check_in"
+	PunchCheckOut   PunchKind = "check_out"
+	PunchBreakStart PunchKind = "break_start"
+	PunchBreakEnd   PunchKind = "break_end"
+	PunchVoid       PunchKind = "void" // marks the punch's session as recorded by mistake
+)
+
+// ErrInvalidPunchKind is returned for a punch of an unknown kind, or one its source cannot record
+var ErrInvalidPunchKind = errors.New("invalid punch kind")
+
+// Validate checks that the kind is known
+func (k PunchKind) Validate() error {
+	switch k {
+	case PunchCheckIn, PunchCheckOut, PunchBreakStart, PunchBreakEnd, PunchVoid:
+		return nil
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidPunchKind, k)
+	}
+}
+
+// PunchSource is where a punch came from
+type PunchSource string
+
+const (
+	PunchSourceManual        PunchSource = "manual"         // the user, through the app
+	PunchSourceHRAutoFetch   PunchSource = "hr_auto_fetch"  // the first check-in of the day fetched from the HR system
+	PunchSourceSchedulerSync PunchSource = "scheduler_sync" // a check-out found by the scheduler in the HR system
+	PunchSourceWebhook       PunchSource = "webhook"        // an inbound webhook such as a door badge reader
+)
+
+// Punch is an immutable record of a clock event, sessions are derived from the punch log
+type Punch struct {
+	ID         string
+	Kind       PunchKind
+	At         time.Time // when the clock event happened
+	Source     PunchSource
+	SessionID  string    // the session a void punch is about, empty for other kinds
+	RecordedAt time.Time // when the punch was recorded, punches replay in this order
+}
+
+// ApplyPunch changes the session a punch is about, or starts one, following the same rules as the live clock
+// The sessions must include those of the punch's work date and the open one since EarliestOpenDate,
+// a new session takes the punch's ID
+// Returns the session and the state it was in before the punch, empty for a session the punch started,
+// or an OutrankedError if the punch would replace a time set by a higher ranked source
+func ApplyPunch(sessions []*WorkSession, p *Punch, config *WorkConfig) (*WorkSession, SessionState, error) {
+	date := config.WorkDate(p.At)
+
+	switch p.Kind {
+	case PunchCheckIn:
+		if err := ValidateCheckIn(p.At, p.RecordedAt); err != nil {
+			return nil, "", err
+		}
+		if p.Source == PunchSourceHRAutoFetch {
+			// The HR system only knows the first check-in of the day, it corrects that session
+			if first := firstSessionOf(sessions, date); first != nil {
+				previous := first.State()
+				if first.HasCheckedOut() && !p.At.Before(*first.CheckOut) {
+					return nil, "", ErrCheckOutBeforeCheckIn
+				}
+				if err := checkPrecedence(config, p, first, first.CheckInSource, first.CheckIn); err != nil {
+					return nil, "", err
+				}
+				first.CheckIn = p.At
+				first.CheckInSource = p.Source
+				first.WorkHours = config.ExpectedMinutes(date)
+				return first, previous, nil
+			}
+		} else if latest := latestSessionOf(sessions, date); latest != nil &&
+			(!latest.HasCheckedOut() || p.At.Before(*latest.CheckOut)) {
+			// Re-check-in: correct the latest session, a checked-out one only to a check-in within it
+			if latest.HasCheckedOut() && p.At.Before(latest.CheckIn) {
+				return nil, "", ErrCheckInBeforeSession
+			}
+			previous := latest.State()
+			if err := checkPrecedence(config, p, latest, latest.CheckInSource, latest.CheckIn); err != nil {
+				return nil, "", err
+			}
+			// Reopening drops the check-out, which its source may outrank the punch to keep
+			if latest.HasCheckedOut() {
+				if err := checkPrecedence(config, p, latest, latest.CheckOutSource, *latest.CheckOut); err != nil {
+					return nil, "", err
+				}
+			}
+			if err := latest.Reopen(p.At, p.RecordedAt); err != nil {
+				return nil, "", err
+			}
+			latest.CheckInSource = p.Source
+			latest.WorkHours = config.ExpectedMinutes(date)
+			return latest, previous, nil
+		}
+		// New check-in, or back after checking out for another session of a split shift
+		return &WorkSession{
+			ID:            p.ID,
+			Date:          date,
+			CheckIn:       p.At,
+			CheckInSource: p.Source,
+			WorkHours:     config.ExpectedMinutes(date),
+		}, "", nil
+
+	case PunchCheckOut:
+		session := currentSessionOf(sessions, date)
+		if session == nil {
+			return nil, "", fmt.Errorf("%w for %s", ErrNoCheckIn, date)
+		}
+		previous := session.State()
+		if session.HasCheckedOut() {
+			if err := checkPrecedence(config, p, session, session.CheckOutSource, *session.CheckOut); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := session.Close(p.At, p.RecordedAt, p.Source != PunchSourceManual); err != nil {
+			return nil, "", err
+		}
+		session.CheckOutSource = p.Source
+		return session, previous, nil
+
+	case PunchBreakStart, PunchBreakEnd:
+		session := currentSessionOf(sessions, date)
+		if session == nil {
+			return nil, "", fmt.Errorf("%w for %s", ErrNoCheckIn, date)
+		}
+		previous := session.State()
+		var err error
+		if p.Kind == PunchBreakStart {
+			err = session.StartBreak(p.ID, p.At)
+		} else {
+			err = session.EndBreak(p.At)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return session, previous, nil
+
+	case PunchVoid:
+		for _, session := range sessions {
+			if session.ID != p.SessionID {
+				continue
+			}
+			previous := session.State()
+			if err := session.Void(); err != nil {
+				return nil, "", err
+			}
+			return session, previous, nil
+		}
+		return nil, "", ErrSessionNotFound
+
+	default:
+		return nil, "", p.Kind.Validate()
+	}
+}
+
+// DeriveSessions replays the punch log in the order it was recorded and returns the resulting sessions
+// ordered by date and check-in, together with the punches that no longer apply under the current rules
+func DeriveSessions(punches []*Punch, config *WorkConfig) (sessions []*WorkSession, skipped []*Punch) {
+	ordered := make([]*Punch, len(punches))
+	copy(ordered, punches)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].RecordedAt.Before(ordered[j].RecordedAt)
+	})
+
+	for _, p := range ordered {
+		session, previous, err := ApplyPunch(sessions, p, config)
+		if err != nil {
+			skipped = append(skipped, p)
+			continue
+		}
+		if previous == "" {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].Date != sessions[j].Date {
+			return sessions[i].Date < sessions[j].Date
+		}
+		return sessions[i].CheckIn.Before(sessions[j].CheckIn)
+	})
+	return sessions, skipped
+}
+
+// checkPrecedence returns an OutrankedError if the punch may not replace a session time set by the kept source
+func checkPrecedence(config *WorkConfig, p *Punch, session *WorkSession, kept PunchSource, keptAt time.Time) error {
+	if config.SourcePrecedence.Outranks(kept, p.Source) {
+		return &OutrankedError{Kind: p.Kind, Source: p.Source, KeptBy: kept, KeptAt: keptAt, Session: session.ID}
+	}
+	return nil
+}
+
+// firstSessionOf returns the earliest session of the date that is not void
+func firstSessionOf(sessions []*WorkSession, date string) *WorkSession {
+	var first *WorkSession
+	for _, session := range sessions {
+		if session.Voided || session.Date != date {
+			continue
+		}
+		if first == nil || session.CheckIn.Before(first.CheckIn) {
+			first = session
+		}
+	}
+	return first
+}
+
+// latestSessionOf returns the latest session of the date that is not void
+func latestSessionOf(sessions []*WorkSession, date string) *WorkSession {
+	var latest *WorkSession
+	for _, session := range sessions {
+		if session.Voided || session.Date != date {
+			continue
+		}
+		if latest == nil || !session.CheckIn.Before(latest.CheckIn) {
+			latest = session
+		}
+	}
+	return latest
+}
+
+// currentSessionOf returns the latest open session of the date or the day before, or else the latest session
+// of the date, so that an overnight shift can be checked out and an earlier check-out corrected
+// An older open session was never checked out, it is left for the user to correct
+func currentSessionOf(sessions []*WorkSession, date string) *WorkSession {
+	since := dayBefore(date)
+	var open *WorkSession
+	for _, session := range sessions {
+		if session.Voided || session.HasCheckedOut() || session.Date < since {
+			continue
+		}
+		if open == nil || !session.CheckIn.Before(open.CheckIn) {
+			open = session
+		}
+	}
+	if open != nil {
+		return open
+	}
+	return latestSessionOf(sessions, date)
+}
@@ -1,12 +1,99 @@
 package domain
 
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is matched by every error about a record that does not exist, such as ErrSessionNotFound
+// A repository that failed to look returns a StorageError instead, callers must not take it for an empty result
+var ErrNotFound = errors.New("not found")
+
+// ErrConfigNotFound is returned when the work configuration has not been stored
+var ErrConfigNotFound = fmt.Errorf("config %w", ErrNotFound)
+
+// ErrAlreadyExists is returned when a record is added with the ID of one that exists, such as a punch recorded twice
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrStorage is matched by every StorageError
+var ErrStorage = errors.New("storage failure")
+
+// StorageError is returned when a repository failed to read or write, such as when the database is unreachable
+// or the context expired, as opposed to finding no record
+type StorageError struct {
+	Op  string // the repository method that failed
+	Err error
+}
+
+func (e *StorageError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Op, e.Err)
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes every storage error match ErrStorage
+func (e *StorageError) Is(target error) bool {
+	return target == ErrStorage
+}
+
 // Repository defines the interface for data persistence
 // This interface is defined in the domain layer, and implemented in the infrastructure layer
+// Every method but Close takes the caller's context, a canceled or expired one stops it with a StorageError
+// wrapping the context's error
+// Voided sessions are only returned by GetSession
 type Repository interface {
-	GetTodaySession(date string) *WorkSession
-	GetSessionsByMonth(yearMonth string) ([]*WorkSession, error)
-	SaveSession(session *WorkSession) error
-	GetConfig() (*WorkConfig, error)
-	SaveConfig(config *WorkConfig) error
+	// GetTodaySession returns the latest session of the date, or ErrSessionNotFound if there is none
+	GetTodaySession(ctx context.Context, date string) (*WorkSession, error)
+	// GetOpenSession returns the latest session without check-out dated on or after since (YYYY-MM-DD),
+	// or ErrSessionNotFound if there is none
+	GetOpenSession(ctx context.Context, since string) (*WorkSession, error)
+	// GetOpenSessionsBefore returns the sessions without check-out dated before the date ordered by date and check-in
+	GetOpenSessionsBefore(ctx context.Context, date string) ([]*WorkSession, error)
+	// GetSessionsByDate returns all sessions of the date ordered by check-in time
+	GetSessionsByDate(ctx context.Context, date string) ([]*WorkSession, error)
+	GetSessionsByMonth(ctx context.Context, yearMonth string) ([]*WorkSession, error)
+	// GetSessionsByDateRange returns the sessions between two dates (inclusive, empty means unbounded) ordered by check-in time
+	GetSessionsByDateRange(ctx context.Context, from, to string) ([]*WorkSession, error)
+	// GetSession returns a session by ID whatever its state, or ErrSessionNotFound
+	GetSession(ctx context.Context, id string) (*WorkSession, error)
+	SaveSession(ctx context.Context, session *WorkSession) error
+	// RecordPunch appends a punch to the log and saves the session it changed, together
+	// A nil session only records the punch, such as one the source precedence kept from changing a session
+	RecordPunch(ctx context.Context, punch *Punch, session *WorkSession) error
+	// GetPunches returns the punches between two dates (inclusive, empty means unbounded) in the order they were recorded
+	GetPunches(ctx context.Context, from, to string) ([]*Punch, error)
+	// ReplaceSessions replaces every session with the ones derived from the punch log,
+	// the notes and tags of sessions that remain are kept
+	ReplaceSessions(ctx context.Context, sessions []*WorkSession) error
+	// GetAbsence returns an absence by ID, or ErrAbsenceNotFound
+	GetAbsence(ctx context.Context, id string) (*Absence, error)
+	SaveAbsence(ctx context.Context, absence *Absence) error
+	DeleteAbsence(ctx context.Context, id string) error
+	GetAbsencesByDate(ctx context.Context, date string) ([]*Absence, error)
+	GetAbsencesByMonth(ctx context.Context, yearMonth string) ([]*Absence, error)
+	// GetAbsencesByDateRange returns the absences between two dates (inclusive) ordered by date
+	GetAbsencesByDateRange(ctx context.Context, from, to string) ([]*Absence, error)
+	SaveTimeBankEntry(ctx context.Context, entry *TimeBankEntry) error
+	DeleteTimeBankEntry(ctx context.Context, id string) error
+	// GetTimeBankEntries returns entries between two dates (inclusive, empty means unbounded) ordered by date
+	GetTimeBankEntries(ctx context.Context, from, to string) ([]*TimeBankEntry, error)
+	// SumTimeBankMinutes returns the balance of all entries dated before the date, empty means all entries
+	SumTimeBankMinutes(ctx context.Context, before string) (int, error)
+	SaveProject(ctx context.Context, project *Project) error
+	// DeleteProject deletes a project, or returns ErrProjectInUse while time is allocated to it
+	DeleteProject(ctx context.Context, id string) error
+	// GetProjects returns all projects ordered by name
+	GetProjects(ctx context.Context) ([]*Project, error)
+	// SaveAllocations replaces all allocations of a session
+	SaveAllocations(ctx context.Context, sessionID string, allocations []*Allocation) error
+	GetAllocationsBySession(ctx context.Context, sessionID string) ([]*Allocation, error)
+	// GetAllocationsByDateRange returns the allocations of sessions between two dates (inclusive)
+	GetAllocationsByDateRange(ctx context.Context, from, to string) ([]*Allocation, error)
+	// GetConfig returns the work configuration, or ErrConfigNotFound if none is stored
+	GetConfig(ctx context.Context) (*WorkConfig, error)
+	SaveConfig(ctx context.Context, config *WorkConfig) error
 	Close() error
 }
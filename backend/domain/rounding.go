@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RoundingDirection selects how a punch is moved onto the rounding interval
+type RoundingDirection string
+
+const (
+	RoundingNone    RoundingDirection = "none"    // the punch is kept as it is
+	RoundingUp      RoundingDirection = "up"      // to the next interval boundary
+	RoundingDown    RoundingDirection = "down"    // to the previous interval boundary
+	RoundingNearest RoundingDirection = "nearest" // to the closest interval boundary
+)
+
+// RoundingPolicy rounds punches the way payroll does, such as check-in up and check-out down to 15 minutes
+// A punch within the grace window of a boundary snaps to it whatever the direction
+type RoundingPolicy struct {
+	IntervalMinutes int               `json:"interval_minutes"` // 0 disables rounding
+	CheckIn         RoundingDirection `json:"check_in"`
+	CheckOut        RoundingDirection `json:"check_out"`
+	GraceMinutes    int               `json:"grace_minutes"`
+}
+
+// DefaultRoundingPolicy returns the policy that keeps raw punches
+func DefaultRoundingPolicy() RoundingPolicy {
+	return RoundingPolicy{CheckIn: RoundingNone, CheckOut: RoundingNone}
+}
+
+// Validate checks that the policy can be applied
+func (p RoundingPolicy) Validate() error {
+	if p.IntervalMinutes < 0 || p.IntervalMinutes > MinutesPerHour {
+		return fmt.Errorf("rounding interval must be between 0 and %d minutes", MinutesPerHour)
+	}
+	for _, direction := range []RoundingDirection{p.CheckIn, p.CheckOut} {
+		switch direction {
+		case RoundingNone, RoundingUp, RoundingDown, RoundingNearest:
+		default:
+			return fmt.Errorf("unknown rounding direction %q", direction)
+		}
+	}
+	if p.GraceMinutes < 0 || (p.IntervalMinutes > 0 && p.GraceMinutes*2 > p.IntervalMinutes) {
+		return fmt.Errorf("rounding grace window must be between 0 and half the interval")
+	}
+	return nil
+}
+
+// Enabled returns true if the policy changes any punch
+func (p RoundingPolicy) Enabled() bool {
+	return p.IntervalMinutes > 0
+}
+
+// Round moves a punch onto the interval in the given direction, counting intervals from local midnight
+func (p RoundingPolicy) Round(t time.Time, direction RoundingDirection) time.Time {
+	if !p.Enabled() || direction == RoundingNone || direction == "" {
+		return t
+	}
+
+	interval := time.Duration(p.IntervalMinutes) * time.Minute
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	floor := midnight.Add(offset.Truncate(interval))
+	if floor.Equal(t) {
+		return t
+	}
+	ceil := floor.Add(interval)
+
+	grace := time.Duration(p.GraceMinutes) * time.Minute
+	switch {
+	case t.Sub(floor) <= grace:
+		return floor
+	case ceil.Sub(t) <= grace:
+		return ceil
+	}
+
+	switch direction {
+	case RoundingUp:
+		return ceil
+	case RoundingDown:
+		return floor
+	default:
+		if t.Sub(floor) < ceil.Sub(t) {
+			return floor
+		}
+		return ceil
+	}
+}
+
+// Rounded returns a copy of the session with its punches rounded, the stored session keeps the raw punches
+func (s *WorkSession) Rounded(policy RoundingPolicy) *WorkSession {
+	if !policy.Enabled() {
+		return s
+	}
+
+	rounded := *s
+	rounded.CheckIn = policy.Round(s.CheckIn, policy.CheckIn)
+	if s.CheckOut != nil {
+		checkOut := policy.Round(*s.CheckOut, policy.CheckOut)
+		if checkOut.Before(rounded.CheckIn) {
+			checkOut = rounded.CheckIn
+		}
+		rounded.CheckOut = &checkOut
+	}
+	return &rounded
+}
+
+// Rounded returns a copy of the day with the punches of every session rounded
+func (d *WorkDay) Rounded(policy RoundingPolicy) *WorkDay {
+	if !policy.Enabled() {
+		return d
+	}
+
+	rounded := *d
+	rounded.Sessions = make([]*WorkSession, len(d.Sessions))
+	for i, session := range d.Sessions {
+		rounded.Sessions[i] = session.Rounded(policy)
+	}
+	return &rounded
+}
@@ -1,9 +1,18 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // AttendanceProvider defines the interface for fetching attendance data from external systems
 // This interface is defined in the domain layer, and implemented in the infrastructure layer
 type AttendanceProvider interface {
 	FetchAttendanceStatus(config *WorkConfig, date string) (checkedIn, checkedOut *time.Time, err error)
 }
+
+// HolidayProvider defines the interface for looking up the official calendar of a date,
+// implemented in the infrastructure layer. A lookup stops once the context is done
+type HolidayProvider interface {
+	GetDayType(ctx context.Context, date string) (DayType, error)
+}
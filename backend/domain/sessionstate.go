@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SessionState is where a session is in its lifecycle
+type SessionState string
+
+const (
+	SessionOpen       SessionState = "open"        // checked in, working
+	SessionOnBreak    SessionState = "on_break"    // checked in, a break is in progress
+	SessionClosed     SessionState = "closed"      // checked out by the user
+	SessionAutoClosed SessionState = "auto_closed" // checked out from the HR system's punches
+	SessionVoid       SessionState = "void"        // recorded by mistake, left out of every calculation
+)
+
+// MaxClockSkew is how far in the future a punch may be, to allow for clocks that are slightly ahead
+const MaxClockSkew = 5 * time.Minute
+
+// Errors returned when a session rule is violated
+var (
+	ErrInvalidTransition     = errors.New("invalid session state transition")
+	ErrNoCheckIn             = errors.New("no check-in found")
+	ErrCheckInInFuture       = errors.New("check-in cannot be in the future")
+	ErrCheckInBeforeSession  = errors.New("check-in cannot be before the check-in of a checked-out session")
+	ErrCheckInAfterBreak     = errors.New("check-in cannot be after a break started")
+	ErrCheckOutInFuture      = errors.New("check-out cannot be in the future")
+	ErrCheckOutBeforeCheckIn = errors.New("check-out cannot be before check-in")
+	ErrCheckOutBeforeBreak   = errors.New("check-out cannot be before a break started")
+)
+
+// TransitionError is returned when a session cannot move from its state to the requested one
+type TransitionError struct {
+	From SessionState
+	To   SessionState
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("session cannot go from %s to %s", e.From, e.To)
+}
+
+// Is makes every transition error match ErrInvalidTransition
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// sessionTransitions lists the states each state can move to
+// Checking in again reopens a session to correct its check-in, checking out again corrects its check-out,
+// whether a correction may replace the time is up to the source precedence
+var sessionTransitions = map[SessionState][]SessionState{
+	SessionOpen:       {SessionOpen, SessionOnBreak, SessionClosed, SessionAutoClosed, SessionVoid},
+	SessionOnBreak:    {SessionOpen, SessionClosed, SessionAutoClosed, SessionVoid},
+	SessionClosed:     {SessionOpen, SessionClosed, SessionAutoClosed, SessionVoid},
+	SessionAutoClosed: {SessionOpen, SessionClosed, SessionAutoClosed, SessionVoid},
+	SessionVoid:       {},
+}
+
+// CanTransitionTo returns true if a session in this state may move to the next one
+func (s SessionState) CanTransitionTo(next SessionState) bool {
+	for _, allowed := range sessionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns where the session is in its lifecycle
+func (s *WorkSession) State() SessionState {
+	switch {
+	case s.Voided:
+		return SessionVoid
+	case s.HasCheckedOut() && s.AutoClosed:
+		return SessionAutoClosed
+	case s.HasCheckedOut():
+		return SessionClosed
+	case s.IsOnBreak():
+		return SessionOnBreak
+	default:
+		return SessionOpen
+	}
+}
+
+// transition checks that the session may move to the next state
+func (s *WorkSession) transition(next SessionState) error {
+	if current := s.State(); !current.CanTransitionTo(next) {
+		return &TransitionError{From: current, To: next}
+	}
+	return nil
+}
+
+// ValidateCheckIn checks that a check-in time is not in the future
+func ValidateCheckIn(at, now time.Time) error {
+	if at.After(now.Add(MaxClockSkew)) {
+		return ErrCheckInInFuture
+	}
+	return nil
+}
+
+// Reopen checks the session in again at the given time
+// A checked-out session drops its check-out and breaks, an open one keeps its breaks, none of which may start before the new check-in
+func (s *WorkSession) Reopen(at, now time.Time) error {
+	if err := s.transition(SessionOpen); err != nil {
+		return err
+	}
+	if err := ValidateCheckIn(at, now); err != nil {
+		return err
+	}
+	if s.HasCheckedOut() {
+		s.Breaks = nil // Breaks belong to the check-out being dropped
+	}
+	for i := range s.Breaks {
+		if s.Breaks[i].Start.Before(at) {
+			return ErrCheckInAfterBreak
+		}
+	}
+	s.CheckIn = at
+	s.CheckOut = nil
+	s.CheckOutSource = ""
+	s.AutoClosed = false
+	return nil
+}
+
+// Close checks the session out at the given time, ending a break in progress or still running at that time
+// auto marks a check-out taken from the HR system rather than made by the user
+func (s *WorkSession) Close(at, now time.Time, auto bool) error {
+	next := SessionClosed
+	if auto {
+		next = SessionAutoClosed
+	}
+	if err := s.transition(next); err != nil {
+		return err
+	}
+	if at.After(now.Add(MaxClockSkew)) {
+		return ErrCheckOutInFuture
+	}
+	if at.Before(s.CheckIn) {
+		return ErrCheckOutBeforeCheckIn
+	}
+	for i := range s.Breaks {
+		if at.Before(s.Breaks[i].Start) {
+			return ErrCheckOutBeforeBreak
+		}
+	}
+	for i := range s.Breaks {
+		if b := &s.Breaks[i]; b.IsOpen() || b.End.After(at) {
+			b.End = &at
+		}
+	}
+	s.CheckOut = &at
+	s.AutoClosed = auto
+	return nil
+}
+
+// Void marks the session as recorded by mistake
+func (s *WorkSession) Void() error {
+	if err := s.transition(SessionVoid); err != nil {
+		return err
+	}
+	s.Voided = true
+	return nil
+}
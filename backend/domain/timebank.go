@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrTimeBankEntryNotFound is returned when a time bank entry does not exist
+var ErrTimeBankEntryNotFound = fmt.Errorf("time bank entry %w", ErrNotFound)
+
+// TimeBankEntryKind represents the origin of a time bank entry
+type TimeBankEntryKind string
+
+const (
+	TimeBankDaily      TimeBankEntryKind = "daily"      // a checked-out day's surplus or deficit
+	TimeBankAdjustment TimeBankEntryKind = "adjustment" // a manual correction, either sign
+	TimeBankCompOff    TimeBankEntryKind = "comp_off"   // compensatory time off taken, always a debit
+)
+
+// TimeBankEntry represents one credit or debit of the overtime time bank
+type TimeBankEntry struct {
+	ID        string
+	Date      string // YYYY-MM-DD format
+	Kind      TimeBankEntryKind
+	Minutes   int // positive credits the bank, negative debits it
+	Note      string
+	CreatedAt time.Time
+}
+
+// dailyTimeBankEntryPrefix prefixes the IDs of daily entries
+const dailyTimeBankEntryPrefix = "daily-"
+
+// DailyTimeBankEntryID returns the ID of a date's daily entry, so recording a day again replaces it
+func DailyTimeBankEntryID(date string) string {
+	return dailyTimeBankEntryPrefix + date
+}
+
+// IsDailyTimeBankEntryID returns true if the ID belongs to a daily entry
+func IsDailyTimeBankEntryID(id string) bool {
+	return strings.HasPrefix(id, dailyTimeBankEntryPrefix)
+}
+
+// NewDailyTimeBankEntry creates the entry of a checked-out day, crediting the work beyond its expected minutes
+// and debiting the work short of them. Returns nil while the day is still open
+func NewDailyTimeBankEntry(day *WorkDay, now time.Time) *TimeBankEntry {
+	if !day.HasCheckedOut() {
+		return nil
+	}
+	return &TimeBankEntry{
+		ID:        DailyTimeBankEntryID(day.Date),
+		Date:      day.Date,
+		Kind:      TimeBankDaily,
+		Minutes:   day.CalculateBalance(),
+		CreatedAt: now,
+	}
+}
+
+// Validate checks a manually added entry
+func (e *TimeBankEntry) Validate() error {
+	if _, err := time.Parse("2006-01-02", e.Date); err != nil {
+		return fmt.Errorf("invalid entry date %q, expected YYYY-MM-DD", e.Date)
+	}
+	switch e.Kind {
+	case TimeBankAdjustment:
+		if e.Minutes == 0 {
+			return fmt.Errorf("adjustment minutes cannot be zero")
+		}
+	case TimeBankCompOff:
+		if e.Minutes >= 0 {
+			return fmt.Errorf("comp-off must debit the time bank")
+		}
+	case TimeBankDaily:
+		return fmt.Errorf("daily entries are recorded on check-out")
+	default:
+		return fmt.Errorf("unknown time bank entry kind %q", e.Kind)
+	}
+	return nil
+}
+
+// TimeBankBalancePoint represents an entry together with the balance right after it
+type TimeBankBalancePoint struct {
+	Entry          *TimeBankEntry
+	BalanceMinutes int
+}
+
+// RunningBalance applies ordered entries to an opening balance and returns the balance after each
+func RunningBalance(openingMinutes int, entries []*TimeBankEntry) []TimeBankBalancePoint {
+	points := make([]TimeBankBalancePoint, 0, len(entries))
+	balance := openingMinutes
+	for _, entry := range entries {
+		balance += entry.Minutes
+		points = append(points, TimeBankBalancePoint{Entry: entry, BalanceMinutes: balance})
+	}
+	return points
+}
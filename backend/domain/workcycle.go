@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkCycle is a rotation of work weeks repeating from an anchor date,
+// such as alternating 5-day and 6-day weeks ("大小周")
+type WorkCycle struct {
+	AnchorDate string           `json:"anchor_date"` // YYYY-MM-DD, any day in the first week of the cycle
+	Weeks      [][]time.Weekday `json:"weeks"`       // working weekdays of each week, weeks start on Monday
+}
+
+// Validate checks that the cycle can be applied
+func (c *WorkCycle) Validate() error {
+	if _, err := time.Parse("2006-01-02", c.AnchorDate); err != nil {
+		return fmt.Errorf("invalid cycle anchor date %q, expected YYYY-MM-DD", c.AnchorDate)
+	}
+	if len(c.Weeks) == 0 {
+		return fmt.Errorf("work cycle must have at least one week")
+	}
+	for _, week := range c.Weeks {
+		for _, weekday := range week {
+			if weekday < time.Sunday || weekday > time.Saturday {
+				return fmt.Errorf("invalid weekday %d, expected 0 (Sunday) to 6 (Saturday)", weekday)
+			}
+		}
+	}
+	return nil
+}
+
+// WeekIndex returns which week of the cycle a date falls in
+func (c *WorkCycle) WeekIndex(date time.Time) int {
+	anchor, err := time.Parse("2006-01-02", c.AnchorDate)
+	if err != nil || len(c.Weeks) == 0 {
+		return 0
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(startOfWeek(day).Sub(startOfWeek(anchor)).Hours() / 24)
+	index := (days / 7) % len(c.Weeks)
+	if index < 0 {
+		index += len(c.Weeks)
+	}
+	return index
+}
+
+// IsWorkday returns true if the date is a working day of its cycle week
+func (c *WorkCycle) IsWorkday(date time.Time) bool {
+	if len(c.Weeks) == 0 {
+		return false
+	}
+	for _, weekday := range c.Weeks[c.WeekIndex(date)] {
+		if weekday == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfWeek returns the Monday of a date's week
+func startOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return date.AddDate(0, 0, -offset)
+}
@@ -1,23 +1,48 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
 )
 
 const (
 	HolidayAPIURL     = "http://api.haoshenqi.top/holiday/today"
+	HolidayDateAPIURL = "http://api.haoshenqi.top/holiday"
 	HolidayStatusWork = "工作"
 	HolidayStatusRest = "休息"
 )
 
+// Day status codes returned by the holiday API for a date
+const (
+	holidayDateStatusWorkday       = 0 // a regular workday
+	holidayDateStatusWeekend       = 1 // a regular weekend
+	holidayDateStatusMakeUpWorkday = 2 // a weekend made a workday to bridge a holiday
+	holidayDateStatusHoliday       = 3 // a statutory public holiday
+)
+
+// holidayDateStatus represents one day of the holiday API's date response
+type holidayDateStatus struct {
+	Date   string `json:"date"`
+	Status int    `json:"status"`
+}
+
 // HolidayAPIClient handles communication with the holiday API
 type HolidayAPIClient struct {
 	httpClient *http.Client
 	apiURL     string
+	dateAPIURL string
+
+	mu       sync.Mutex
+	dayTypes map[string]domain.DayType // past answers, the calendar of a date does not change
 }
 
 // NewHolidayAPIClient creates a new holiday API client
@@ -26,7 +51,9 @@ func NewHolidayAPIClient() *HolidayAPIClient {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		apiURL: HolidayAPIURL,
+		apiURL:     HolidayAPIURL,
+		dateAPIURL: HolidayDateAPIURL,
+		dayTypes:   make(map[string]domain.DayType),
 	}
 }
 
@@ -54,3 +81,59 @@ func (c *HolidayAPIClient) IsHoliday() (bool, error) {
 
 	return isHoliday, nil
 }
+
+// GetDayType looks up whether a date (YYYY-MM-DD) is a workday, rest day or statutory holiday
+func (c *HolidayAPIClient) GetDayType(ctx context.Context, date string) (domain.DayType, error) {
+	c.mu.Lock()
+	dayType, ok := c.dayTypes[date]
+	c.mu.Unlock()
+	if ok {
+		return dayType, nil
+	}
+
+	requestURL := c.dateAPIURL + "?date=" + url.QueryEscape(date)
+	slog.Info("[Holiday API] Checking day type", "url", requestURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("holiday API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("holiday API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var days []holidayDateStatus
+	if err := json.Unmarshal(bodyBytes, &days); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(days) == 0 || days[0].Date != date {
+		return "", fmt.Errorf("holiday API has no data for %s", date)
+	}
+
+	switch days[0].Status {
+	case holidayDateStatusWorkday, holidayDateStatusMakeUpWorkday:
+		dayType = domain.DayTypeWorkday
+	case holidayDateStatusWeekend:
+		dayType = domain.DayTypeRestDay
+	case holidayDateStatusHoliday:
+		dayType = domain.DayTypeHoliday
+	default:
+		return "", fmt.Errorf("unknown holiday API status %d for %s", days[0].Status, date)
+	}
+	slog.Info("[Holiday API] Day type", "date", date, "day_type", dayType)
+
+	c.mu.Lock()
+	c.dayTypes[date] = dayType
+	c.mu.Unlock()
+	return dayType, nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+)
+
+// TestGetDayType tests the mapping of the holiday API's date status to day types
+func TestGetDayType(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    string
+		body    string
+		want    domain.DayType
+		wantErr bool
+	}{
+		{name: "regular workday", date: "2025-10-09", body: `[{"date":"2025-10-09","status":0}]`, want: domain.DayTypeWorkday},
+		{name: "weekend", date: "2025-10-11", body: `[{"date":"2025-10-11","status":1}]`, want: domain.DayTypeRestDay},
+		{name: "make-up workday", date: "2025-09-28", body: `[{"date":"2025-09-28","status":2}]`, want: domain.DayTypeWorkday},
+		{name: "statutory holiday", date: "2025-10-01", body: `[{"date":"2025-10-01","status":3}]`, want: domain.DayTypeHoliday},
+		{name: "no data", date: "2025-10-01", body: `[]`, wantErr: true},
+		{name: "other date", date: "2025-10-01", body: `[{"date":"2025-10-02","status":3}]`, wantErr: true},
+		{name: "invalid body", date: "2025-10-01", body: `休息`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			c := NewHolidayAPIClient()
+			c.dateAPIURL = server.URL
+
+			got, err := c.GetDayType(context.Background(), tt.date)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetDayType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetDayType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
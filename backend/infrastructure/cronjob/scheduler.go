@@ -1,7 +1,11 @@
 package cronjob
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -9,17 +13,34 @@ import (
 	"github.com/simon0-o/offline_me/backend/infrastructure/client"
 )
 
+// jobTimeout bounds the storage calls of a job, an unreachable database fails the job rather than hanging it
+const jobTimeout = 30 * time.Second
+
+// WorkRecord reads the recorded work that the jobs judge
+type WorkRecord interface {
+	// EvaluateCompliance evaluates the recorded work against the labor-law limits of a config
+	EvaluateCompliance(ctx context.Context, config *domain.WorkConfig, now time.Time) ([]domain.ComplianceIssue, error)
+	// GetWorkDay loads the sessions and absences of a date as a work day
+	GetWorkDay(ctx context.Context, date string, config *domain.WorkConfig) (*domain.WorkDay, error)
+}
+
 // Scheduler handles scheduled tasks like reminders
 type Scheduler struct {
 	cron               *cron.Cron
 	store              domain.Repository
+	events             domain.EventPublisher
+	record             WorkRecord
 	attendanceProvider domain.AttendanceProvider
 	holidayClient      *client.HolidayAPIClient
 	webhookClient      *client.WebhookClient
+
+	mu             sync.Mutex
+	coreHoursEntry cron.EntryID // check-in reminder before core hours, 0 while not scheduled
 }
 
-// NewScheduler creates a new scheduler instance
-func NewScheduler(store domain.Repository) *Scheduler {
+// NewScheduler creates a new scheduler instance, punches found in the HR API are published to events
+// and the check-out reminder and compliance alert judge the work of the record
+func NewScheduler(store domain.Repository, events domain.EventPublisher, record WorkRecord) *Scheduler {
 	// Use Asia/Shanghai timezone for cron jobs
 	location, err := time.LoadLocation("Asia/Shanghai")
 	if err != nil {
@@ -30,6 +51,8 @@ func NewScheduler(store domain.Repository) *Scheduler {
 	return &Scheduler{
 		cron:               cron.New(cron.WithLocation(location)),
 		store:              store,
+		events:             events,
+		record:             record,
 		attendanceProvider: client.NewHRAPIClient(),
 		holidayClient:      client.NewHolidayAPIClient(),
 		webhookClient:      client.NewWebhookClient(),
@@ -47,6 +70,15 @@ func (s *Scheduler) Start() {
 		slog.Info("[Scheduler] Added check-in reminder: 9:55 AM daily")
 	}
 
+	// Task 1b: Check-in reminder before the core start (China time), rescheduled when the config changes
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+	if config, err := s.store.GetConfig(ctx); err != nil {
+		slog.Info("[Scheduler] Failed to get config for core hours check-in reminder", "error", err)
+	} else {
+		s.scheduleCoreHoursReminder(config)
+	}
+
 	// Task 2: Check-out reminder at 8:30 PM (China time)
 	if _, err := s.cron.AddFunc("30 20 * * *", s.checkOutReminder); err != nil {
 		slog.Info("[Scheduler] Failed to add check-out reminder (8:30 PM)", "error", err)
@@ -61,6 +93,13 @@ func (s *Scheduler) Start() {
 		slog.Info("[Scheduler] Added check-out reminder: 9:30 PM daily")
 	}
 
+	// Task 4: Compliance alert at 7:00 PM (China time)
+	if _, err := s.cron.AddFunc("0 19 * * *", s.complianceAlert); err != nil {
+		slog.Info("[Scheduler] Failed to add compliance alert job", "error", err)
+	} else {
+		slog.Info("[Scheduler] Added compliance alert: 7:00 PM daily")
+	}
+
 	s.cron.Start()
 	slog.Info("[Scheduler] Cronjob scheduler started successfully")
 }
@@ -72,34 +111,106 @@ func (s *Scheduler) Stop() {
 	slog.Info("[Scheduler] Cronjob scheduler stopped")
 }
 
-// checkInReminder sends a reminder to check in if not already done
+// checkInReminder sends a reminder to check in if not already done, unless it is sent before core hours instead
 func (s *Scheduler) checkInReminder() {
 	slog.Info("[CheckInReminder] Running task...")
 
-	config, err := s.store.GetConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	config, err := s.store.GetConfig(ctx)
+	if err != nil {
+		slog.Info("[CheckInReminder] Failed to get config", "error", err)
+		return
+	}
+	if _, ok := config.CheckInReminderTime(); ok {
+		slog.Info("[CheckInReminder] Reminder is relative to core hours, skipping")
+		return
+	}
+	s.remindCheckIn(config)
+}
+
+// OnConfigChanged moves the check-in reminder before core hours to the saved core start and lead
+func (s *Scheduler) OnConfigChanged(event domain.Event) {
+	changed, ok := event.(domain.ConfigChanged)
+	if !ok {
+		return
+	}
+	s.scheduleCoreHoursReminder(changed.Config)
+}
+
+// scheduleCoreHoursReminder replaces the check-in reminder before core hours with one at the config's reminder time,
+// or removes it when the fixed reminder time is used
+func (s *Scheduler) scheduleCoreHoursReminder(config *domain.WorkConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.coreHoursEntry != 0 {
+		s.cron.Remove(s.coreHoursEntry)
+		s.coreHoursEntry = 0
+	}
+	at, ok := config.CheckInReminderTime()
+	if !ok {
+		return
+	}
+	reminder, _ := time.Parse("15:04", at)
+
+	spec := fmt.Sprintf("%d %d * * *", reminder.Minute(), reminder.Hour())
+	id, err := s.cron.AddFunc(spec, s.coreHoursCheckInReminder)
+	if err != nil {
+		slog.Info("[Scheduler] Failed to add core hours check-in reminder job", "error", err)
+		return
+	}
+	s.coreHoursEntry = id
+	slog.Info("[Scheduler] Added check-in reminder: before core hours start", "at", at)
+}
+
+// coreHoursCheckInReminder sends the check-in reminder when the configured lead before the core start is reached
+func (s *Scheduler) coreHoursCheckInReminder() {
+	slog.Info("[CheckInReminder] Running core hours task...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	config, err := s.store.GetConfig(ctx)
 	if err != nil {
 		slog.Info("[CheckInReminder] Failed to get config", "error", err)
 		return
 	}
+	if _, ok := config.CheckInReminderTime(); !ok {
+		slog.Info("[CheckInReminder] Reminder is no longer relative to core hours, skipping")
+		return
+	}
+	s.remindCheckIn(config)
+}
+
+// remindCheckIn sends the check-in reminder unless today is off or already checked in
+func (s *Scheduler) remindCheckIn(config *domain.WorkConfig) {
 	if config.CheckInWebhookURL == "" {
 		slog.Info("[CheckInReminder] Webhook URL not configured, skipping")
 		return
 	}
 
-	// Step 1: Check if today is a holiday
-	if s.isHolidayToday() {
+	// Step 1: Check if today is a holiday, a weekend the work cycle works on is not
+	today := config.WorkDate(time.Now())
+	if !config.IsWeekendWorkday(today) && s.isHolidayToday() {
 		slog.Info("[CheckInReminder] Today is a holiday, skipping")
 		return
 	}
 
-	// Step 2: Check if already checked in via HR API
-	today := time.Now().Format("2006-01-02")
+	// Step 2: Skip days off in the weekly schedule and work cycle
+	if config.IsDayOff(today) {
+		slog.Info("[CheckInReminder] Today is a scheduled day off, skipping")
+		return
+	}
+
+	// Step 3: Check if already checked in via HR API
 	if s.hasCheckedIn(config, today) {
 		slog.Info("[CheckInReminder] Already checked in, skipping")
 		return
 	}
 
-	// Step 3: Send ntfy notification
+	// Step 4: Send ntfy notification
 	slog.Info("[CheckInReminder] Sending notification", "url", config.CheckInWebhookURL)
 	if err := s.webhookClient.Alarm(config.CheckInWebhookURL, "⏰ Time to check in! Don't forget to clock in for work."); err != nil {
 		slog.Info("[CheckInReminder] Failed to send notification", "error", err)
@@ -112,7 +223,10 @@ func (s *Scheduler) checkInReminder() {
 func (s *Scheduler) checkOutReminder() {
 	slog.Info("[CheckOutReminder] Running task...")
 
-	config, err := s.store.GetConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	config, err := s.store.GetConfig(ctx)
 	if err != nil {
 		slog.Info("[CheckOutReminder] Failed to get config", "error", err)
 		return
@@ -122,20 +236,26 @@ func (s *Scheduler) checkOutReminder() {
 		return
 	}
 
-	// Step 1: Check if today is a holiday
-	if s.isHolidayToday() {
+	// Step 1: Check if today is a holiday, a weekend the work cycle works on is not
+	today := config.WorkDate(time.Now())
+	if !config.IsWeekendWorkday(today) && s.isHolidayToday() {
 		slog.Info("[CheckOutReminder] Today is a holiday, skipping")
 		return
 	}
 
-	// Step 2: Check if already checked out via HR API
-	today := time.Now().Format("2006-01-02")
+	// Step 2: Skip days off in the weekly schedule and work cycle
+	if config.IsDayOff(today) {
+		slog.Info("[CheckOutReminder] Today is a scheduled day off, skipping")
+		return
+	}
+
+	// Step 3: Check if already checked out via HR API
 	if s.hasCheckedOut(config, today) {
 		slog.Info("[CheckOutReminder] Already checked out, skipping")
 		return
 	}
 
-	// Step 3: Send ntfy notification
+	// Step 4: Send ntfy notification
 	slog.Info("[CheckOutReminder] Sending notification", "url", config.CheckOutWebhookURL)
 	if err := s.webhookClient.Alarm(config.CheckOutWebhookURL, "✅ Time to check out! Remember to clock out from work."); err != nil {
 		slog.Info("[CheckOutReminder] Failed to send notification", "error", err)
@@ -144,6 +264,48 @@ func (s *Scheduler) checkOutReminder() {
 	}
 }
 
+// complianceAlert warns when labor-law limits are about to be, or have been, breached
+func (s *Scheduler) complianceAlert() {
+	slog.Info("[ComplianceAlert] Running task...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	config, err := s.store.GetConfig(ctx)
+	if err != nil {
+		slog.Info("[ComplianceAlert] Failed to get config", "error", err)
+		return
+	}
+	if config.CheckOutWebhookURL == "" {
+		slog.Info("[ComplianceAlert] Webhook URL not configured, skipping")
+		return
+	}
+
+	// Step 1: Evaluate the rules on the current month and week
+	issues, err := s.record.EvaluateCompliance(ctx, config, time.Now())
+	if err != nil {
+		slog.Info("[ComplianceAlert] Failed to evaluate compliance", "error", err)
+		return
+	}
+	if len(issues) == 0 {
+		slog.Info("[ComplianceAlert] All limits respected, skipping")
+		return
+	}
+
+	// Step 2: Send ntfy notification
+	lines := make([]string, 0, len(issues)+1)
+	lines = append(lines, "⚠️ Work time limits need attention:")
+	for _, issue := range issues {
+		lines = append(lines, "- "+issue.Message)
+	}
+	slog.Info("[ComplianceAlert] Sending notification", "url", config.CheckOutWebhookURL, "issues", len(issues))
+	if err := s.webhookClient.Alarm(config.CheckOutWebhookURL, strings.Join(lines, "\n")); err != nil {
+		slog.Info("[ComplianceAlert] Failed to send notification", "error", err)
+	} else {
+		slog.Info("[ComplianceAlert] Notification sent successfully")
+	}
+}
+
 // isHolidayToday checks if today is a holiday
 func (s *Scheduler) isHolidayToday() bool {
 	isHoliday, err := s.holidayClient.IsHoliday()
@@ -180,18 +342,32 @@ func (s *Scheduler) hasCheckedOut(config *domain.WorkConfig, date string) bool {
 		slog.Info("[Scheduler] Failed to check HR check-out status", "error", err)
 		return false // Assume not checked out on error
 	}
-	if checkedIn == nil || checkedOut == nil {
+	if checkedOut == nil {
 		return false
 	}
-	// update the work session with check-out time
-	session := s.store.GetTodaySession(date)
-	if session != nil && session.CheckOut == nil {
-		session.CheckOut = checkedOut
-		if err := s.store.SaveSession(session); err != nil {
-			slog.Info("[Scheduler] Failed to save session", "error", err)
+	// Let the work record pick up the check-out time
+	now := time.Now()
+	s.events.Publish(domain.AttendanceSynced{
+		At:       now,
+		Date:     date,
+		CheckIn:  checkedIn,
+		CheckOut: checkedOut,
+	})
+
+	// The expected check-out follows the whole day, its breaks, absences and earlier sessions
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+	day, err := s.record.GetWorkDay(ctx, date, config)
+	if err != nil {
+		slog.Info("[Scheduler] Failed to get work day", "date", date, "error", err)
+		return false // Assume not checked out on error
+	}
+	if len(day.Sessions) == 0 {
+		// Nothing recorded for the day, only the HR system's check-in is known
+		if checkedIn == nil {
+			return false
 		}
+		return checkedOut.After(config.CalculateExpectedCheckOut(*checkedIn))
 	}
-
-	expectedCheckOut := config.CalculateExpectedCheckOut(*checkedIn)
-	return checkedOut.After(expectedCheckOut)
+	return checkedOut.After(day.CalculateExpectedCheckOut(now))
 }
@@ -1,53 +1,68 @@
 package cronjob
 
 import (
+	"context"
+	"io"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/simon0-o/offline_me/backend/domain"
 	"github.com/simon0-o/offline_me/backend/infrastructure/client"
+	"github.com/simon0-o/offline_me/backend/infrastructure/persistence"
 	"github.com/stretchr/testify/assert"
 )
 
-// MockStore is a mock implementation of domain.Repository for testing
-type MockStore struct {
-	config *domain.WorkConfig
+// MockPublisher records the published events for testing
+type MockPublisher struct {
+	events []domain.Event
 }
 
-func (m *MockStore) GetConfig() (*domain.WorkConfig, error) {
-	if m.config == nil {
-		return &domain.WorkConfig{
-			ID:                 "default",
-			DefaultWorkHours:   480,
-			CheckInAPIURL:      "https://api.example.com/attendance",
-			AutoFetchEnabled:   true,
-			PAuth:              "test-p-auth",
-			PRToken:            "test-p-rtoken",
-			CheckInWebhookURL:  "https://webhook.example.com/checkin",
-			CheckOutWebhookURL: "https://webhook.example.com/checkout",
-		}, nil
-	}
-	return m.config, nil
+func (m *MockPublisher) Publish(event domain.Event) {
+	m.events = append(m.events, event)
 }
 
-func (m *MockStore) GetTodaySession(date string) *domain.WorkSession {
-	return nil
+// MockWorkRecord returns fixed compliance issues and work days for testing
+type MockWorkRecord struct {
+	issues []domain.ComplianceIssue
+	days   map[string]*domain.WorkDay
 }
 
-func (m *MockStore) GetSessionsByMonth(yearMonth string) ([]*domain.WorkSession, error) {
-	return nil, nil
+func (m *MockWorkRecord) EvaluateCompliance(ctx context.Context, config *domain.WorkConfig, now time.Time) ([]domain.ComplianceIssue, error) {
+	return m.issues, nil
 }
 
-func (m *MockStore) SaveSession(session *domain.WorkSession) error {
-	return nil
+func (m *MockWorkRecord) GetWorkDay(ctx context.Context, date string, config *domain.WorkConfig) (*domain.WorkDay, error) {
+	if day, ok := m.days[date]; ok {
+		return day, nil
+	}
+	return domain.NewWorkDay(date, nil), nil
 }
 
-func (m *MockStore) SaveConfig(config *domain.WorkConfig) error {
-	return nil
+// hrTime returns the time the HR API reports as a clock time on 2025-10-13
+func hrTime(hour, minute int) time.Time {
+	return time.Date(2025, 10, 13, hour, minute, 0, 0, time.UTC).In(time.Local).Add(-8 * time.Hour)
 }
 
-func (m *MockStore) Close() error {
-	return nil
+// newMemoryStore returns an in-memory store holding the config,
+// or one with the HR API and both webhooks configured if it is nil
+func newMemoryStore(t *testing.T, config *domain.WorkConfig) domain.Repository {
+	if config == nil {
+		config = &domain.WorkConfig{
+			DefaultWorkHours:   480,
+			CheckInAPIURL:      "https://api.example.com/attendance",
+			AutoFetchEnabled:   true,
+			PAuth:              "test-p-auth",
+			PRToken:            "test-p-rtoken",
+			CheckInWebhookURL:  "https://webhook.example.com/checkin",
+			CheckOutWebhookURL: "https://webhook.example.com/checkout",
+		}
+	}
+	config.ID = "default"
+	store := persistence.NewMemoryStore()
+	assert.NoError(t, store.SaveConfig(context.Background(), config))
+	return store
 }
 
 func TestIsHolidayToday_WorkingDay(t *testing.T) {
@@ -58,8 +73,8 @@ func TestIsHolidayToday_WorkingDay(t *testing.T) {
 	httpmock.RegisterResponder("GET", "http://api.haoshenqi.top/holiday/today",
 		httpmock.NewBytesResponder(200, []byte(client.HolidayStatusWork)))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	isHoliday := scheduler.isHolidayToday()
 
@@ -75,8 +90,8 @@ func TestIsHolidayToday_Holiday(t *testing.T) {
 	httpmock.RegisterResponder("GET", "http://api.haoshenqi.top/holiday/today",
 		httpmock.NewBytesResponder(200, []byte(client.HolidayStatusRest)))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	isHoliday := scheduler.isHolidayToday()
 
@@ -91,8 +106,8 @@ func TestIsHolidayToday_APIError(t *testing.T) {
 	httpmock.RegisterResponder("GET", "http://api.haoshenqi.top/holiday/today",
 		httpmock.NewStringResponder(500, "Internal Server Error"))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	isHoliday := scheduler.isHolidayToday()
 
@@ -120,9 +135,9 @@ func TestHasCheckedIn_Checked(t *testing.T) {
 			},
 		}))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
-	config, error := mockStore.GetConfig()
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
+	config, error := store.GetConfig(context.Background())
 	assert.NoError(t, error)
 
 	hasCheckedIn := scheduler.hasCheckedIn(config, "2025-10-13")
@@ -143,9 +158,9 @@ func TestHasCheckedIn_NotChecked(t *testing.T) {
 			Data:    []client.AttendanceRecord{},
 		}))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
-	config, err := mockStore.GetConfig()
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
+	config, err := store.GetConfig(context.Background())
 	assert.NoError(t, err)
 
 	hasCheckedIn := scheduler.hasCheckedIn(config, "2025-10-13")
@@ -154,14 +169,12 @@ func TestHasCheckedIn_NotChecked(t *testing.T) {
 }
 
 func TestHasCheckedIn_NoAPIConfig(t *testing.T) {
-	mockStore := &MockStore{
-		config: &domain.WorkConfig{
-			CheckInAPIURL:    "",
-			AutoFetchEnabled: false,
-		},
-	}
-	scheduler := NewScheduler(mockStore)
-	config, err := mockStore.GetConfig()
+	store := newMemoryStore(t, &domain.WorkConfig{
+		CheckInAPIURL:    "",
+		AutoFetchEnabled: false,
+	})
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
+	config, err := store.GetConfig(context.Background())
 	assert.NoError(t, err)
 
 	hasCheckedIn := scheduler.hasCheckedIn(config, "2025-10-13")
@@ -190,9 +203,16 @@ func TestHasCheckedOut_Checked(t *testing.T) {
 			},
 		}))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
-	config, err := mockStore.GetConfig()
+	// The work record picked up the check-out of a session checked in at 9:00
+	checkedOut := hrTime(18, 30)
+	record := &MockWorkRecord{days: map[string]*domain.WorkDay{
+		"2025-10-13": domain.NewWorkDay("2025-10-13", []*domain.WorkSession{
+			{ID: "s1", Date: "2025-10-13", CheckIn: hrTime(9, 0), CheckOut: &checkedOut, WorkHours: 480},
+		}),
+	}}
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, record)
+	config, err := store.GetConfig(context.Background())
 	assert.NoError(t, err)
 
 	hasCheckedOut := scheduler.hasCheckedOut(config, "2025-10-13")
@@ -200,6 +220,41 @@ func TestHasCheckedOut_Checked(t *testing.T) {
 	assert.True(t, hasCheckedOut)
 }
 
+func TestHasCheckedOut_WholeDay(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	checkInTime, checkOutTime := "09:00", "17:30"
+	httpmock.RegisterResponder("GET", "https://api.example.com/attendance?monthly=2025-10",
+		httpmock.NewJsonResponderOrPanic(200, client.HRAttendanceInfo{
+			Code:    "200",
+			Message: "Success",
+			Success: true,
+			Data: []client.AttendanceRecord{
+				{AttendanceDate: "2025-10-13", FirstClockInTime: &checkInTime, LastClockOutTime: &checkOutTime},
+			},
+		}))
+
+	store := newMemoryStore(t, nil)
+	config, err := store.GetConfig(context.Background())
+	assert.NoError(t, err)
+	checkedOut := hrTime(17, 30)
+	session := &domain.WorkSession{ID: "s1", Date: "2025-10-13", CheckIn: hrTime(9, 0), CheckOut: &checkedOut, WorkHours: 480}
+	day := domain.NewWorkDay("2025-10-13", []*domain.WorkSession{session})
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{days: map[string]*domain.WorkDay{"2025-10-13": day}})
+
+	// A two-hour lunch moves the expected check-out to 19:00
+	lunchEnd := hrTime(14, 0)
+	session.Breaks = []domain.Break{{ID: "b1", Start: hrTime(12, 0), End: &lunchEnd}}
+	assert.False(t, scheduler.hasCheckedOut(config, "2025-10-13"))
+
+	// A half-day leave halves the work hours
+	session.Breaks = nil
+	session.CheckIn = hrTime(13, 0)
+	day.Absences = []*domain.Absence{{ID: "a1", Date: "2025-10-13", Type: domain.AbsenceAnnualLeave, Amount: domain.AbsenceHalfDay}}
+	assert.True(t, scheduler.hasCheckedOut(config, "2025-10-13"))
+}
+
 func TestHasCheckedOut_NotChecked(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -213,23 +268,23 @@ func TestHasCheckedOut_NotChecked(t *testing.T) {
 			Data:    []client.AttendanceRecord{},
 		}))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
-	config, err := mockStore.GetConfig()
+	store := newMemoryStore(t, nil)
+	publisher := &MockPublisher{}
+	scheduler := NewScheduler(store, publisher, &MockWorkRecord{})
+	config, err := store.GetConfig(context.Background())
 	assert.NoError(t, err)
 
 	hasCheckedOut := scheduler.hasCheckedOut(config, "2025-10-13")
 
 	assert.False(t, hasCheckedOut)
+	assert.Empty(t, publisher.events)
 }
 
 func TestCheckInReminder_NoWebhookURL(t *testing.T) {
-	mockStore := &MockStore{
-		config: &domain.WorkConfig{
-			CheckInWebhookURL: "",
-		},
-	}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, &domain.WorkConfig{
+		CheckInWebhookURL: "",
+	})
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	// Should not panic and should skip execution
 	scheduler.checkInReminder()
@@ -244,8 +299,8 @@ func TestCheckInReminder_Holiday(t *testing.T) {
 	httpmock.RegisterResponder("GET", "http://api.haoshenqi.top/holiday/today",
 		httpmock.NewBytesResponder(200, []byte(client.HolidayStatusRest)))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	// Should skip webhook call on holiday
 	scheduler.checkInReminder()
@@ -262,12 +317,10 @@ func TestCheckInReminder_Holiday(t *testing.T) {
 }
 
 func TestCheckOutReminder_NoWebhookURL(t *testing.T) {
-	mockStore := &MockStore{
-		config: &domain.WorkConfig{
-			CheckOutWebhookURL: "",
-		},
-	}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, &domain.WorkConfig{
+		CheckOutWebhookURL: "",
+	})
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	// Should not panic and should skip execution
 	scheduler.checkOutReminder()
@@ -282,8 +335,8 @@ func TestCheckOutReminder_Holiday(t *testing.T) {
 	httpmock.RegisterResponder("GET", "http://api.haoshenqi.top/holiday/today",
 		httpmock.NewBytesResponder(200, []byte(client.HolidayStatusRest)))
 
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	// Should skip webhook call on holiday
 	scheduler.checkOutReminder()
@@ -300,28 +353,86 @@ func TestCheckOutReminder_Holiday(t *testing.T) {
 }
 
 func TestNewScheduler(t *testing.T) {
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	assert.NotNil(t, scheduler)
 	assert.NotNil(t, scheduler.cron)
-	assert.Equal(t, mockStore, scheduler.store)
+	assert.Equal(t, store, scheduler.store)
 	assert.NotNil(t, scheduler.attendanceProvider)
 	assert.NotNil(t, scheduler.holidayClient)
 	assert.NotNil(t, scheduler.webhookClient)
 }
 
 func TestScheduler_StartStop(t *testing.T) {
-	mockStore := &MockStore{}
-	scheduler := NewScheduler(mockStore)
+	store := newMemoryStore(t, nil)
+	scheduler := NewScheduler(store, &MockPublisher{}, &MockWorkRecord{})
 
 	// Start scheduler
 	scheduler.Start()
 
-	// Verify cron jobs were added (3 jobs: 1 check-in, 2 check-out)
+	// Verify cron jobs were added (4 jobs: 1 check-in, 2 check-out, 1 compliance)
 	entries := scheduler.cron.Entries()
-	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, 4, len(entries))
 
 	// Stop scheduler
 	scheduler.Stop()
 }
+
+func TestScheduler_CoreHoursReminder(t *testing.T) {
+	config := &domain.WorkConfig{
+		DefaultWorkHours:           480,
+		CoreHours:                  domain.CoreHours{Start: "10:00", End: "16:00"},
+		CheckInReminderLeadMinutes: 15,
+	}
+	scheduler := NewScheduler(newMemoryStore(t, config), &MockPublisher{}, &MockWorkRecord{})
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	// The reminder runs once a day at the lead before the core start, in the scheduler's time zone
+	assert.Equal(t, 5, len(scheduler.cron.Entries()))
+	next := scheduler.cron.Entry(scheduler.coreHoursEntry).Next
+	assert.Equal(t, 9, next.Hour())
+	assert.Equal(t, 45, next.Minute())
+	assert.Equal(t, "Asia/Shanghai", next.Location().String())
+
+	// A changed core start moves the reminder, dropping the core hours removes it
+	config.CoreHours.Start = "09:30"
+	scheduler.OnConfigChanged(domain.ConfigChanged{At: time.Now(), Config: config})
+	assert.Equal(t, 5, len(scheduler.cron.Entries()))
+	next = scheduler.cron.Entry(scheduler.coreHoursEntry).Next
+	assert.Equal(t, 9, next.Hour())
+	assert.Equal(t, 15, next.Minute())
+
+	config.CoreHours = domain.CoreHours{}
+	scheduler.OnConfigChanged(domain.ConfigChanged{At: time.Now(), Config: config})
+	assert.Equal(t, 4, len(scheduler.cron.Entries()))
+}
+
+func TestComplianceAlert(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var message string
+	httpmock.RegisterResponder("POST", "https://webhook.example.com/checkout",
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			message = string(body)
+			return httpmock.NewStringResponse(200, ""), nil
+		})
+
+	store := newMemoryStore(t, nil)
+	compliance := &MockWorkRecord{}
+	scheduler := NewScheduler(store, &MockPublisher{}, compliance)
+
+	// Nothing is sent while all limits are respected
+	scheduler.complianceAlert()
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+
+	compliance.issues = []domain.ComplianceIssue{
+		{Rule: domain.ComplianceMonthlyOvertime, Severity: domain.ComplianceWarning, Message: "Overtime this month is 33h00m of the 36h00m limit"},
+	}
+	scheduler.complianceAlert()
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	assert.Contains(t, message, "- Overtime this month is 33h00m of the 36h00m limit")
+}
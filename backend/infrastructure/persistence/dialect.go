@@ -0,0 +1,133 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect is the SQL database a store runs on
+// Queries are written once with ? placeholders, the dialect covers what differs between databases
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// DialectOf returns the dialect of a DSN, a postgres:// or postgresql:// URL or else the path of a SQLite file
+func DialectOf(dsn string) Dialect {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return DialectPostgres
+	}
+	return DialectSQLite
+}
+
+// rebind rewrites the ? placeholders of a query into the dialect's
+func (d Dialect) rebind(query string) string {
+	if d != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// onDates returns the condition and arguments that keep the rows whose timestamp column falls on a local date
+// between two dates (YYYY-MM-DD, inclusive, empty means unbounded)
+func (d Dialect) onDates(column, from, to string) (string, []any, error) {
+	var conditions []string
+	var args []any
+	if d == DialectSQLite {
+		// SQLite keeps timestamps as text in local time, starting with the date
+		if from != "" {
+			conditions = append(conditions, "substr("+column+", 1, 10) >= ?")
+			args = append(args, from)
+		}
+		if to != "" {
+			conditions = append(conditions, "substr("+column+", 1, 10) <= ?")
+			args = append(args, to)
+		}
+	} else {
+		if from != "" {
+			start, err := time.ParseInLocation("2006-01-02", from, time.Local)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, column+" >= ?")
+			args = append(args, start)
+		}
+		if to != "" {
+			end, err := time.ParseInLocation("2006-01-02", to, time.Local)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, column+" < ?")
+			args = append(args, end.AddDate(0, 0, 1))
+		}
+	}
+	if len(conditions) == 0 {
+		return "1 = 1", nil, nil
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// insertionOrder returns the column that orders the rows of a table the way they were inserted
+func (d Dialect) insertionOrder(alias string) string {
+	column := "rowid"
+	if d == DialectPostgres {
+		column = "seq"
+	}
+	if alias == "" {
+		return column
+	}
+	return alias + "." + column
+}
+
+// tableExistsQuery returns the query counting the tables of a name
+func (d Dialect) tableExistsQuery() string {
+	if d == DialectPostgres {
+		return `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1`
+	}
+	return `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+}
+
+// upsert returns the statement inserting a row, or updating every column of the row with the same id
+func upsert(table string, columns ...string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	updates := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column != "id" {
+			updates = append(updates, column+" = excluded."+column)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (id) DO UPDATE SET %s",
+		table, strings.Join(columns, ", "), placeholders, strings.Join(updates, ", "))
+}
+
+// sqlTx is a transaction that rebinds its queries to the store's dialect
+// and runs them with the context it was begun with
+type sqlTx struct {
+	tx      *sql.Tx
+	dialect Dialect
+	ctx     context.Context
+}
+
+func (t sqlTx) exec(query string, args ...any) (sql.Result, error) {
+	return t.tx.ExecContext(t.ctx, t.dialect.rebind(query), args...)
+}
+
+func (t sqlTx) query(query string, args ...any) (*sql.Rows, error) {
+	return t.tx.QueryContext(t.ctx, t.dialect.rebind(query), args...)
+}
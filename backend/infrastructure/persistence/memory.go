@@ -0,0 +1,655 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+)
+
+// MemoryStore keeps everything in memory and loses it on exit, for tests and the server's ephemeral demo mode
+// It is safe for concurrent use and hands out copies, so callers may change what it returns like with SQLStore
+type MemoryStore struct {
+	mu          sync.RWMutex
+	sessions    map[string]*domain.WorkSession
+	punches     []*domain.Punch // in the order they were recorded
+	absences    map[string]*domain.Absence
+	timeBank    map[string]*domain.TimeBankEntry
+	projects    map[string]*domain.Project
+	allocations []*domain.Allocation // in the order they were saved
+	configs     map[string]*domain.WorkConfig
+}
+
+// NewMemoryStore creates an empty store with the default config
+func NewMemoryStore() domain.Repository {
+	config := defaultConfig()
+	return &MemoryStore{
+		sessions: make(map[string]*domain.WorkSession),
+		absences: make(map[string]*domain.Absence),
+		timeBank: make(map[string]*domain.TimeBankEntry),
+		projects: make(map[string]*domain.Project),
+		configs:  map[string]*domain.WorkConfig{config.ID: config},
+	}
+}
+
+// defaultConfig returns the config a new database starts with, the same as the column defaults of the SQL schema
+func defaultConfig() *domain.WorkConfig {
+	return &domain.WorkConfig{
+		ID:               "default",
+		DefaultWorkHours: domain.StandardWorkMinutes,
+		OvertimePolicy:   domain.DefaultOvertimePolicy(),
+		ComplianceRules:  domain.DefaultComplianceRules(),
+		BalancePolicy:    domain.DefaultBalancePolicy(),
+		RoundingPolicy:   domain.DefaultRoundingPolicy(),
+		CheckOutRule:     domain.DefaultCheckOutRule(),
+	}
+}
+
+// contextDone returns a StorageError of the named method once the context is done, as SQLStore fails its query
+func contextDone(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return &domain.StorageError{Op: op, Err: err}
+	}
+	return nil
+}
+
+// Close does nothing, the data lives as long as the store
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// GetTodaySession retrieves the latest work session for a specific date
+// Voided sessions are left out of every query but GetSession
+func (m *MemoryStore) GetTodaySession(ctx context.Context, date string) (*domain.WorkSession, error) {
+	if err := contextDone(ctx, "GetTodaySession"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessions := m.selectSessions(func(s *domain.WorkSession) bool { return s.Date == date })
+	if len(sessions) == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+	return sessions[len(sessions)-1], nil
+}
+
+// GetOpenSession retrieves the latest work session that has not been checked out, dated on or after since
+func (m *MemoryStore) GetOpenSession(ctx context.Context, since string) (*domain.WorkSession, error) {
+	if err := contextDone(ctx, "GetOpenSession"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var open *domain.WorkSession
+	for _, session := range m.sessions {
+		if session.CheckOut != nil || session.Voided || session.Date < since {
+			continue
+		}
+		if open == nil || session.CheckIn.After(open.CheckIn) {
+			open = session
+		}
+	}
+	if open == nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	return cloneSession(open), nil
+}
+
+// GetOpenSessionsBefore retrieves the work sessions that have not been checked out dated before the date
+func (m *MemoryStore) GetOpenSessionsBefore(ctx context.Context, date string) ([]*domain.WorkSession, error) {
+	if err := contextDone(ctx, "GetOpenSessionsBefore"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.selectSessions(func(s *domain.WorkSession) bool {
+		return s.CheckOut == nil && s.Date < date
+	}), nil
+}
+
+// GetSessionsByDate retrieves all work sessions for a specific date ordered by check-in time
+func (m *MemoryStore) GetSessionsByDate(ctx context.Context, date string) ([]*domain.WorkSession, error) {
+	if err := contextDone(ctx, "GetSessionsByDate"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.selectSessions(func(s *domain.WorkSession) bool { return s.Date == date }), nil
+}
+
+// GetSessionsByMonth retrieves all work sessions for a specific month (YYYY-MM format)
+func (m *MemoryStore) GetSessionsByMonth(ctx context.Context, yearMonth string) ([]*domain.WorkSession, error) {
+	if err := contextDone(ctx, "GetSessionsByMonth"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.selectSessions(func(s *domain.WorkSession) bool { return strings.HasPrefix(s.Date, yearMonth) }), nil
+}
+
+// GetSessionsByDateRange retrieves the work sessions between two dates (YYYY-MM-DD, inclusive, empty means unbounded)
+func (m *MemoryStore) GetSessionsByDateRange(ctx context.Context, from, to string) ([]*domain.WorkSession, error) {
+	if err := contextDone(ctx, "GetSessionsByDateRange"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.selectSessions(func(s *domain.WorkSession) bool {
+		return (from == "" || s.Date >= from) && (to == "" || s.Date <= to)
+	}), nil
+}
+
+// GetSession retrieves a work session by ID
+func (m *MemoryStore) GetSession(ctx context.Context, id string) (*domain.WorkSession, error) {
+	if err := contextDone(ctx, "GetSession"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return cloneSession(session), nil
+}
+
+// selectSessions returns copies of the sessions that are not voided and match, ordered by date and check-in time
+// The caller must hold the lock
+func (m *MemoryStore) selectSessions(match func(*domain.WorkSession) bool) []*domain.WorkSession {
+	var sessions []*domain.WorkSession
+	for _, session := range m.sessions {
+		if !session.Voided && match(session) {
+			sessions = append(sessions, cloneSession(session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].Date != sessions[j].Date {
+			return sessions[i].Date < sessions[j].Date
+		}
+		if !sessions[i].CheckIn.Equal(sessions[j].CheckIn) {
+			return sessions[i].CheckIn.Before(sessions[j].CheckIn)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// SaveSession saves or updates a work session together with its breaks
+func (m *MemoryStore) SaveSession(ctx context.Context, session *domain.WorkSession) error {
+	if err := contextDone(ctx, "SaveSession"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[session.ID] = cloneSession(session)
+	return nil
+}
+
+// RecordPunch appends a punch to the log and saves the session it changed, a nil session only records the punch
+func (m *MemoryStore) RecordPunch(ctx context.Context, punch *domain.Punch, session *domain.WorkSession) error {
+	if err := contextDone(ctx, "RecordPunch"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Punches are never replaced, a duplicate ID is an error
+	for _, recorded := range m.punches {
+		if recorded.ID == punch.ID {
+			return fmt.Errorf("punch %s %w", punch.ID, domain.ErrAlreadyExists)
+		}
+	}
+	recorded := *punch
+	m.punches = append(m.punches, &recorded)
+	if session != nil {
+		m.sessions[session.ID] = cloneSession(session)
+	}
+	return nil
+}
+
+// GetPunches retrieves the punches between two dates (YYYY-MM-DD, inclusive, empty means unbounded)
+// in the order they were recorded
+func (m *MemoryStore) GetPunches(ctx context.Context, from, to string) ([]*domain.Punch, error) {
+	if err := contextDone(ctx, "GetPunches"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var punches []*domain.Punch
+	for _, punch := range m.punches {
+		date := punch.At.In(time.Local).Format("2006-01-02")
+		if (from != "" && date < from) || (to != "" && date > to) {
+			continue
+		}
+		p := *punch
+		punches = append(punches, &p)
+	}
+	// Stable, punches recorded at the same time keep the order they were appended in
+	sort.SliceStable(punches, func(i, j int) bool {
+		return punches[i].RecordedAt.Before(punches[j].RecordedAt)
+	})
+	return punches, nil
+}
+
+// ReplaceSessions replaces every session with the derived ones, the notes and tags of sessions that remain are kept
+func (m *MemoryStore) ReplaceSessions(ctx context.Context, sessions []*domain.WorkSession) error {
+	if err := contextDone(ctx, "ReplaceSessions"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	derived := make(map[string]*domain.WorkSession, len(sessions))
+	for _, session := range sessions {
+		replacement := cloneSession(session)
+		replacement.Note, replacement.Tags = "", nil
+		if existing, ok := m.sessions[session.ID]; ok {
+			replacement.Note, replacement.Tags = existing.Note, existing.Tags
+		}
+		derived[session.ID] = replacement
+	}
+	m.sessions = derived
+
+	// The allocations of sessions no punch derives any more go with them
+	allocations := m.allocations[:0]
+	for _, allocation := range m.allocations {
+		if _, ok := derived[allocation.SessionID]; ok {
+			allocations = append(allocations, allocation)
+		}
+	}
+	m.allocations = allocations
+	return nil
+}
+
+// SaveProject saves or updates a project
+func (m *MemoryStore) SaveProject(ctx context.Context, project *domain.Project) error {
+	if err := contextDone(ctx, "SaveProject"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	saved := *project
+	m.projects[project.ID] = &saved
+	return nil
+}
+
+// DeleteProject deletes a project that no time is allocated to
+func (m *MemoryStore) DeleteProject(ctx context.Context, id string) error {
+	if err := contextDone(ctx, "DeleteProject"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, a := range m.allocations {
+		if a.ProjectID == id {
+			return domain.ErrProjectInUse
+		}
+	}
+	if _, ok := m.projects[id]; !ok {
+		return domain.ErrProjectNotFound
+	}
+	delete(m.projects, id)
+	return nil
+}
+
+// GetProjects retrieves all projects ordered by name
+func (m *MemoryStore) GetProjects(ctx context.Context) ([]*domain.Project, error) {
+	if err := contextDone(ctx, "GetProjects"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var projects []*domain.Project
+	for _, project := range m.projects {
+		p := *project
+		projects = append(projects, &p)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID < projects[j].ID
+	})
+	return projects, nil
+}
+
+// SaveAllocations replaces the allocations of a session
+func (m *MemoryStore) SaveAllocations(ctx context.Context, sessionID string, allocations []*domain.Allocation) error {
+	if err := contextDone(ctx, "SaveAllocations"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.allocations[:0:0]
+	for _, a := range m.allocations {
+		if a.SessionID != sessionID {
+			kept = append(kept, a)
+		}
+	}
+	for _, a := range allocations {
+		saved := *a
+		saved.SessionID = sessionID
+		kept = append(kept, &saved)
+	}
+	m.allocations = kept
+	return nil
+}
+
+// GetAllocationsBySession retrieves the allocations of a session
+func (m *MemoryStore) GetAllocationsBySession(ctx context.Context, sessionID string) ([]*domain.Allocation, error) {
+	if err := contextDone(ctx, "GetAllocationsBySession"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.allocationsOf(sessionID), nil
+}
+
+// GetAllocationsByDateRange retrieves the allocations of sessions between two dates (YYYY-MM-DD, inclusive)
+func (m *MemoryStore) GetAllocationsByDateRange(ctx context.Context, from, to string) ([]*domain.Allocation, error) {
+	if err := contextDone(ctx, "GetAllocationsByDateRange"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var allocations []*domain.Allocation
+	for _, session := range m.selectSessions(func(s *domain.WorkSession) bool { return s.Date >= from && s.Date <= to }) {
+		allocations = append(allocations, m.allocationsOf(session.ID)...)
+	}
+	return allocations, nil
+}
+
+// allocationsOf returns copies of the allocations of a session in the order they were saved
+// The caller must hold the lock
+func (m *MemoryStore) allocationsOf(sessionID string) []*domain.Allocation {
+	var allocations []*domain.Allocation
+	for _, a := range m.allocations {
+		if a.SessionID == sessionID {
+			allocation := *a
+			allocations = append(allocations, &allocation)
+		}
+	}
+	return allocations
+}
+
+// GetAbsence retrieves an absence by ID
+func (m *MemoryStore) GetAbsence(ctx context.Context, id string) (*domain.Absence, error) {
+	if err := contextDone(ctx, "GetAbsence"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	absence, ok := m.absences[id]
+	if !ok {
+		return nil, domain.ErrAbsenceNotFound
+	}
+	a := *absence
+	return &a, nil
+}
+
+// SaveAbsence saves or updates an absence
+func (m *MemoryStore) SaveAbsence(ctx context.Context, absence *domain.Absence) error {
+	if err := contextDone(ctx, "SaveAbsence"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	saved := *absence
+	m.absences[absence.ID] = &saved
+	return nil
+}
+
+// DeleteAbsence deletes an absence by ID
+func (m *MemoryStore) DeleteAbsence(ctx context.Context, id string) error {
+	if err := contextDone(ctx, "DeleteAbsence"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.absences[id]; !ok {
+		return domain.ErrAbsenceNotFound
+	}
+	delete(m.absences, id)
+	return nil
+}
+
+// GetAbsencesByDate retrieves the absences of a specific date
+func (m *MemoryStore) GetAbsencesByDate(ctx context.Context, date string) ([]*domain.Absence, error) {
+	if err := contextDone(ctx, "GetAbsencesByDate"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.selectAbsences(func(a *domain.Absence) bool { return a.Date == date }), nil
+}
+
+// GetAbsencesByMonth retrieves all absences for a specific month (YYYY-MM format)
+func (m *MemoryStore) GetAbsencesByMonth(ctx context.Context, yearMonth string) ([]*domain.Absence, error) {
+	if err := contextDone(ctx, "GetAbsencesByMonth"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.selectAbsences(func(a *domain.Absence) bool { return strings.HasPrefix(a.Date, yearMonth) }), nil
+}
+
+// GetAbsencesByDateRange retrieves the absences between two dates (YYYY-MM-DD, inclusive)
+func (m *MemoryStore) GetAbsencesByDateRange(ctx context.Context, from, to string) ([]*domain.Absence, error) {
+	if err := contextDone(ctx, "GetAbsencesByDateRange"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.selectAbsences(func(a *domain.Absence) bool { return a.Date >= from && a.Date <= to }), nil
+}
+
+// selectAbsences returns copies of the absences that match ordered by date and ID
+// The caller must hold the lock
+func (m *MemoryStore) selectAbsences(match func(*domain.Absence) bool) []*domain.Absence {
+	var absences []*domain.Absence
+	for _, absence := range m.absences {
+		if match(absence) {
+			a := *absence
+			absences = append(absences, &a)
+		}
+	}
+	sort.Slice(absences, func(i, j int) bool {
+		if absences[i].Date != absences[j].Date {
+			return absences[i].Date < absences[j].Date
+		}
+		return absences[i].ID < absences[j].ID
+	})
+	return absences
+}
+
+// SaveTimeBankEntry saves or updates a time bank entry
+func (m *MemoryStore) SaveTimeBankEntry(ctx context.Context, entry *domain.TimeBankEntry) error {
+	if err := contextDone(ctx, "SaveTimeBankEntry"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	saved := *entry
+	m.timeBank[entry.ID] = &saved
+	return nil
+}
+
+// DeleteTimeBankEntry deletes a time bank entry by ID
+func (m *MemoryStore) DeleteTimeBankEntry(ctx context.Context, id string) error {
+	if err := contextDone(ctx, "DeleteTimeBankEntry"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.timeBank[id]; !ok {
+		return domain.ErrTimeBankEntryNotFound
+	}
+	delete(m.timeBank, id)
+	return nil
+}
+
+// GetTimeBankEntries retrieves time bank entries between two dates, an empty bound is open
+func (m *MemoryStore) GetTimeBankEntries(ctx context.Context, from, to string) ([]*domain.TimeBankEntry, error) {
+	if err := contextDone(ctx, "GetTimeBankEntries"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entries []*domain.TimeBankEntry
+	for _, entry := range m.timeBank {
+		if (from != "" && entry.Date < from) || (to != "" && entry.Date > to) {
+			continue
+		}
+		e := *entry
+		entries = append(entries, &e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+// SumTimeBankMinutes sums the minutes of all entries dated before a date, empty sums everything
+func (m *MemoryStore) SumTimeBankMinutes(ctx context.Context, before string) (int, error) {
+	if err := contextDone(ctx, "SumTimeBankMinutes"); err != nil {
+		return 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := 0
+	for _, entry := range m.timeBank {
+		if before == "" || entry.Date < before {
+			total += entry.Minutes
+		}
+	}
+	return total, nil
+}
+
+// GetConfig retrieves the work configuration
+func (m *MemoryStore) GetConfig(ctx context.Context) (*domain.WorkConfig, error) {
+	if err := contextDone(ctx, "GetConfig"); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return cloneConfig(m.configs["default"]), nil
+}
+
+// SaveConfig saves or updates the work configuration
+func (m *MemoryStore) SaveConfig(ctx context.Context, config *domain.WorkConfig) error {
+	if err := contextDone(ctx, "SaveConfig"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.configs[config.ID] = cloneConfig(config)
+	return nil
+}
+
+// cloneSession returns a deep copy of a session with its breaks ordered by start time, nil if it has none
+// Slices are copied with s[:0:0] so a nil slice stays nil and an empty one empty, as through a JSON column
+func cloneSession(session *domain.WorkSession) *domain.WorkSession {
+	clone := *session
+	if session.CheckOut != nil {
+		checkOut := *session.CheckOut
+		clone.CheckOut = &checkOut
+	}
+	clone.Breaks = nil
+	for _, b := range session.Breaks {
+		if b.End != nil {
+			end := *b.End
+			b.End = &end
+		}
+		clone.Breaks = append(clone.Breaks, b)
+	}
+	sort.SliceStable(clone.Breaks, func(i, j int) bool { return clone.Breaks[i].Start.Before(clone.Breaks[j].Start) })
+	clone.Tags = append(session.Tags[:0:0], session.Tags...)
+	return &clone
+}
+
+// cloneConfig returns a deep copy of a config
+func cloneConfig(config *domain.WorkConfig) *domain.WorkConfig {
+	clone := *config
+	if config.WeeklySchedule != nil {
+		schedule := *config.WeeklySchedule
+		clone.WeeklySchedule = &schedule
+	}
+	if config.WorkCycle != nil {
+		cycle := *config.WorkCycle
+		if config.WorkCycle.Weeks != nil {
+			cycle.Weeks = make([][]time.Weekday, len(config.WorkCycle.Weeks))
+			for i, week := range config.WorkCycle.Weeks {
+				cycle.Weeks[i] = append(week[:0:0], week...)
+			}
+		}
+		clone.WorkCycle = &cycle
+	}
+	clone.SourcePrecedence = append(config.SourcePrecedence[:0:0], config.SourcePrecedence...)
+	return &clone
+}
@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryStore()
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	end := checkIn.Add(time.Hour)
+	session := &domain.WorkSession{
+		ID: "s1", Date: "2025-10-13", CheckIn: checkIn, WorkHours: 480,
+		Breaks: []domain.Break{{ID: "b1", Start: checkIn, End: &end}}, Tags: []string{"a"},
+	}
+	assert.NoError(t, repo.SaveSession(ctx, session))
+
+	// Neither the saved session nor a returned one is shared with the store
+	session.Tags[0] = "changed"
+	*session.Breaks[0].End = checkIn
+	got, err := repo.GetSession(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, got.Tags)
+	assert.WithinDuration(t, checkIn.Add(time.Hour), *got.Breaks[0].End, 0)
+
+	got.Note = "changed"
+	got.Tags = append(got.Tags, "b")
+	got, err = repo.GetSession(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Empty(t, got.Note)
+	assert.Equal(t, []string{"a"}, got.Tags)
+
+	config, err := repo.GetConfig(ctx)
+	assert.NoError(t, err)
+	config.SourcePrecedence = domain.DefaultSourcePrecedence()
+	config, err = repo.GetConfig(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, config.SourcePrecedence)
+}
+
+func TestMemoryStoreConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryStore()
+	day := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			at := day.Add(time.Duration(i) * time.Minute)
+			id := fmt.Sprintf("s%d", i)
+			assert.NoError(t, repo.RecordPunch(ctx,
+				&domain.Punch{ID: id, Kind: domain.PunchCheckIn, At: at, Source: domain.PunchSourceManual, RecordedAt: at},
+				&domain.WorkSession{ID: id, Date: "2025-10-13", CheckIn: at, WorkHours: 480},
+			))
+			_, err := repo.GetSessionsByDate(ctx, "2025-10-13")
+			assert.NoError(t, err)
+			_, err = repo.GetPunches(ctx, "", "")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	sessions, err := repo.GetSessionsByDate(ctx, "2025-10-13")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 20)
+	punches, err := repo.GetPunches(ctx, "", "")
+	assert.NoError(t, err)
+	assert.Len(t, punches, 20)
+}
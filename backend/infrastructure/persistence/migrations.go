@@ -0,0 +1,160 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a numbered schema change, applied in the same transaction as its record in schema_migrations
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+	down    func(tx *sql.Tx) error
+}
+
+// migrations lists the schema changes of the dialect in order
+// A released migration is never edited, a schema change is a new migration appended to the list of every dialect
+// under the same version and name
+func (d Dialect) migrations() []migration {
+	if d == DialectPostgres {
+		return postgresMigrations
+	}
+	return sqliteMigrations
+}
+
+// LatestSchemaVersion returns the version of the newest migration
+func LatestSchemaVersion() int {
+	return sqliteMigrations[len(sqliteMigrations)-1].version
+}
+
+// MigrationStep is a migration applied or rolled back
+type MigrationStep struct {
+	Version int
+	Name    string
+	Down    bool // rolled back rather than applied
+}
+
+func (s MigrationStep) String() string {
+	direction := "up"
+	if s.Down {
+		direction = "down"
+	}
+	return fmt.Sprintf("%03d_%s %s", s.Version, s.Name, direction)
+}
+
+// Migrator applies and rolls back the numbered schema migrations of a database
+type Migrator struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewMigrator creates a migrator for the database of the dialect
+func NewMigrator(db *sql.DB, dialect Dialect) *Migrator {
+	return &Migrator{db: db, dialect: dialect}
+}
+
+// Version returns the version of the newest migration applied, 0 for a database that has never been migrated
+func (m *Migrator) Version() (int, error) {
+	var tables int
+	err := m.db.QueryRow(m.dialect.tableExistsQuery(), "schema_migrations").Scan(&tables)
+	if err != nil || tables == 0 {
+		return 0, err
+	}
+	return currentVersion(m.db)
+}
+
+// Migrate applies or rolls back migrations until the database is at the target version, all in one transaction
+// A dry run performs the same steps and rolls the transaction back, so only the returned steps tell what would change
+func (m *Migrator) Migrate(target int, dryRun bool) ([]MigrationStep, error) {
+	if target < 0 || target > LatestSchemaVersion() {
+		return nil, fmt.Errorf("unknown schema version %d, the latest is %d", target, LatestSchemaVersion())
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	timestamp := "DATETIME"
+	if m.dialect == DialectPostgres {
+		timestamp = "TIMESTAMPTZ"
+	}
+	_, err = tx.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at ` + timestamp + ` NOT NULL
+	);`)
+	if err != nil {
+		return nil, err
+	}
+	current, err := currentVersion(tx)
+	if err != nil {
+		return nil, err
+	}
+	// A newer binary migrated the database, this one knows neither its schema nor how to roll it back
+	if current > LatestSchemaVersion() {
+		return nil, fmt.Errorf("schema version %d is newer than the latest known %d, upgrade the binary", current, LatestSchemaVersion())
+	}
+
+	migrations := m.dialect.migrations()
+	var steps []MigrationStep
+	if target >= current {
+		for _, mig := range migrations {
+			if mig.version <= current || mig.version > target {
+				continue
+			}
+			if err := mig.up(tx); err != nil {
+				return nil, fmt.Errorf("failed to apply migration %03d_%s: %w", mig.version, mig.name, err)
+			}
+			if _, err := tx.Exec(m.dialect.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+				mig.version, mig.name, time.Now()); err != nil {
+				return nil, err
+			}
+			steps = append(steps, MigrationStep{Version: mig.version, Name: mig.name})
+		}
+	} else {
+		for i := len(migrations) - 1; i >= 0; i-- {
+			mig := migrations[i]
+			if mig.version > current || mig.version <= target {
+				continue
+			}
+			if err := mig.down(tx); err != nil {
+				return nil, fmt.Errorf("failed to roll back migration %03d_%s: %w", mig.version, mig.name, err)
+			}
+			if _, err := tx.Exec(m.dialect.rebind(`DELETE FROM schema_migrations WHERE version = ?`), mig.version); err != nil {
+				return nil, err
+			}
+			steps = append(steps, MigrationStep{Version: mig.version, Name: mig.name, Down: true})
+		}
+	}
+
+	if dryRun {
+		return steps, nil
+	}
+	return steps, tx.Commit()
+}
+
+// currentVersion returns the version of the newest migration recorded in schema_migrations
+func currentVersion(q interface {
+	QueryRow(query string, args ...any) *sql.Row
+}) (int, error) {
+	var version int
+	err := q.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// execAll returns a migration step that runs the statements in order
+func execAll(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
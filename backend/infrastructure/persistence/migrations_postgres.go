@@ -0,0 +1,125 @@
+package persistence
+
+// postgresMigrations lists the schema changes of PostgreSQL databases
+// There were no PostgreSQL databases before migrations were numbered, the baseline only creates the tables
+// Timestamps are TIMESTAMPTZ, and punches and allocations keep their insertion order in seq as SQLite does in rowid
+var postgresMigrations = []migration{
+	{
+		version: 1,
+		name:    "baseline",
+		up: execAll(
+			`CREATE TABLE work_sessions (
+				id TEXT PRIMARY KEY,
+				date TEXT NOT NULL,
+				check_in TIMESTAMPTZ NOT NULL,
+				check_out TIMESTAMPTZ,
+				work_hours INTEGER NOT NULL,
+				note TEXT DEFAULT '',
+				tags TEXT DEFAULT '',
+				auto_closed BOOLEAN DEFAULT FALSE,
+				voided BOOLEAN DEFAULT FALSE,
+				check_in_source TEXT DEFAULT 'manual',
+				check_out_source TEXT DEFAULT ''
+			)`,
+			`CREATE INDEX work_sessions_date ON work_sessions (date)`,
+			`CREATE TABLE work_breaks (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				start_time TIMESTAMPTZ NOT NULL,
+				end_time TIMESTAMPTZ
+			)`,
+			`CREATE INDEX work_breaks_session_id ON work_breaks (session_id)`,
+			`CREATE TABLE punches (
+				id TEXT PRIMARY KEY,
+				seq BIGSERIAL,
+				kind TEXT NOT NULL,
+				at TIMESTAMPTZ NOT NULL,
+				source TEXT NOT NULL,
+				session_id TEXT DEFAULT '',
+				recorded_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE TABLE absences (
+				id TEXT PRIMARY KEY,
+				date TEXT NOT NULL,
+				type TEXT NOT NULL,
+				amount TEXT NOT NULL,
+				note TEXT DEFAULT ''
+			)`,
+			`CREATE TABLE time_bank_entries (
+				id TEXT PRIMARY KEY,
+				date TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				minutes INTEGER NOT NULL,
+				note TEXT DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE TABLE projects (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				code TEXT DEFAULT '',
+				archived BOOLEAN DEFAULT FALSE
+			)`,
+			`CREATE TABLE allocations (
+				id TEXT PRIMARY KEY,
+				seq BIGSERIAL,
+				session_id TEXT NOT NULL,
+				project_id TEXT NOT NULL,
+				task TEXT DEFAULT '',
+				minutes INTEGER DEFAULT 0,
+				percent DOUBLE PRECISION DEFAULT 0
+			)`,
+			`CREATE TABLE work_config (
+				id TEXT PRIMARY KEY,
+				default_work_hours INTEGER NOT NULL,
+				check_in_api_url TEXT DEFAULT '',
+				auto_fetch_enabled BOOLEAN DEFAULT FALSE,
+				"authorization" TEXT DEFAULT '',
+				p_auth TEXT DEFAULT '',
+				p_rtoken TEXT DEFAULT '',
+				check_in_webhook_url TEXT DEFAULT '',
+				check_out_webhook_url TEXT DEFAULT '',
+				overtime_mode TEXT DEFAULT 'fixed',
+				overtime_threshold_minutes INTEGER DEFAULT 600,
+				overtime_weekday_thresholds TEXT DEFAULT '',
+				overtime_grace_minutes INTEGER DEFAULT 0,
+				day_rollover_hour INTEGER DEFAULT 0,
+				weekly_schedule TEXT DEFAULT '',
+				work_cycle TEXT DEFAULT '',
+				overtime_hourly_rate DOUBLE PRECISION DEFAULT 0,
+				compliance_max_monthly_overtime_minutes INTEGER DEFAULT 2160,
+				compliance_max_weekly_work_minutes INTEGER DEFAULT 3600,
+				compliance_min_rest_minutes INTEGER DEFAULT 660,
+				compliance_warning_percent INTEGER DEFAULT 90,
+				balance_mode TEXT DEFAULT 'off',
+				balance_max_carry_over_minutes INTEGER DEFAULT 0,
+				rounding_interval_minutes INTEGER DEFAULT 0,
+				rounding_check_in TEXT DEFAULT 'none',
+				rounding_check_out TEXT DEFAULT 'none',
+				rounding_grace_minutes INTEGER DEFAULT 0,
+				core_start TEXT DEFAULT '',
+				core_end TEXT DEFAULT '',
+				check_in_reminder_lead_minutes INTEGER DEFAULT 0,
+				check_out_mode TEXT DEFAULT 'duration',
+				check_out_earliest_end TEXT DEFAULT '',
+				check_out_latest_start TEXT DEFAULT '',
+				source_precedence TEXT DEFAULT ''
+			)`,
+		),
+		down: execAll(
+			`DROP TABLE IF EXISTS work_sessions`,
+			`DROP TABLE IF EXISTS work_breaks`,
+			`DROP TABLE IF EXISTS punches`,
+			`DROP TABLE IF EXISTS absences`,
+			`DROP TABLE IF EXISTS time_bank_entries`,
+			`DROP TABLE IF EXISTS projects`,
+			`DROP TABLE IF EXISTS allocations`,
+			`DROP TABLE IF EXISTS work_config`,
+		),
+	},
+	{
+		version: 2,
+		name:    "drop_config_authorization",
+		up:      execAll(`ALTER TABLE work_config DROP COLUMN "authorization"`),
+		down:    execAll(`ALTER TABLE work_config ADD COLUMN "authorization" TEXT DEFAULT ''`),
+	},
+}
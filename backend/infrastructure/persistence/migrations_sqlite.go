@@ -0,0 +1,323 @@
+package persistence
+
+import "database/sql"
+
+// sqliteMigrations lists the schema changes of SQLite databases
+var sqliteMigrations = []migration{
+	{version: 1, name: "baseline", up: sqliteBaselineUp, down: sqliteBaselineDown},
+	{
+		version: 2,
+		name:    "drop_config_authorization",
+		up:      execAll(`ALTER TABLE work_config DROP COLUMN "authorization"`),
+		down:    execAll(`ALTER TABLE work_config ADD COLUMN "authorization" TEXT DEFAULT ''`),
+	},
+}
+
+// sqliteBaselineUp creates the schema as it was before migrations were numbered, bringing a database created
+// by any earlier version up to it: missing tables are created, missing columns added and the punch log backfilled
+func sqliteBaselineUp(tx *sql.Tx) error {
+	createSessionsTable := `
+	CREATE TABLE IF NOT EXISTS work_sessions (
+		id TEXT PRIMARY KEY,
+		date TEXT NOT NULL,
+		check_in DATETIME NOT NULL,
+		check_out DATETIME,
+		work_hours INTEGER NOT NULL,
+		note TEXT DEFAULT '',
+		tags TEXT DEFAULT '',
+		auto_closed INTEGER DEFAULT 0,
+		voided INTEGER DEFAULT 0,
+		check_in_source TEXT DEFAULT 'manual',
+		check_out_source TEXT DEFAULT ''
+	);`
+
+	createBreaksTable := `
+	CREATE TABLE IF NOT EXISTS work_breaks (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		start_time DATETIME NOT NULL,
+		end_time DATETIME
+	);`
+
+	createPunchesTable := `
+	CREATE TABLE IF NOT EXISTS punches (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,
+		at DATETIME NOT NULL,
+		source TEXT NOT NULL,
+		session_id TEXT DEFAULT '',
+		recorded_at DATETIME NOT NULL
+	);`
+
+	createAbsencesTable := `
+	CREATE TABLE IF NOT EXISTS absences (
+		id TEXT PRIMARY KEY,
+		date TEXT NOT NULL,
+		type TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		note TEXT DEFAULT ''
+	);`
+
+	createTimeBankTable := `
+	CREATE TABLE IF NOT EXISTS time_bank_entries (
+		id TEXT PRIMARY KEY,
+		date TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		minutes INTEGER NOT NULL,
+		note TEXT DEFAULT '',
+		created_at DATETIME NOT NULL
+	);`
+
+	createProjectsTable := `
+	CREATE TABLE IF NOT EXISTS projects (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		code TEXT DEFAULT '',
+		archived BOOLEAN DEFAULT FALSE
+	);`
+
+	createAllocationsTable := `
+	CREATE TABLE IF NOT EXISTS allocations (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		task TEXT DEFAULT '',
+		minutes INTEGER DEFAULT 0,
+		percent REAL DEFAULT 0
+	);`
+
+	createConfigTable := `
+	CREATE TABLE IF NOT EXISTS work_config (
+		id TEXT PRIMARY KEY,
+		default_work_hours INTEGER NOT NULL,
+		check_in_api_url TEXT DEFAULT '',
+		auto_fetch_enabled BOOLEAN DEFAULT FALSE,
+		authorization TEXT DEFAULT '',
+		p_auth TEXT DEFAULT '',
+		p_rtoken TEXT DEFAULT '',
+		check_in_webhook_url TEXT DEFAULT '',
+		check_out_webhook_url TEXT DEFAULT '',
+		overtime_mode TEXT DEFAULT 'fixed',
+		overtime_threshold_minutes INTEGER DEFAULT 600,
+		overtime_weekday_thresholds TEXT DEFAULT '',
+		overtime_grace_minutes INTEGER DEFAULT 0,
+		day_rollover_hour INTEGER DEFAULT 0,
+		weekly_schedule TEXT DEFAULT '',
+		work_cycle TEXT DEFAULT '',
+		overtime_hourly_rate REAL DEFAULT 0,
+		compliance_max_monthly_overtime_minutes INTEGER DEFAULT 2160,
+		compliance_max_weekly_work_minutes INTEGER DEFAULT 3600,
+		compliance_min_rest_minutes INTEGER DEFAULT 660,
+		compliance_warning_percent INTEGER DEFAULT 90,
+		balance_mode TEXT DEFAULT 'off',
+		balance_max_carry_over_minutes INTEGER DEFAULT 0,
+		rounding_interval_minutes INTEGER DEFAULT 0,
+		rounding_check_in TEXT DEFAULT 'none',
+		rounding_check_out TEXT DEFAULT 'none',
+		rounding_grace_minutes INTEGER DEFAULT 0,
+		core_start TEXT DEFAULT '',
+		core_end TEXT DEFAULT '',
+		check_in_reminder_lead_minutes INTEGER DEFAULT 0,
+		check_out_mode TEXT DEFAULT 'duration',
+		check_out_earliest_end TEXT DEFAULT '',
+		check_out_latest_start TEXT DEFAULT '',
+		source_precedence TEXT DEFAULT ''
+	);`
+
+	if _, err := tx.Exec(createSessionsTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createBreaksTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createPunchesTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createAbsencesTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createTimeBankTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createProjectsTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createAllocationsTable); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createConfigTable); err != nil {
+		return err
+	}
+
+	// Tables created by an earlier version lack the columns added since
+	if err := migrateSessionsTable(tx); err != nil {
+		return err
+	}
+	if err := migrateConfigTable(tx); err != nil {
+		return err
+	}
+	return backfillPunches(tx)
+}
+
+// sqliteBaselineDown drops every table of the baseline schema
+func sqliteBaselineDown(tx *sql.Tx) error {
+	return execAll(
+		`DROP TABLE IF EXISTS work_sessions`,
+		`DROP TABLE IF EXISTS work_breaks`,
+		`DROP TABLE IF EXISTS punches`,
+		`DROP TABLE IF EXISTS absences`,
+		`DROP TABLE IF EXISTS time_bank_entries`,
+		`DROP TABLE IF EXISTS projects`,
+		`DROP TABLE IF EXISTS allocations`,
+		`DROP TABLE IF EXISTS work_config`,
+	)(tx)
+}
+
+// columnDef is a column added to an existing table
+type columnDef struct {
+	name       string
+	definition string
+}
+
+// migrateSessionsTable adds missing columns to the sessions table
+func migrateSessionsTable(tx *sql.Tx) error {
+	err := addMissingColumns(tx, "work_sessions", []columnDef{
+		{"note", "TEXT DEFAULT ''"},
+		{"tags", "TEXT DEFAULT ''"},
+		{"auto_closed", "INTEGER DEFAULT 0"},
+		{"voided", "INTEGER DEFAULT 0"},
+		{"check_in_source", "TEXT DEFAULT 'manual'"},
+		{"check_out_source", "TEXT DEFAULT ''"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Check-outs recorded before their source was, an auto-closed one came from the HR system
+	_, err = tx.Exec(`
+		UPDATE work_sessions
+		SET check_out_source = CASE WHEN auto_closed THEN 'scheduler_sync' ELSE 'manual' END
+		WHERE check_out IS NOT NULL AND check_out_source = ''
+	`)
+	return err
+}
+
+// migrateConfigTable adds missing columns to the config table
+func migrateConfigTable(tx *sql.Tx) error {
+	return addMissingColumns(tx, "work_config", []columnDef{
+		{"check_in_webhook_url", "TEXT DEFAULT ''"},
+		{"check_out_webhook_url", "TEXT DEFAULT ''"},
+		{"overtime_mode", "TEXT DEFAULT 'fixed'"},
+		{"overtime_threshold_minutes", "INTEGER DEFAULT 600"},
+		{"overtime_weekday_thresholds", "TEXT DEFAULT ''"},
+		{"overtime_grace_minutes", "INTEGER DEFAULT 0"},
+		{"day_rollover_hour", "INTEGER DEFAULT 0"},
+		{"weekly_schedule", "TEXT DEFAULT ''"},
+		{"work_cycle", "TEXT DEFAULT ''"},
+		{"overtime_hourly_rate", "REAL DEFAULT 0"},
+		{"compliance_max_monthly_overtime_minutes", "INTEGER DEFAULT 2160"},
+		{"compliance_max_weekly_work_minutes", "INTEGER DEFAULT 3600"},
+		{"compliance_min_rest_minutes", "INTEGER DEFAULT 660"},
+		{"compliance_warning_percent", "INTEGER DEFAULT 90"},
+		{"balance_mode", "TEXT DEFAULT 'off'"},
+		{"balance_max_carry_over_minutes", "INTEGER DEFAULT 0"},
+		{"rounding_interval_minutes", "INTEGER DEFAULT 0"},
+		{"rounding_check_in", "TEXT DEFAULT 'none'"},
+		{"rounding_check_out", "TEXT DEFAULT 'none'"},
+		{"rounding_grace_minutes", "INTEGER DEFAULT 0"},
+		{"core_start", "TEXT DEFAULT ''"},
+		{"core_end", "TEXT DEFAULT ''"},
+		{"check_in_reminder_lead_minutes", "INTEGER DEFAULT 0"},
+		{"check_out_mode", "TEXT DEFAULT 'duration'"},
+		{"check_out_earliest_end", "TEXT DEFAULT ''"},
+		{"check_out_latest_start", "TEXT DEFAULT ''"},
+		{"source_precedence", "TEXT DEFAULT ''"},
+	})
+}
+
+// backfillPunches writes the punch log of sessions recorded before there was one
+// Punches take the IDs of what they created so that derived sessions and breaks keep theirs
+func backfillPunches(tx *sql.Tx) error {
+	var punches, sessions int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM punches`).Scan(&punches); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM work_sessions`).Scan(&sessions); err != nil {
+		return err
+	}
+	if punches > 0 || sessions == 0 {
+		return nil
+	}
+
+	// One statement per kind, punches recorded at the same time replay in this order
+	statements := []string{
+		`INSERT INTO punches (id, kind, at, source, session_id, recorded_at)
+		SELECT id, 'check_in', check_in, 'manual', '', check_in
+		FROM work_sessions`,
+		`INSERT INTO punches (id, kind, at, source, session_id, recorded_at)
+		SELECT b.id, 'break_start', b.start_time, 'manual', '', b.start_time
+		FROM work_breaks b JOIN work_sessions ws ON ws.id = b.session_id`,
+		`INSERT INTO punches (id, kind, at, source, session_id, recorded_at)
+		SELECT b.id || ':end', 'break_end', b.end_time, 'manual', '', b.end_time
+		FROM work_breaks b JOIN work_sessions ws ON ws.id = b.session_id
+		WHERE b.end_time IS NOT NULL`,
+		`INSERT INTO punches (id, kind, at, source, session_id, recorded_at)
+		SELECT id || ':out', 'check_out', check_out,
+			CASE WHEN auto_closed THEN 'scheduler_sync' ELSE 'manual' END, '', check_out
+		FROM work_sessions
+		WHERE check_out IS NOT NULL`,
+		`INSERT INTO punches (id, kind, at, source, session_id, recorded_at)
+		SELECT id || ':void', 'void', COALESCE(check_out, check_in), 'manual', id, COALESCE(check_out, check_in)
+		FROM work_sessions
+		WHERE voided`,
+	}
+	return execAll(statements...)(tx)
+}
+
+// addMissingColumns adds the columns a table created by an older version does not have yet
+func addMissingColumns(tx *sql.Tx, table string, columns []columnDef) error {
+	rows, err := tx.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	existingColumns := make(map[string]bool)
+	for rows.Next() {
+		var cid int
+		var name string
+		var dataType string
+		var notNull int
+		var defaultValue *string
+		var pk int
+
+		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		existingColumns[name] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	// Add missing columns
+	for _, column := range columns {
+		if existingColumns[column.name] {
+			continue
+		}
+		if _, err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + column.name + " " + column.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// legacySchema is a database created before migrations were numbered, by a version without notes or a punch log
+const legacySchema = `
+CREATE TABLE work_sessions (
+	id TEXT PRIMARY KEY,
+	date TEXT NOT NULL,
+	check_in DATETIME NOT NULL,
+	check_out DATETIME,
+	work_hours INTEGER NOT NULL
+);
+CREATE TABLE work_breaks (
+	id TEXT PRIMARY KEY,
+	session_id TEXT NOT NULL,
+	start_time DATETIME NOT NULL,
+	end_time DATETIME
+);
+CREATE TABLE work_config (
+	id TEXT PRIMARY KEY,
+	default_work_hours INTEGER NOT NULL,
+	check_in_api_url TEXT DEFAULT '',
+	auto_fetch_enabled BOOLEAN DEFAULT FALSE,
+	authorization TEXT DEFAULT '',
+	p_auth TEXT DEFAULT '',
+	p_rtoken TEXT DEFAULT ''
+);
+INSERT INTO work_config (id, default_work_hours, authorization) VALUES ('default', 420, 'Bearer x');
+`
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "worktime.db")
+	db, _, err := Open(path)
+	assert.NoError(t, err)
+	_, err = db.Exec(legacySchema)
+	assert.NoError(t, err)
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	_, err = db.Exec(`INSERT INTO work_sessions (id, date, check_in, check_out, work_hours) VALUES (?, ?, ?, ?, ?)`,
+		"s1", "2025-10-13", checkIn, checkIn.Add(8*time.Hour), 480)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	repo, err := NewSQLiteStore(path)
+	assert.NoError(t, err)
+	defer repo.Close()
+	store := repo.(*SQLStore)
+
+	version, err := NewMigrator(store.db, store.dialect).Version()
+	assert.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), version)
+	assert.False(t, hasColumn(t, store.db, "work_config", "authorization"))
+
+	// The session and config survive, and the punch log is backfilled from the session
+	session, err := repo.GetSession(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, "manual", string(session.CheckOutSource))
+	config, err := repo.GetConfig(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 420, config.DefaultWorkHours)
+	punches, err := repo.GetPunches(ctx, "", "")
+	assert.NoError(t, err)
+	assert.Len(t, punches, 2)
+
+	// Opening the store again applies nothing
+	steps, err := NewMigrator(store.db, store.dialect).Migrate(LatestSchemaVersion(), false)
+	assert.NoError(t, err)
+	assert.Empty(t, steps)
+}
+
+func TestMigrateDownAndDryRun(t *testing.T) {
+	repo, err := NewSQLiteStore(filepath.Join(t.TempDir(), "worktime.db"))
+	assert.NoError(t, err)
+	defer repo.Close()
+	migrator := NewMigrator(repo.(*SQLStore).db, DialectSQLite)
+
+	// A dry run reports the steps and changes nothing
+	steps, err := migrator.Migrate(0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []MigrationStep{
+		{Version: 2, Name: "drop_config_authorization", Down: true},
+		{Version: 1, Name: "baseline", Down: true},
+	}, steps)
+	version, err := migrator.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), version)
+
+	steps, err = migrator.Migrate(1, false)
+	assert.NoError(t, err)
+	assert.Len(t, steps, 1)
+	assert.True(t, hasColumn(t, repo.(*SQLStore).db, "work_config", "authorization"))
+
+	steps, err = migrator.Migrate(LatestSchemaVersion(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []MigrationStep{{Version: 2, Name: "drop_config_authorization"}}, steps)
+	assert.False(t, hasColumn(t, repo.(*SQLStore).db, "work_config", "authorization"))
+
+	_, err = migrator.Migrate(LatestSchemaVersion()+1, false)
+	assert.Error(t, err)
+
+	// A schema migrated by a newer binary is left alone
+	_, err = repo.(*SQLStore).db.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		LatestSchemaVersion()+1, "from_the_future", time.Now())
+	assert.NoError(t, err)
+	_, err = migrator.Migrate(LatestSchemaVersion(), false)
+	assert.ErrorContains(t, err, "newer than the latest known")
+	_, err = migrator.Migrate(0, false)
+	assert.Error(t, err)
+	assert.True(t, hasColumn(t, repo.(*SQLStore).db, "work_sessions", "id"))
+}
+
+// hasColumn returns true if the table has the column
+func hasColumn(t *testing.T, db *sql.DB, table, column string) bool {
+	t.Helper()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	assert.NoError(t, err)
+	return count > 0
+}
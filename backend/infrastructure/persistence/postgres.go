@@ -0,0 +1,33 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/simon0-o/offline_me/backend/domain"
+)
+
+// postgresUniqueViolation is the SQLSTATE of an insert that breaks a primary key or unique constraint
+const postgresUniqueViolation = "23505"
+
+// NewPostgresStore creates a store on the PostgreSQL database of a postgres:// URL and migrates it to the latest schema
+func NewPostgresStore(dsn string) (domain.Repository, error) {
+	if DialectOf(dsn) != DialectPostgres {
+		return nil, fmt.Errorf("not a PostgreSQL DSN, expected a postgres:// URL")
+	}
+	return NewStore(dsn)
+}
+
+// isPostgresUniqueViolation returns true if the error is PostgreSQL refusing a duplicate key
+func isPostgresUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == postgresUniqueViolation
+}
+
+// openPostgres opens the PostgreSQL database of a postgres:// URL
+func openPostgres(dsn string) (*sql.DB, error) {
+	return sql.Open("pgx", dsn)
+}
@@ -2,201 +2,812 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/simon0-o/offline_me/backend/domain"
 )
 
-// SQLiteStore handles database operations
-type SQLiteStore struct {
-	db *sql.DB
+// SQLStore implements the repository on a SQL database, SQLite or PostgreSQL
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
 }
 
-// NewSQLiteStore creates a new SQLite store and initializes the database
+// NewStore opens the database of a DSN, a postgres:// URL or the path of a SQLite file,
+// and migrates it to the latest schema
 // Returns domain.Repository interface for dependency inversion
-func NewSQLiteStore(dbPath string) (domain.Repository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+func NewStore(dsn string) (domain.Repository, error) {
+	db, dialect, err := Open(dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	store := &SQLiteStore{db: db}
-	if err := store.initTables(); err != nil {
+	steps, err := NewMigrator(db, dialect).Migrate(LatestSchemaVersion(), false)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, step := range steps {
+		slog.Info("[Migrate] Applied migration", "dialect", dialect, "migration", step.String())
+	}
+
+	store := &SQLStore{db: db, dialect: dialect}
+	if err := store.initConfig(context.Background()); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return store, nil
 }
 
+// DefaultDSN returns the DSN of the DATABASE_DSN environment variable,
+// or else the SQLite file the server has always used next to the backend directory
+func DefaultDSN() string {
+	if dsn := os.Getenv("DATABASE_DSN"); dsn != "" {
+		return dsn
+	}
+	return "../worktime.db"
+}
+
+// Open opens the database of a DSN without migrating it
+func Open(dsn string) (*sql.DB, Dialect, error) {
+	dialect := DialectOf(dsn)
+	var db *sql.DB
+	var err error
+	if dialect == DialectPostgres {
+		db, err = openPostgres(dsn)
+	} else {
+		db, err = openSQLite(dsn)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return db, dialect, nil
+}
+
+// initConfig inserts the default config if there is none yet
+func (s *SQLStore) initConfig(ctx context.Context) error {
+	_, err := s.exec(ctx, `
+		INSERT INTO work_config (
+			id, default_work_hours, check_in_api_url, auto_fetch_enabled,
+			p_auth, p_rtoken, check_in_webhook_url, check_out_webhook_url
+		) VALUES ('default', ?, '', FALSE, '', '', '', '')
+		ON CONFLICT (id) DO NOTHING
+	`, domain.StandardWorkMinutes)
+	return err
+}
+
+// exec runs a statement written with ? placeholders
+func (s *SQLStore) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.dialect.rebind(query), args...)
+}
+
+// query runs a query written with ? placeholders
+func (s *SQLStore) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.dialect.rebind(query), args...)
+}
+
+// queryRow runs a query written with ? placeholders that returns at most one row
+func (s *SQLStore) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return s.db.QueryRowContext(ctx, s.dialect.rebind(query), args...)
+}
+
+// begin starts a transaction that the context rolls back if it is done before the commit
+func (s *SQLStore) begin(ctx context.Context) (sqlTx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	return sqlTx{tx: tx, dialect: s.dialect, ctx: ctx}, err
+}
+
+// failed turns an error of the named method into a StorageError, leaving the errors that are answers as they are:
+// a missing record, a duplicate one or a project still in use
+func failed(op string, err *error) {
+	if *err == nil || errors.Is(*err, domain.ErrNotFound) || errors.Is(*err, domain.ErrProjectInUse) {
+		return
+	}
+	if isSQLiteUniqueViolation(*err) || isPostgresUniqueViolation(*err) {
+		*err = fmt.Errorf("%s: %w: %v", op, domain.ErrAlreadyExists, *err)
+		return
+	}
+	*err = &domain.StorageError{Op: op, Err: *err}
+}
+
 // Close closes the database connection
-func (s *SQLiteStore) Close() error {
+func (s *SQLStore) Close() error {
 	return s.db.Close()
 }
 
-// initTables creates the required database tables
-func (s *SQLiteStore) initTables() error {
-	createSessionsTable := `
-	CREATE TABLE IF NOT EXISTS work_sessions (
-		id TEXT PRIMARY KEY,
-		date TEXT NOT NULL,
-		check_in DATETIME NOT NULL,
-		check_out DATETIME,
-		work_hours INTEGER NOT NULL
-	);`
+// GetTodaySession retrieves the latest work session for a specific date
+// Voided sessions are left out of every query but GetSession
+func (s *SQLStore) GetTodaySession(ctx context.Context, date string) (_ *domain.WorkSession, err error) {
+	defer failed("GetTodaySession", &err)
+	sessions, err := s.querySessions(ctx, `
+		SELECT id, date, check_in, check_out, work_hours, note, tags, auto_closed, voided,
+		       check_in_source, check_out_source
+		FROM work_sessions
+		WHERE date = ? AND NOT voided
+		ORDER BY check_in DESC
+		LIMIT 1
+	`, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
 
-	createConfigTable := `
-	CREATE TABLE IF NOT EXISTS work_config (
-		id TEXT PRIMARY KEY,
-		default_work_hours INTEGER NOT NULL,
-		check_in_api_url TEXT DEFAULT '',
-		auto_fetch_enabled BOOLEAN DEFAULT FALSE,
-		authorization TEXT DEFAULT '',
-		p_auth TEXT DEFAULT '',
-		p_rtoken TEXT DEFAULT '',
-		check_in_webhook_url TEXT DEFAULT '',
-		check_out_webhook_url TEXT DEFAULT ''
-	);`
+	return sessions[0], nil
+}
 
-	if _, err := s.db.Exec(createSessionsTable); err != nil {
+// GetOpenSession retrieves the latest work session that has not been checked out, dated on or after since
+func (s *SQLStore) GetOpenSession(ctx context.Context, since string) (_ *domain.WorkSession, err error) {
+	defer failed("GetOpenSession", &err)
+	sessions, err := s.querySessions(ctx, `
+		SELECT id, date, check_in, check_out, work_hours, note, tags, auto_closed, voided,
+		       check_in_source, check_out_source
+		FROM work_sessions
+		WHERE check_out IS NULL AND NOT voided AND date >= ?
+		ORDER BY check_in DESC
+		LIMIT 1
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	return sessions[0], nil
+}
+
+// GetOpenSessionsBefore retrieves the work sessions that have not been checked out dated before the date
+func (s *SQLStore) GetOpenSessionsBefore(ctx context.Context, date string) (_ []*domain.WorkSession, err error) {
+	defer failed("GetOpenSessionsBefore", &err)
+	return s.querySessions(ctx, `
+		SELECT id, date, check_in, check_out, work_hours, note, tags, auto_closed, voided,
+		       check_in_source, check_out_source
+		FROM work_sessions
+		WHERE check_out IS NULL AND NOT voided AND date < ?
+		ORDER BY date ASC, check_in ASC
+	`, date)
+}
+
+// GetSessionsByDate retrieves all work sessions for a specific date ordered by check-in time
+func (s *SQLStore) GetSessionsByDate(ctx context.Context, date string) (_ []*domain.WorkSession, err error) {
+	defer failed("GetSessionsByDate", &err)
+	return s.querySessions(ctx, `
+		SELECT id, date, check_in, check_out, work_hours, note, tags, auto_closed, voided,
+		       check_in_source, check_out_source
+		FROM work_sessions
+		WHERE date = ? AND NOT voided
+		ORDER BY check_in ASC
+	`, date)
+}
+
+// GetSessionsByMonth retrieves all work sessions for a specific month (YYYY-MM format)
+func (s *SQLStore) GetSessionsByMonth(ctx context.Context, yearMonth string) (_ []*domain.WorkSession, err error) {
+	defer failed("GetSessionsByMonth", &err)
+	return s.querySessions(ctx, `
+		SELECT id, date, check_in, check_out, work_hours, note, tags, auto_closed, voided,
+		       check_in_source, check_out_source
+		FROM work_sessions
+		WHERE date LIKE ? AND NOT voided
+		ORDER BY date ASC, check_in ASC
+	`, yearMonth+"%")
+}
+
+// GetSessionsByDateRange retrieves the work sessions between two dates (YYYY-MM-DD, inclusive, empty means unbounded)
+func (s *SQLStore) GetSessionsByDateRange(ctx context.Context, from, to string) (_ []*domain.WorkSession, err error) {
+	defer failed("GetSessionsByDateRange", &err)
+	return s.querySessions(ctx, `
+		SELECT id, date, check_in, check_out, work_hours, note, tags, auto_closed, voided,
+		       check_in_source, check_out_source
+		FROM work_sessions
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?) AND NOT voided
+		ORDER BY date ASC, check_in ASC
+	`, from, from, to, to)
+}
+
+// GetSession retrieves a work session by ID
+func (s *SQLStore) GetSession(ctx context.Context, id string) (_ *domain.WorkSession, err error) {
+	defer failed("GetSession", &err)
+	sessions, err := s.querySessions(ctx, `
+		SELECT id, date, check_in, check_out, work_hours, note, tags, auto_closed, voided,
+		       check_in_source, check_out_source
+		FROM work_sessions
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	return sessions[0], nil
+}
+
+// querySessions runs a session query and loads the breaks of every returned session
+func (s *SQLStore) querySessions(ctx context.Context, query string, args ...any) ([]*domain.WorkSession, error) {
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.WorkSession
+	for rows.Next() {
+		var session domain.WorkSession
+		var checkOut sql.NullTime
+		var tags, checkInSource, checkOutSource string
+
+		err := rows.Scan(&session.ID, &session.Date, &session.CheckIn, &checkOut, &session.WorkHours, &session.Note, &tags,
+			&session.AutoClosed, &session.Voided, &checkInSource, &checkOutSource)
+		if err != nil {
+			return nil, err
+		}
+		session.CheckInSource = domain.PunchSource(checkInSource)
+		session.CheckOutSource = domain.PunchSource(checkOutSource)
+		if err := unmarshalJSONColumn(tags, &session.Tags); err != nil {
+			return nil, err
+		}
+
+		if checkOut.Valid {
+			session.CheckOut = &checkOut.Time
+		}
+
+		sessions = append(sessions, &session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, session := range sessions {
+		breaks, err := s.loadBreaks(ctx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+		session.Breaks = breaks
+	}
+
+	return sessions, nil
+}
+
+// loadBreaks retrieves the breaks of a session ordered by start time
+func (s *SQLStore) loadBreaks(ctx context.Context, sessionID string) ([]domain.Break, error) {
+	rows, err := s.query(ctx, `
+		SELECT id, start_time, end_time
+		FROM work_breaks
+		WHERE session_id = ?
+		ORDER BY start_time ASC
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var breaks []domain.Break
+	for rows.Next() {
+		var b domain.Break
+		var end sql.NullTime
+
+		if err := rows.Scan(&b.ID, &b.Start, &end); err != nil {
+			return nil, err
+		}
+
+		if end.Valid {
+			b.End = &end.Time
+		}
+
+		breaks = append(breaks, b)
+	}
+
+	return breaks, rows.Err()
+}
+
+// SaveSession saves or updates a work session together with its breaks
+func (s *SQLStore) SaveSession(ctx context.Context, session *domain.WorkSession) (err error) {
+	defer failed("SaveSession", &err)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.tx.Rollback()
+
+	if err := saveSession(tx, session); err != nil {
 		return err
 	}
 
-	if _, err := s.db.Exec(createConfigTable); err != nil {
+	return tx.tx.Commit()
+}
+
+// saveSession writes a session and replaces its breaks
+func saveSession(tx sqlTx, session *domain.WorkSession) error {
+	tags, err := json.Marshal(session.Tags)
+	if err != nil {
 		return err
 	}
 
-	// Migrate existing table to add new columns if they don't exist
-	if err := s.migrateConfigTable(); err != nil {
+	_, err = tx.exec(upsert("work_sessions", "id", "date", "check_in", "check_out", "work_hours", "note", "tags",
+		"auto_closed", "voided", "check_in_source", "check_out_source"), session.ID, session.Date, session.CheckIn, session.CheckOut, session.WorkHours, session.Note, string(tags),
+		session.AutoClosed, session.Voided, string(session.CheckInSource), string(session.CheckOutSource))
+	if err != nil {
 		return err
 	}
 
-	// Insert default config if not exists
-	_, err := s.db.Exec(`
-		INSERT OR IGNORE INTO work_config (
-			id, default_work_hours, check_in_api_url, auto_fetch_enabled,
-			authorization, p_auth, p_rtoken, check_in_webhook_url, check_out_webhook_url
-		) VALUES ('default', ?, '', FALSE, '', '', '', '', '')
-	`, domain.StandardWorkMinutes)
+	return saveBreaks(tx, session)
+}
 
-	return err
+// saveBreaks replaces the breaks of a session as a whole, the session owns them
+func saveBreaks(tx sqlTx, session *domain.WorkSession) error {
+	if _, err := tx.exec(`DELETE FROM work_breaks WHERE session_id = ?`, session.ID); err != nil {
+		return err
+	}
+	for _, b := range session.Breaks {
+		_, err := tx.exec(`
+			INSERT INTO work_breaks (id, session_id, start_time, end_time)
+			VALUES (?, ?, ?, ?)
+		`, b.ID, session.ID, b.Start, b.End)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// migrateConfigTable adds missing columns to the config table
-func (s *SQLiteStore) migrateConfigTable() error {
-	rows, err := s.db.Query("PRAGMA table_info(work_config)")
+// RecordPunch appends a punch to the log and saves the session it changed in one transaction,
+// a nil session only records the punch
+func (s *SQLStore) RecordPunch(ctx context.Context, punch *domain.Punch, session *domain.WorkSession) (err error) {
+	defer failed("RecordPunch", &err)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.tx.Rollback()
+
+	// Punches are never replaced, a duplicate ID is an error
+	_, err = tx.exec(`
+		INSERT INTO punches (id, kind, at, source, session_id, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, punch.ID, string(punch.Kind), punch.At, string(punch.Source), punch.SessionID, punch.RecordedAt)
 	if err != nil {
 		return err
 	}
+	if session != nil {
+		if err := saveSession(tx, session); err != nil {
+			return err
+		}
+	}
+
+	return tx.tx.Commit()
+}
+
+// GetPunches retrieves the punches between two dates (YYYY-MM-DD, inclusive, empty means unbounded)
+// in the order they were recorded
+func (s *SQLStore) GetPunches(ctx context.Context, from, to string) (_ []*domain.Punch, err error) {
+	defer failed("GetPunches", &err)
+	onDates, args, err := s.dialect.onDates("at", from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.query(ctx, `
+		SELECT id, kind, at, source, session_id, recorded_at
+		FROM punches
+		WHERE `+onDates+`
+		ORDER BY recorded_at ASC, `+s.dialect.insertionOrder("")+` ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	existingColumns := make(map[string]bool)
+	var punches []*domain.Punch
 	for rows.Next() {
-		var cid int
-		var name string
-		var dataType string
-		var notNull int
-		var defaultValue *string
-		var pk int
-
-		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
-			return err
+		var punch domain.Punch
+		var kind, source string
+		if err := rows.Scan(&punch.ID, &kind, &punch.At, &source, &punch.SessionID, &punch.RecordedAt); err != nil {
+			return nil, err
 		}
-		existingColumns[name] = true
+		punch.Kind = domain.PunchKind(kind)
+		punch.Source = domain.PunchSource(source)
+		punches = append(punches, &punch)
+	}
+
+	return punches, rows.Err()
+}
+
+// ReplaceSessions replaces every session with the derived ones in one transaction,
+// the notes and tags of sessions that remain are kept
+func (s *SQLStore) ReplaceSessions(ctx context.Context, sessions []*domain.WorkSession) (err error) {
+	defer failed("ReplaceSessions", &err)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.tx.Rollback()
+
+	if _, err := tx.exec(`CREATE TEMP TABLE IF NOT EXISTS derived_sessions (id TEXT PRIMARY KEY)`); err != nil {
+		return err
+	}
+	if _, err := tx.exec(`DELETE FROM derived_sessions`); err != nil {
+		return err
 	}
 
-	// Add missing columns
-	if !existingColumns["check_in_webhook_url"] {
-		if _, err := s.db.Exec("ALTER TABLE work_config ADD COLUMN check_in_webhook_url TEXT DEFAULT ''"); err != nil {
+	for _, session := range sessions {
+		_, err := tx.exec(`
+			INSERT INTO work_sessions (id, date, check_in, check_out, work_hours, auto_closed, voided,
+				check_in_source, check_out_source)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				date = excluded.date,
+				check_in = excluded.check_in,
+				check_out = excluded.check_out,
+				work_hours = excluded.work_hours,
+				auto_closed = excluded.auto_closed,
+				voided = excluded.voided,
+				check_in_source = excluded.check_in_source,
+				check_out_source = excluded.check_out_source
+		`, session.ID, session.Date, session.CheckIn, session.CheckOut, session.WorkHours, session.AutoClosed, session.Voided,
+			string(session.CheckInSource), string(session.CheckOutSource))
+		if err != nil {
+			return err
+		}
+		if err := saveBreaks(tx, session); err != nil {
+			return err
+		}
+		if _, err := tx.exec(`INSERT INTO derived_sessions (id) VALUES (?)`, session.ID); err != nil {
 			return err
 		}
 	}
 
-	if !existingColumns["check_out_webhook_url"] {
-		if _, err := s.db.Exec("ALTER TABLE work_config ADD COLUMN check_out_webhook_url TEXT DEFAULT ''"); err != nil {
+	// Sessions no punch derives any more, such as ones a rule change merged into another
+	if _, err := tx.exec(`
+		DELETE FROM work_breaks WHERE session_id NOT IN (SELECT id FROM derived_sessions)
+	`); err != nil {
+		return err
+	}
+	if _, err := tx.exec(`
+		DELETE FROM allocations WHERE session_id NOT IN (SELECT id FROM derived_sessions)
+	`); err != nil {
+		return err
+	}
+	if _, err := tx.exec(`
+		DELETE FROM work_sessions WHERE id NOT IN (SELECT id FROM derived_sessions)
+	`); err != nil {
+		return err
+	}
+
+	return tx.tx.Commit()
+}
+
+// SaveProject saves or updates a project
+func (s *SQLStore) SaveProject(ctx context.Context, project *domain.Project) (err error) {
+	defer failed("SaveProject", &err)
+	_, err = s.exec(ctx, upsert("projects", "id", "name", "code", "archived"),
+		project.ID, project.Name, project.Code, project.Archived)
+	return err
+}
+
+// DeleteProject deletes a project that no time is allocated to
+func (s *SQLStore) DeleteProject(ctx context.Context, id string) (err error) {
+	defer failed("DeleteProject", &err)
+	var allocations int
+	if err := s.queryRow(ctx, `SELECT COUNT(*) FROM allocations WHERE project_id = ?`, id).Scan(&allocations); err != nil {
+		return err
+	}
+	if allocations > 0 {
+		return domain.ErrProjectInUse
+	}
+
+	result, err := s.exec(ctx, `DELETE FROM projects WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrProjectNotFound
+	}
+	return nil
+}
+
+// GetProjects retrieves all projects ordered by name
+func (s *SQLStore) GetProjects(ctx context.Context) (_ []*domain.Project, err error) {
+	defer failed("GetProjects", &err)
+	rows, err := s.query(ctx, `
+		SELECT id, name, code, archived
+		FROM projects
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*domain.Project
+	for rows.Next() {
+		var project domain.Project
+		if err := rows.Scan(&project.ID, &project.Name, &project.Code, &project.Archived); err != nil {
+			return nil, err
+		}
+		projects = append(projects, &project)
+	}
+
+	return projects, rows.Err()
+}
+
+// SaveAllocations replaces the allocations of a session
+func (s *SQLStore) SaveAllocations(ctx context.Context, sessionID string, allocations []*domain.Allocation) (err error) {
+	defer failed("SaveAllocations", &err)
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.tx.Rollback()
+
+	if _, err := tx.exec(`DELETE FROM allocations WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	for _, a := range allocations {
+		_, err := tx.exec(`
+			INSERT INTO allocations (id, session_id, project_id, task, minutes, percent)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, a.ID, sessionID, a.ProjectID, a.Task, a.Minutes, a.Percent)
+		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return tx.tx.Commit()
 }
 
-// GetTodaySession retrieves the work session for a specific date
-func (s *SQLiteStore) GetTodaySession(date string) *domain.WorkSession {
-	var session domain.WorkSession
-	var checkOut sql.NullTime
+// GetAllocationsBySession retrieves the allocations of a session
+func (s *SQLStore) GetAllocationsBySession(ctx context.Context, sessionID string) (_ []*domain.Allocation, err error) {
+	defer failed("GetAllocationsBySession", &err)
+	return s.queryAllocations(ctx, `
+		SELECT id, session_id, project_id, task, minutes, percent
+		FROM allocations
+		WHERE session_id = ?
+		ORDER BY `+s.dialect.insertionOrder("")+` ASC
+	`, sessionID)
+}
 
-	row := s.db.QueryRow(`
-		SELECT id, date, check_in, check_out, work_hours
-		FROM work_sessions
-		WHERE date = ?
-	`, date)
+// GetAllocationsByDateRange retrieves the allocations of sessions between two dates (YYYY-MM-DD, inclusive)
+func (s *SQLStore) GetAllocationsByDateRange(ctx context.Context, from, to string) (_ []*domain.Allocation, err error) {
+	defer failed("GetAllocationsByDateRange", &err)
+	return s.queryAllocations(ctx, `
+		SELECT a.id, a.session_id, a.project_id, a.task, a.minutes, a.percent
+		FROM allocations a
+		JOIN work_sessions ws ON ws.id = a.session_id
+		WHERE ws.date >= ? AND ws.date <= ? AND NOT ws.voided
+		ORDER BY ws.date ASC, ws.check_in ASC, `+s.dialect.insertionOrder("a")+` ASC
+	`, from, to)
+}
 
-	err := row.Scan(&session.ID, &session.Date, &session.CheckIn, &checkOut, &session.WorkHours)
+// queryAllocations runs an allocation query
+func (s *SQLStore) queryAllocations(ctx context.Context, query string, args ...any) ([]*domain.Allocation, error) {
+	rows, err := s.query(ctx, query, args...)
 	if err != nil {
-		return nil
+		return nil, err
 	}
+	defer rows.Close()
 
-	if checkOut.Valid {
-		session.CheckOut = &checkOut.Time
+	var allocations []*domain.Allocation
+	for rows.Next() {
+		var a domain.Allocation
+		if err := rows.Scan(&a.ID, &a.SessionID, &a.ProjectID, &a.Task, &a.Minutes, &a.Percent); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, &a)
 	}
 
-	return &session
+	return allocations, rows.Err()
 }
 
-// GetSessionsByMonth retrieves all work sessions for a specific month (YYYY-MM format)
-func (s *SQLiteStore) GetSessionsByMonth(yearMonth string) ([]*domain.WorkSession, error) {
-	rows, err := s.db.Query(`
-		SELECT id, date, check_in, check_out, work_hours
-		FROM work_sessions
+// GetAbsence retrieves an absence by ID
+func (s *SQLStore) GetAbsence(ctx context.Context, id string) (_ *domain.Absence, err error) {
+	defer failed("GetAbsence", &err)
+	absences, err := s.queryAbsences(ctx, `
+		SELECT id, date, type, amount, note
+		FROM absences
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(absences) == 0 {
+		return nil, domain.ErrAbsenceNotFound
+	}
+	return absences[0], nil
+}
+
+// SaveAbsence saves or updates an absence
+func (s *SQLStore) SaveAbsence(ctx context.Context, absence *domain.Absence) (err error) {
+	defer failed("SaveAbsence", &err)
+	_, err = s.exec(ctx, upsert("absences", "id", "date", "type", "amount", "note"),
+		absence.ID, absence.Date, string(absence.Type), string(absence.Amount), absence.Note)
+	return err
+}
+
+// DeleteAbsence deletes an absence by ID
+func (s *SQLStore) DeleteAbsence(ctx context.Context, id string) (err error) {
+	defer failed("DeleteAbsence", &err)
+	result, err := s.exec(ctx, `DELETE FROM absences WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrAbsenceNotFound
+	}
+	return nil
+}
+
+// GetAbsencesByDate retrieves the absences of a specific date
+func (s *SQLStore) GetAbsencesByDate(ctx context.Context, date string) (_ []*domain.Absence, err error) {
+	defer failed("GetAbsencesByDate", &err)
+	return s.queryAbsences(ctx, `
+		SELECT id, date, type, amount, note
+		FROM absences
+		WHERE date = ?
+		ORDER BY id ASC
+	`, date)
+}
+
+// GetAbsencesByMonth retrieves all absences for a specific month (YYYY-MM format)
+func (s *SQLStore) GetAbsencesByMonth(ctx context.Context, yearMonth string) (_ []*domain.Absence, err error) {
+	defer failed("GetAbsencesByMonth", &err)
+	return s.queryAbsences(ctx, `
+		SELECT id, date, type, amount, note
+		FROM absences
 		WHERE date LIKE ?
-		ORDER BY date ASC
+		ORDER BY date ASC, id ASC
 	`, yearMonth+"%")
+}
+
+// GetAbsencesByDateRange retrieves the absences between two dates (YYYY-MM-DD, inclusive)
+func (s *SQLStore) GetAbsencesByDateRange(ctx context.Context, from, to string) (_ []*domain.Absence, err error) {
+	defer failed("GetAbsencesByDateRange", &err)
+	return s.queryAbsences(ctx, `
+		SELECT id, date, type, amount, note
+		FROM absences
+		WHERE date >= ? AND date <= ?
+		ORDER BY date ASC, id ASC
+	`, from, to)
+}
+
+// queryAbsences runs an absence query
+func (s *SQLStore) queryAbsences(ctx context.Context, query string, args ...any) ([]*domain.Absence, error) {
+	rows, err := s.query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var sessions []*domain.WorkSession
+	var absences []*domain.Absence
 	for rows.Next() {
-		var session domain.WorkSession
-		var checkOut sql.NullTime
+		var absence domain.Absence
+		var absenceType, amount string
 
-		err := rows.Scan(&session.ID, &session.Date, &session.CheckIn, &checkOut, &session.WorkHours)
-		if err != nil {
+		if err := rows.Scan(&absence.ID, &absence.Date, &absenceType, &amount, &absence.Note); err != nil {
 			return nil, err
 		}
 
-		if checkOut.Valid {
-			session.CheckOut = &checkOut.Time
+		absence.Type = domain.AbsenceType(absenceType)
+		absence.Amount = domain.AbsenceAmount(amount)
+		absences = append(absences, &absence)
+	}
+
+	return absences, rows.Err()
+}
+
+// SaveTimeBankEntry saves or updates a time bank entry
+func (s *SQLStore) SaveTimeBankEntry(ctx context.Context, entry *domain.TimeBankEntry) (err error) {
+	defer failed("SaveTimeBankEntry", &err)
+	_, err = s.exec(ctx, upsert("time_bank_entries", "id", "date", "kind", "minutes", "note", "created_at"),
+		entry.ID, entry.Date, string(entry.Kind), entry.Minutes, entry.Note, entry.CreatedAt)
+	return err
+}
+
+// DeleteTimeBankEntry deletes a time bank entry by ID
+func (s *SQLStore) DeleteTimeBankEntry(ctx context.Context, id string) (err error) {
+	defer failed("DeleteTimeBankEntry", &err)
+	result, err := s.exec(ctx, `DELETE FROM time_bank_entries WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTimeBankEntryNotFound
+	}
+	return nil
+}
+
+// GetTimeBankEntries retrieves time bank entries between two dates, an empty bound is open
+func (s *SQLStore) GetTimeBankEntries(ctx context.Context, from, to string) (_ []*domain.TimeBankEntry, err error) {
+	defer failed("GetTimeBankEntries", &err)
+	rows, err := s.query(ctx, `
+		SELECT id, date, kind, minutes, note, created_at
+		FROM time_bank_entries
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date ASC, created_at ASC
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.TimeBankEntry
+	for rows.Next() {
+		var entry domain.TimeBankEntry
+		var kind string
+
+		if err := rows.Scan(&entry.ID, &entry.Date, &kind, &entry.Minutes, &entry.Note, &entry.CreatedAt); err != nil {
+			return nil, err
 		}
 
-		sessions = append(sessions, &session)
+		entry.Kind = domain.TimeBankEntryKind(kind)
+		entries = append(entries, &entry)
 	}
 
-	return sessions, nil
+	return entries, rows.Err()
 }
 
-// SaveSession saves or updates a work session
-func (s *SQLiteStore) SaveSession(session *domain.WorkSession) error {
-	_, err := s.db.Exec(`
-		INSERT OR REPLACE INTO work_sessions (id, date, check_in, check_out, work_hours)
-		VALUES (?, ?, ?, ?, ?)
-	`, session.ID, session.Date, session.CheckIn, session.CheckOut, session.WorkHours)
-	return err
+// SumTimeBankMinutes sums the minutes of all entries dated before a date, empty sums everything
+func (s *SQLStore) SumTimeBankMinutes(ctx context.Context, before string) (_ int, err error) {
+	defer failed("SumTimeBankMinutes", &err)
+	var total int
+	err = s.queryRow(ctx, `
+		SELECT COALESCE(SUM(minutes), 0)
+		FROM time_bank_entries
+		WHERE ? = '' OR date < ?
+	`, before, before).Scan(&total)
+	return total, err
 }
 
 // GetConfig retrieves the work configuration
-func (s *SQLiteStore) GetConfig() (*domain.WorkConfig, error) {
+func (s *SQLStore) GetConfig(ctx context.Context) (_ *domain.WorkConfig, err error) {
+	defer failed("GetConfig", &err)
 	var config domain.WorkConfig
-	row := s.db.QueryRow(`
+	var overtimeMode string
+	var weekdayThresholds string
+	var weeklySchedule string
+	var workCycle string
+	var balanceMode string
+	var roundingCheckIn, roundingCheckOut string
+	var checkOutMode string
+	var sourcePrecedence string
+	row := s.queryRow(ctx, `
 		SELECT id, default_work_hours, check_in_api_url, auto_fetch_enabled,
-		       p_auth, p_rtoken, check_in_webhook_url, check_out_webhook_url
+		       p_auth, p_rtoken, check_in_webhook_url, check_out_webhook_url,
+		       overtime_mode, overtime_threshold_minutes, overtime_weekday_thresholds, overtime_grace_minutes,
+		       day_rollover_hour, weekly_schedule, work_cycle, overtime_hourly_rate,
+		       compliance_max_monthly_overtime_minutes, compliance_max_weekly_work_minutes,
+		       compliance_min_rest_minutes, compliance_warning_percent,
+		       balance_mode, balance_max_carry_over_minutes,
+		       rounding_interval_minutes, rounding_check_in, rounding_check_out, rounding_grace_minutes,
+		       core_start, core_end, check_in_reminder_lead_minutes,
+		       check_out_mode, check_out_earliest_end, check_out_latest_start,
+		       source_precedence
 		FROM work_config
 		WHERE id = 'default'
 	`)
 
-	err := row.Scan(
+	err = row.Scan(
 		&config.ID,
 		&config.DefaultWorkHours,
 		&config.CheckInAPIURL,
@@ -205,23 +816,88 @@ func (s *SQLiteStore) GetConfig() (*domain.WorkConfig, error) {
 		&config.PRToken,
 		&config.CheckInWebhookURL,
 		&config.CheckOutWebhookURL,
+		&overtimeMode,
+		&config.OvertimePolicy.ThresholdMinutes,
+		&weekdayThresholds,
+		&config.OvertimePolicy.GraceMinutes,
+		&config.DayRolloverHour,
+		&weeklySchedule,
+		&workCycle,
+		&config.OvertimeHourlyRate,
+		&config.ComplianceRules.MaxMonthlyOvertimeMinutes,
+		&config.ComplianceRules.MaxWeeklyWorkMinutes,
+		&config.ComplianceRules.MinRestMinutes,
+		&config.ComplianceRules.WarningPercent,
+		&balanceMode,
+		&config.BalancePolicy.MaxCarryOverMinutes,
+		&config.RoundingPolicy.IntervalMinutes,
+		&roundingCheckIn,
+		&roundingCheckOut,
+		&config.RoundingPolicy.GraceMinutes,
+		&config.CoreHours.Start,
+		&config.CoreHours.End,
+		&config.CheckInReminderLeadMinutes,
+		&checkOutMode,
+		&config.CheckOutRule.EarliestEnd,
+		&config.CheckOutRule.LatestStart,
+		&sourcePrecedence,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrConfigNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	config.OvertimePolicy.Mode = domain.OvertimeMode(overtimeMode)
+	config.BalancePolicy.Mode = domain.BalanceMode(balanceMode)
+	config.RoundingPolicy.CheckIn = domain.RoundingDirection(roundingCheckIn)
+	config.RoundingPolicy.CheckOut = domain.RoundingDirection(roundingCheckOut)
+	config.CheckOutRule.Mode = domain.CheckOutMode(checkOutMode)
+	if err := unmarshalJSONColumn(weekdayThresholds, &config.OvertimePolicy.WeekdayThresholds); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONColumn(weeklySchedule, &config.WeeklySchedule); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONColumn(workCycle, &config.WorkCycle); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONColumn(sourcePrecedence, &config.SourcePrecedence); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
 // SaveConfig saves or updates the work configuration
-func (s *SQLiteStore) SaveConfig(config *domain.WorkConfig) error {
-	_, err := s.db.Exec(`
-		INSERT OR REPLACE INTO work_config (
-			id, default_work_hours, check_in_api_url, auto_fetch_enabled,
-			p_auth, p_rtoken, check_in_webhook_url, check_out_webhook_url
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`,
-		config.ID,
+func (s *SQLStore) SaveConfig(ctx context.Context, config *domain.WorkConfig) (err error) {
+	defer failed("SaveConfig", &err)
+	weekdayThresholds, err := json.Marshal(config.OvertimePolicy.WeekdayThresholds)
+	if err != nil {
+		return err
+	}
+	weeklySchedule, err := json.Marshal(config.WeeklySchedule)
+	if err != nil {
+		return err
+	}
+	workCycle, err := json.Marshal(config.WorkCycle)
+	if err != nil {
+		return err
+	}
+	sourcePrecedence, err := json.Marshal(config.SourcePrecedence)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.exec(ctx, upsert("work_config", "id", "default_work_hours", "check_in_api_url", "auto_fetch_enabled", "p_auth",
+		"p_rtoken", "check_in_webhook_url", "check_out_webhook_url", "overtime_mode", "overtime_threshold_minutes",
+		"overtime_weekday_thresholds", "overtime_grace_minutes", "day_rollover_hour", "weekly_schedule", "work_cycle",
+		"overtime_hourly_rate", "compliance_max_monthly_overtime_minutes", "compliance_max_weekly_work_minutes",
+		"compliance_min_rest_minutes", "compliance_warning_percent", "balance_mode", "balance_max_carry_over_minutes",
+		"rounding_interval_minutes", "rounding_check_in", "rounding_check_out", "rounding_grace_minutes", "core_start",
+		"core_end", "check_in_reminder_lead_minutes", "check_out_mode", "check_out_earliest_end", "check_out_latest_start",
+		"source_precedence"), config.ID,
 		config.DefaultWorkHours,
 		config.CheckInAPIURL,
 		config.AutoFetchEnabled,
@@ -229,6 +905,39 @@ func (s *SQLiteStore) SaveConfig(config *domain.WorkConfig) error {
 		config.PRToken,
 		config.CheckInWebhookURL,
 		config.CheckOutWebhookURL,
+		string(config.OvertimePolicy.Mode),
+		config.OvertimePolicy.ThresholdMinutes,
+		string(weekdayThresholds),
+		config.OvertimePolicy.GraceMinutes,
+		config.DayRolloverHour,
+		string(weeklySchedule),
+		string(workCycle),
+		config.OvertimeHourlyRate,
+		config.ComplianceRules.MaxMonthlyOvertimeMinutes,
+		config.ComplianceRules.MaxWeeklyWorkMinutes,
+		config.ComplianceRules.MinRestMinutes,
+		config.ComplianceRules.WarningPercent,
+		string(config.BalancePolicy.Mode),
+		config.BalancePolicy.MaxCarryOverMinutes,
+		config.RoundingPolicy.IntervalMinutes,
+		string(config.RoundingPolicy.CheckIn),
+		string(config.RoundingPolicy.CheckOut),
+		config.RoundingPolicy.GraceMinutes,
+		config.CoreHours.Start,
+		config.CoreHours.End,
+		config.CheckInReminderLeadMinutes,
+		string(config.CheckOutRule.Mode),
+		config.CheckOutRule.EarliestEnd,
+		config.CheckOutRule.LatestStart,
+		string(sourcePrecedence),
 	)
 	return err
 }
+
+// unmarshalJSONColumn decodes a JSON text column, an empty column leaves the target unchanged
+func unmarshalJSONColumn(value string, target any) error {
+	if value == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(value), target)
+}
@@ -0,0 +1,18 @@
+package persistence
+
+import (
+	"database/sql"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+)
+
+// NewSQLiteStore creates a store on the SQLite file at the path and migrates it to the latest schema
+// The driver is chosen at build time, see SQLiteDriver
+func NewSQLiteStore(dbPath string) (domain.Repository, error) {
+	return NewStore(dbPath)
+}
+
+// openSQLite opens the SQLite file at the path with the driver the binary was built with
+func openSQLite(dbPath string) (*sql.DB, error) {
+	return sql.Open(SQLiteDriver, sqliteDSN(dbPath))
+}
@@ -0,0 +1,24 @@
+//go:build cgo && !purego
+
+package persistence
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLiteDriver is the database/sql driver of SQLite files, mattn/go-sqlite3 when built with CGO
+const SQLiteDriver = "sqlite3"
+
+// sqliteDSN returns the data source name of the SQLite file at the path
+func sqliteDSN(dbPath string) string {
+	return dbPath
+}
+
+// isSQLiteUniqueViolation returns true if the error is SQLite refusing a duplicate key
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey || sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique)
+}
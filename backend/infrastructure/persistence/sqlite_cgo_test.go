@@ -0,0 +1,65 @@
+//go:build cgo && !purego
+
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/simon0-o/offline_me/backend/domain"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+// TestSQLiteDriversShareFiles checks that a file written by the CGO build can be used by the pure Go one and back,
+// so switching builds needs no data migration
+func TestSQLiteDriversShareFiles(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "worktime.db")
+	checkIn := time.Date(2025, 10, 13, 9, 0, 0, 0, time.Local)
+	checkOut := checkIn.Add(8 * time.Hour)
+
+	repo, err := NewSQLiteStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.RecordPunch(ctx,
+		&domain.Punch{ID: "p1", Kind: domain.PunchCheckIn, At: checkIn, Source: domain.PunchSourceManual, RecordedAt: checkIn},
+		&domain.WorkSession{ID: "s1", Date: "2025-10-13", CheckIn: checkIn, WorkHours: 480, CheckInSource: domain.PunchSourceManual},
+	))
+	assert.NoError(t, repo.Close())
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_time_format=sqlite&_pragma=busy_timeout(5000)")
+	assert.NoError(t, err)
+	pure := &SQLStore{db: db, dialect: DialectSQLite}
+	version, err := NewMigrator(db, DialectSQLite).Version()
+	assert.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), version)
+
+	session, err := pure.GetSession(ctx, "s1")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, checkIn, session.CheckIn, 0)
+	punches, err := pure.GetPunches(ctx, "2025-10-13", "2025-10-13")
+	assert.NoError(t, err)
+	assert.Len(t, punches, 1)
+
+	session.CheckOut = &checkOut
+	session.CheckOutSource = domain.PunchSourceManual
+	assert.NoError(t, pure.RecordPunch(ctx,
+		&domain.Punch{ID: "p2", Kind: domain.PunchCheckOut, At: checkOut, Source: domain.PunchSourceManual, RecordedAt: checkOut},
+		session,
+	))
+	assert.NoError(t, pure.Close())
+
+	repo, err = NewSQLiteStore(path)
+	assert.NoError(t, err)
+	defer repo.Close()
+	sessions, err := repo.GetSessionsByDate(ctx, "2025-10-13")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.WithinDuration(t, checkOut, *sessions[0].CheckOut, 0)
+	punches, err = repo.GetPunches(ctx, "2025-10-13", "2025-10-13")
+	assert.NoError(t, err)
+	assert.Len(t, punches, 2)
+}
@@ -0,0 +1,28 @@
+//go:build !cgo || purego
+
+package persistence
+
+import (
+	"errors"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteDriver is the database/sql driver of SQLite files, the pure Go modernc.org/sqlite when built
+// with CGO_ENABLED=0 or the purego tag
+const SQLiteDriver = "sqlite"
+
+// sqliteDSN returns the data source name of the SQLite file at the path
+// Times are written in the format mattn/go-sqlite3 uses and busy connections wait as long as with it,
+// so the same file can be opened by binaries built either way
+func sqliteDSN(dbPath string) string {
+	return "file:" + dbPath + "?_time_format=sqlite&_pragma=busy_timeout(5000)"
+}
+
+// isSQLiteUniqueViolation returns true if the error is SQLite refusing a duplicate key
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE)
+}
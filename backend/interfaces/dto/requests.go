@@ -12,6 +12,16 @@ type CheckOutRequest struct {
 	CheckOutTime time.Time `json:"check_out_time"`
 }
 
+// BreakStartRequest represents a request to begin a break
+type BreakStartRequest struct {
+	BreakStartTime time.Time `json:"break_start_time"`
+}
+
+// BreakEndRequest represents a request to end the current break
+type BreakEndRequest struct {
+	BreakEndTime time.Time `json:"break_end_time"`
+}
+
 // ConfigRequest represents a configuration update request
 type ConfigRequest struct {
 	WorkHours          int    `json:"work_hours"` // in minutes
@@ -21,6 +31,72 @@ type ConfigRequest struct {
 	PRToken            string `json:"p_rtoken"`
 	CheckInWebhookURL  string `json:"check_in_webhook_url"`
 	CheckOutWebhookURL string `json:"check_out_webhook_url"`
+
+	OvertimePolicy  *OvertimePolicy `json:"overtime_policy,omitempty"`   // nil keeps the current policy
+	DayRolloverHour *int            `json:"day_rollover_hour,omitempty"` // 0-23, nil keeps the current value
+	WeeklySchedule  *[]int          `json:"weekly_schedule,omitempty"`   // 7 values starting on Sunday, 0 is a day off; empty removes it
+	WorkCycle       *WorkCycle      `json:"work_cycle,omitempty"`        // nil keeps the current cycle, no weeks removes it
+
+	OvertimeHourlyRate *float64         `json:"overtime_hourly_rate,omitempty"` // nil keeps the current rate
+	ComplianceRules    *ComplianceRules `json:"compliance_rules,omitempty"`     // nil keeps the current rules
+	BalancePolicy      *BalancePolicy   `json:"balance_policy,omitempty"`       // nil keeps the current policy
+	RoundingPolicy     *RoundingPolicy  `json:"rounding_policy,omitempty"`      // nil keeps the current policy
+
+	CoreHours                  *CoreHours `json:"core_hours,omitempty"`                     // nil keeps the current core hours, empty times remove them
+	CheckInReminderLeadMinutes *int       `json:"check_in_reminder_lead_minutes,omitempty"` // remind this long before the core start, 0 uses the fixed 9:55 reminder
+
+	CheckOutRule *CheckOutRule `json:"check_out_rule,omitempty"` // nil keeps the current rule
+
+	SourcePrecedence []string `json:"source_precedence,omitempty"` // every punch source from the highest ranked to the lowest, nil keeps the current policy
+}
+
+// CheckOutRule represents how the expected check-out of a regular workday is decided
+type CheckOutRule struct {
+	Mode        string `json:"mode"`                   // "duration", "fixed_end" or "max"
+	EarliestEnd string `json:"earliest_end,omitempty"` // HH:MM, used by fixed_end and max
+	LatestStart string `json:"latest_start,omitempty"` // HH:MM, a fixed_end day checked in after it also has to reach the work hours
+}
+
+// CoreHours represents the flexi-time core hours lateness is measured against
+type CoreHours struct {
+	Start string `json:"start"` // HH:MM
+	End   string `json:"end"`   // HH:MM
+}
+
+// RoundingPolicy represents how punches are rounded for worked minutes and overtime
+type RoundingPolicy struct {
+	IntervalMinutes int    `json:"interval_minutes"` // 0 disables rounding
+	CheckIn         string `json:"check_in"`         // "none", "up", "down" or "nearest"
+	CheckOut        string `json:"check_out"`        // "none", "up", "down" or "nearest"
+	GraceMinutes    int    `json:"grace_minutes"`    // a punch this close to a boundary snaps to it
+}
+
+// BalancePolicy represents how earlier days' surplus or deficit moves today's leave time
+type BalancePolicy struct {
+	Mode                string `json:"mode"`                   // "off", "month" or "week"
+	MaxCarryOverMinutes int    `json:"max_carry_over_minutes"` // cap of the carry-over either way, 0 is unlimited
+}
+
+// ComplianceRules represents the labor-law limits to warn about, a limit of 0 disables its rule
+type ComplianceRules struct {
+	MaxMonthlyOvertimeMinutes int `json:"max_monthly_overtime_minutes"`
+	MaxWeeklyWorkMinutes      int `json:"max_weekly_work_minutes"`
+	MinRestMinutes            int `json:"min_rest_minutes"` // rest between check-out and the next workday's check-in
+	WarningPercent            int `json:"warning_percent"`  // warn once this share of a limit is used
+}
+
+// WorkCycle represents a rotation of work weeks such as alternating 5-day and 6-day weeks
+type WorkCycle struct {
+	AnchorDate string  `json:"anchor_date"` // YYYY-MM-DD, any day in the first week of the cycle
+	Weeks      [][]int `json:"weeks"`       // working weekdays of each week, 0 is Sunday
+}
+
+// OvertimePolicy represents how overtime is counted
+type OvertimePolicy struct {
+	Mode              string `json:"mode"`                         // "fixed", "relative" or "weekday"
+	ThresholdMinutes  int    `json:"threshold_minutes"`            // used by fixed mode
+	WeekdayThresholds []int  `json:"weekday_thresholds,omitempty"` // used by weekday mode, 7 values starting on Sunday
+	GraceMinutes      int    `json:"grace_minutes"`                // overtime up to this many minutes is not counted
 }
 
 // TodayCheckInRequest represents a request to get/auto-fetch today's check-in
@@ -28,3 +104,69 @@ type TodayCheckInRequest struct {
 	Date      string `json:"date"` // YYYY-MM-DD format
 	ReCheckIn bool   `json:"re_check_in"`
 }
+
+// AbsenceRequest represents a request to create or update an absence
+type AbsenceRequest struct {
+	ID     string `json:"id,omitempty"` // empty creates a new absence
+	Date   string `json:"date"`         // YYYY-MM-DD format
+	Type   string `json:"type"`         // "annual_leave", "sick_leave", "personal_leave" or "business_trip"
+	Amount string `json:"amount"`       // "full_day" (default) or "half_day"
+	Note   string `json:"note"`
+}
+
+// TimeBankEntryRequest represents a manual time bank adjustment or comp-off
+type TimeBankEntryRequest struct {
+	Date    string `json:"date"`    // YYYY-MM-DD format, defaults to today
+	Kind    string `json:"kind"`    // "adjustment" or "comp_off"
+	Minutes int    `json:"minutes"` // signed for adjustments, time taken for comp-off
+	Note    string `json:"note"`
+}
+
+// ProjectRequest represents a request to create or update a project
+type ProjectRequest struct {
+	ID       string `json:"id,omitempty"` // empty creates a new project
+	Name     string `json:"name"`
+	Code     string `json:"code"`
+	Archived bool   `json:"archived"` // hidden when allocating, kept for reporting
+}
+
+// AllocationsRequest represents a request to replace the time allocations of a session
+type AllocationsRequest struct {
+	SessionID   string              `json:"session_id"`
+	Allocations []AllocationRequest `json:"allocations"` // empty removes all allocations
+}
+
+// AllocationRequest represents part of a session's worked time spent on a project
+type AllocationRequest struct {
+	ProjectID string  `json:"project_id"`
+	Task      string  `json:"task"`
+	Minutes   int     `json:"minutes,omitempty"` // set either minutes
+	Percent   float64 `json:"percent,omitempty"` // or a percentage of the worked minutes
+}
+
+// SessionNoteRequest represents a request to replace the note and tags of a session
+type SessionNoteRequest struct {
+	SessionID string   `json:"session_id"`
+	Note      string   `json:"note"` // markdown
+	Tags      []string `json:"tags"` // stored lowercase, spaces become dashes
+}
+
+// VoidSessionRequest represents a request to void a session recorded by mistake
+type VoidSessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// PunchRequest represents a clock event received from an inbound webhook
+type PunchRequest struct {
+	Kind string    `json:"kind"` // check_in, check_out, break_start or break_end
+	Time time.Time `json:"time"`
+}
+
+// SessionSearchRequest represents the filters of a session search
+type SessionSearchRequest struct {
+	From               string // YYYY-MM-DD, defaults to the first day of the current month
+	To                 string // YYYY-MM-DD, defaults to today
+	Tag                string
+	Text               string // matched against the note and the tags
+	MinOvertimeMinutes int    // minimum overtime of the session's day
+}
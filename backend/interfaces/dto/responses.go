@@ -4,18 +4,32 @@ import "time"
 
 // CheckInResponse represents a check-in API response
 type CheckInResponse struct {
-	SessionID    string    `json:"session_id"`
-	CheckInTime  time.Time `json:"check_in_time"`
-	CheckOutTime time.Time `json:"expected_check_out_time"`
-	WorkHours    int       `json:"work_hours"` // in minutes
+	SessionID          string    `json:"session_id"`
+	CheckInTime        time.Time `json:"check_in_time"`
+	RoundedCheckInTime time.Time `json:"rounded_check_in_time"` // check-in after the rounding policy
+	CheckOutTime       time.Time `json:"expected_check_out_time"`
+	WorkHours          int       `json:"work_hours"`   // in minutes
+	LateMinutes        int       `json:"late_minutes"` // minutes after the core start, 0 if on time
 }
 
 // CheckOutResponse represents a check-out API response
 type CheckOutResponse struct {
-	SessionID       string    `json:"session_id"`
-	CheckInTime     time.Time `json:"check_in_time"`
-	CheckOutTime    time.Time `json:"check_out_time"`
-	OvertimeMinutes int       `json:"overtime_minutes"`
+	SessionID           string    `json:"session_id"`
+	CheckInTime         time.Time `json:"check_in_time"`
+	CheckOutTime        time.Time `json:"check_out_time"`
+	RoundedCheckInTime  time.Time `json:"rounded_check_in_time"` // punches after the rounding policy
+	RoundedCheckOutTime time.Time `json:"rounded_check_out_time"`
+	WorkedMinutes       int       `json:"worked_minutes"`   // total worked today across sessions
+	OvertimeMinutes     int       `json:"overtime_minutes"` // overtime of the whole day
+}
+
+// BreakResponse represents a break start/end API response
+type BreakResponse struct {
+	SessionID        string     `json:"session_id"`
+	BreakStartTime   time.Time  `json:"break_start_time"`
+	BreakEndTime     *time.Time `json:"break_end_time,omitempty"`
+	BreakMinutes     int        `json:"break_minutes"` // total break time today
+	ExpectedCheckOut time.Time  `json:"expected_check_out_time"`
 }
 
 // ConfigResponse represents a configuration response
@@ -27,18 +41,75 @@ type ConfigResponse struct {
 	PRToken            string `json:"p_rtoken"`
 	CheckInWebhookURL  string `json:"check_in_webhook_url"`
 	CheckOutWebhookURL string `json:"check_out_webhook_url"`
+
+	OvertimePolicy  OvertimePolicy `json:"overtime_policy"`
+	DayRolloverHour int            `json:"day_rollover_hour"` // punches before this hour belong to the previous workday
+	WeeklySchedule  []int          `json:"weekly_schedule"`   // 7 values starting on Sunday, null uses work_hours every day
+	WorkCycle       *WorkCycle     `json:"work_cycle"`        // null works the same days every week
+
+	OvertimeHourlyRate float64         `json:"overtime_hourly_rate"` // base hourly wage that overtime pay multiplies
+	ComplianceRules    ComplianceRules `json:"compliance_rules"`
+	BalancePolicy      BalancePolicy   `json:"balance_policy"`
+	RoundingPolicy     RoundingPolicy  `json:"rounding_policy"`
+
+	CoreHours                  *CoreHours `json:"core_hours"` // null when not configured
+	CheckInReminderLeadMinutes int        `json:"check_in_reminder_lead_minutes"`
+
+	CheckOutRule CheckOutRule `json:"check_out_rule"`
+
+	SourcePrecedence []string `json:"source_precedence"` // punch sources from the highest ranked to the lowest
 }
 
 // StatusResponse represents the current work status
 type StatusResponse struct {
-	HasCheckedIn     bool       `json:"has_checked_in"`
-	CheckInTime      *time.Time `json:"check_in_time,omitempty"`
-	CheckOutTime     *time.Time `json:"check_out_time,omitempty"`
-	ExpectedCheckOut *time.Time `json:"expected_check_out_time,omitempty"`
-	CurrentTime      time.Time  `json:"current_time"`
-	WorkHours        int        `json:"work_hours"` // in minutes
-	IsCheckOutTime   bool       `json:"is_check_out_time"`
-	OvertimeMinutes  int        `json:"overtime_minutes"`
+	HasCheckedIn        bool              `json:"has_checked_in"`
+	CheckInTime         *time.Time        `json:"check_in_time,omitempty"`
+	CheckOutTime        *time.Time        `json:"check_out_time,omitempty"`
+	RoundedCheckInTime  *time.Time        `json:"rounded_check_in_time,omitempty"` // punches after the rounding policy
+	RoundedCheckOutTime *time.Time        `json:"rounded_check_out_time,omitempty"`
+	ExpectedCheckOut    *time.Time        `json:"expected_check_out_time,omitempty"`
+	CurrentTime         time.Time         `json:"current_time"`
+	WorkHours           int               `json:"work_hours"` // in minutes
+	IsCheckOutTime      bool              `json:"is_check_out_time"`
+	OvertimeMinutes     int               `json:"overtime_minutes"`
+	IsOnBreak           bool              `json:"is_on_break"`
+	BreakStartTime      *time.Time        `json:"break_start_time,omitempty"`        // start of the break in progress
+	BreakMinutes        int               `json:"break_minutes"`                     // total break time today
+	WorkedMinutes       int               `json:"worked_minutes"`                    // total worked in checked-out sessions today
+	Sessions            []SessionSummary  `json:"sessions,omitempty"`                // today's sessions ordered by check-in
+	Compliance          []ComplianceIssue `json:"compliance,omitempty"`              // labor-law limits close to or beyond being breached
+	CarryOverMinutes    int               `json:"carry_over_minutes"`                // earlier days' surplus (positive) or deficit (negative)
+	BalancedCheckOut    *time.Time        `json:"balanced_check_out_time,omitempty"` // leave time that evens out the carry-over, set when a balance mode is on
+	Unclosed            []UnclosedSession `json:"unclosed,omitempty"`                // sessions of earlier workdays never checked out, to be corrected
+}
+
+// UnclosedSession represents a session of an earlier workday that was never checked out
+type UnclosedSession struct {
+	SessionID   string    `json:"session_id"`
+	Date        string    `json:"date"`
+	CheckInTime time.Time `json:"check_in_time"`
+}
+
+// SessionSummary represents one session of a day with split shifts
+type SessionSummary struct {
+	SessionID           string     `json:"session_id"`
+	State               string     `json:"state"` // open, on_break, closed or auto_closed
+	CheckInTime         time.Time  `json:"check_in_time"`
+	CheckOutTime        *time.Time `json:"check_out_time,omitempty"`
+	CheckInSource       string     `json:"check_in_source"`            // manual, hr_auto_fetch, scheduler_sync or webhook
+	CheckOutSource      string     `json:"check_out_source,omitempty"` // empty until checked out
+	RoundedCheckInTime  time.Time  `json:"rounded_check_in_time"`      // punches after the rounding policy
+	RoundedCheckOutTime *time.Time `json:"rounded_check_out_time,omitempty"`
+	WorkedMinutes       int        `json:"worked_minutes"`
+	BreakMinutes        int        `json:"break_minutes"`
+
+	IsLate                bool `json:"is_late"` // checked in after the core start
+	LateMinutes           int  `json:"late_minutes"`
+	IsEarlyDeparture      bool `json:"is_early_departure"` // checked out before the core end
+	EarlyDepartureMinutes int  `json:"early_departure_minutes"`
+
+	Note string   `json:"note,omitempty"`
+	Tags []string `json:"tags,omitempty"`
 }
 
 // TodayCheckInResponse represents a response for today's check-in status
@@ -60,6 +131,243 @@ type MonthlyStatsResponse struct {
 type MonthStats struct {
 	YearMonth       string `json:"year_month"`
 	TotalDays       int    `json:"total_days"`
+	TotalSessions   int    `json:"total_sessions"`
 	CheckedOutDays  int    `json:"checked_out_days"`
+	WorkMinutes     int    `json:"work_minutes"`
 	OvertimeMinutes int    `json:"overtime_minutes"`
+
+	AttendedDays     int     `json:"attended_days"`
+	AbsentDays       float64 `json:"absent_days"` // a half-day counts as 0.5
+	ExpectedWorkdays int     `json:"expected_workdays"`
+
+	WeekendOvertimeMinutes int `json:"weekend_overtime_minutes"` // part of overtime_minutes worked on days off
+
+	LateArrivals          int `json:"late_arrivals"` // sessions checked in after the core start
+	LateMinutes           int `json:"late_minutes"`
+	EarlyDepartures       int `json:"early_departures"` // sessions checked out before the core end
+	EarlyDepartureMinutes int `json:"early_departure_minutes"`
+}
+
+// AbsenceResponse represents a single absence
+type AbsenceResponse struct {
+	ID     string  `json:"id"`
+	Date   string  `json:"date"`
+	Type   string  `json:"type"`
+	Amount string  `json:"amount"`
+	Days   float64 `json:"days"`
+	Note   string  `json:"note"`
+}
+
+// AbsenceListResponse represents the absences of a month
+type AbsenceListResponse struct {
+	YearMonth  string            `json:"year_month"`
+	AbsentDays float64           `json:"absent_days"`
+	Absences   []AbsenceResponse `json:"absences"`
+}
+
+// TimeBankBalanceResponse represents the current time bank balance
+type TimeBankBalanceResponse struct {
+	BalanceMinutes int       `json:"balance_minutes"` // positive means time off is owed
+	AsOf           time.Time `json:"as_of"`
+}
+
+// TimeBankEntryResponse represents a time bank entry with the balance right after it
+type TimeBankEntryResponse struct {
+	ID             string    `json:"id"`
+	Date           string    `json:"date"`
+	Kind           string    `json:"kind"`
+	Minutes        int       `json:"minutes"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"created_at"`
+	BalanceMinutes int       `json:"balance_minutes"`
+}
+
+// TimeBankHistoryResponse represents the time bank entries of a date range
+type TimeBankHistoryResponse struct {
+	From                  string                  `json:"from,omitempty"`
+	To                    string                  `json:"to,omitempty"`
+	OpeningBalanceMinutes int                     `json:"opening_balance_minutes"`
+	ClosingBalanceMinutes int                     `json:"closing_balance_minutes"`
+	Entries               []TimeBankEntryResponse `json:"entries"`
+}
+
+// OvertimePayResponse represents a month's estimated overtime pay by statutory bucket
+type OvertimePayResponse struct {
+	YearMonth      string           `json:"year_month"`
+	HourlyRate     float64          `json:"hourly_rate"`
+	WorkdayMinutes int              `json:"workday_minutes"`  // paid at 1.5x
+	RestDayMinutes int              `json:"rest_day_minutes"` // paid at 2x
+	HolidayMinutes int              `json:"holiday_minutes"`  // paid at 3x
+	WorkdayPay     float64          `json:"workday_pay"`
+	RestDayPay     float64          `json:"rest_day_pay"`
+	HolidayPay     float64          `json:"holiday_pay"`
+	TotalPay       float64          `json:"total_pay"`
+	Days           []DayOvertimePay `json:"days"`
+}
+
+// DayOvertimePay represents the paid overtime of a single day
+type DayOvertimePay struct {
+	Date            string  `json:"date"`
+	DayType         string  `json:"day_type"` // "workday", "rest_day" or "holiday"
+	Multiplier      float64 `json:"multiplier"`
+	OvertimeMinutes int     `json:"overtime_minutes"`
+	Pay             float64 `json:"pay"`
+}
+
+// ComplianceResponse represents the labor-law limits close to or beyond being breached
+type ComplianceResponse struct {
+	EvaluatedAt time.Time         `json:"evaluated_at"`
+	Rules       ComplianceRules   `json:"rules"`
+	Issues      []ComplianceIssue `json:"issues"`
+}
+
+// ComplianceIssue represents a single limit close to or beyond being breached
+type ComplianceIssue struct {
+	Rule          string `json:"rule"`     // "monthly_overtime", "weekly_hours" or "daily_rest"
+	Severity      string `json:"severity"` // "warning" or "violation"
+	Date          string `json:"date"`     // first day of the period, or the day the rest ended
+	ActualMinutes int    `json:"actual_minutes"`
+	LimitMinutes  int    `json:"limit_minutes"`
+	Message       string `json:"message"`
+}
+
+// ProjectResponse represents a single project
+type ProjectResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Code     string `json:"code"`
+	Archived bool   `json:"archived"`
+}
+
+// ProjectListResponse represents all projects
+type ProjectListResponse struct {
+	Projects []ProjectResponse `json:"projects"`
+}
+
+// AllocationsResponse represents the time allocations of a session
+type AllocationsResponse struct {
+	SessionID        string               `json:"session_id"`
+	Date             string               `json:"date"`
+	WorkedMinutes    int                  `json:"worked_minutes"`
+	AllocatedMinutes int                  `json:"allocated_minutes"`
+	Allocations      []AllocationResponse `json:"allocations"`
+}
+
+// AllocationResponse represents part of a session's worked time spent on a project
+type AllocationResponse struct {
+	ID              string  `json:"id"`
+	ProjectID       string  `json:"project_id"`
+	Task            string  `json:"task"`
+	Minutes         int     `json:"minutes"`
+	Percent         float64 `json:"percent"`
+	ResolvedMinutes int     `json:"resolved_minutes"` // minutes covered, a percentage resolved against the worked minutes
+}
+
+// ProjectTotalsResponse represents the time allocated per project over a date range
+type ProjectTotalsResponse struct {
+	From               string         `json:"from"`
+	To                 string         `json:"to"`
+	UnallocatedMinutes int            `json:"unallocated_minutes"` // worked time not allocated to any project
+	Projects           []ProjectTotal `json:"projects"`            // most minutes first
+}
+
+// ProjectTotal represents the time allocated to one project, split by task
+type ProjectTotal struct {
+	ProjectID string      `json:"project_id"`
+	Name      string      `json:"name"`
+	Code      string      `json:"code"`
+	Minutes   int         `json:"minutes"`
+	Tasks     []TaskTotal `json:"tasks"`
+}
+
+// TaskTotal represents the time allocated to one task of a project
+type TaskTotal struct {
+	Task    string `json:"task"` // empty for time without a task
+	Minutes int    `json:"minutes"`
+}
+
+// SessionNoteResponse represents the note and tags of a session
+type SessionNoteResponse struct {
+	SessionID string   `json:"session_id"`
+	Date      string   `json:"date"`
+	Note      string   `json:"note"`
+	Tags      []string `json:"tags"`
+}
+
+// VoidSessionResponse represents a session after it was voided
+type VoidSessionResponse struct {
+	SessionID string `json:"session_id"`
+	Date      string `json:"date"`
+	State     string `json:"state"`
+}
+
+// PunchResponse represents a record of the punch log
+type PunchResponse struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Time       time.Time `json:"time"`
+	Source     string    `json:"source"`               // manual, hr_auto_fetch, scheduler_sync or webhook
+	SessionID  string    `json:"session_id,omitempty"` // the voided session of a void punch
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// PunchListResponse represents the punch log between two dates
+type PunchListResponse struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Punches []PunchResponse `json:"punches"`
+}
+
+// InboundPunchResponse represents a clock event accepted from an inbound webhook
+type InboundPunchResponse struct {
+	Kind      string    `json:"kind"`
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`
+	SessionID string    `json:"session_id"`
+}
+
+// RebuildSessionsResponse represents the outcome of deriving the sessions again from the punch log
+type RebuildSessionsResponse struct {
+	Punches  int             `json:"punches"`
+	Sessions int             `json:"sessions"`
+	Skipped  []PunchResponse `json:"skipped"` // punches that no longer apply under the current rules
+}
+
+// ConflictListResponse represents the days where a session time and the HR system disagree
+type ConflictListResponse struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Conflict represents a session time that differs from the latest value the HR system reported
+type Conflict struct {
+	Date        string     `json:"date"`
+	Kind        string     `json:"kind"`                 // check_in or check_out
+	LocalTime   *time.Time `json:"local_time,omitempty"` // absent if the day has no such time yet
+	LocalSource string     `json:"local_source,omitempty"`
+	HRTime      time.Time  `json:"hr_time"`
+	HRSource    string     `json:"hr_source"`
+	DiffMinutes int        `json:"diff_minutes"` // how far the HR time is after the local one
+	KeptSource  string     `json:"kept_source"`  // the source whose time the precedence policy keeps
+}
+
+// SessionSearchResponse represents the sessions matching a search
+type SessionSearchResponse struct {
+	From            string                `json:"from"`
+	To              string                `json:"to"`
+	OvertimeMinutes int                   `json:"overtime_minutes"` // overtime of the days with a matching session
+	Sessions        []SessionSearchResult `json:"sessions"`
+}
+
+// SessionSearchResult represents a session matching a search
+type SessionSearchResult struct {
+	SessionID          string     `json:"session_id"`
+	Date               string     `json:"date"`
+	CheckInTime        time.Time  `json:"check_in_time"`
+	CheckOutTime       *time.Time `json:"check_out_time,omitempty"`
+	WorkedMinutes      int        `json:"worked_minutes"`
+	DayOvertimeMinutes int        `json:"day_overtime_minutes"` // overtime of the whole day the session belongs to
+	Note               string     `json:"note"`
+	Tags               []string   `json:"tags"`
 }
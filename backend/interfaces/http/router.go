@@ -12,10 +12,26 @@ func SetupRouter(workHandler *WorkHandler) *http.ServeMux {
 	// Register API routes with CORS middleware
 	mux.HandleFunc("/api/checkin", corsMiddleware(workHandler.CheckIn))
 	mux.HandleFunc("/api/checkout", corsMiddleware(workHandler.CheckOut))
+	mux.HandleFunc("/api/break/start", corsMiddleware(workHandler.StartBreak))
+	mux.HandleFunc("/api/break/end", corsMiddleware(workHandler.EndBreak))
 	mux.HandleFunc("/api/status", corsMiddleware(workHandler.GetStatus))
 	mux.HandleFunc("/api/today-checkin", corsMiddleware(workHandler.GetTodayCheckIn))
 	mux.HandleFunc("/api/monthly-stats", corsMiddleware(workHandler.GetMonthlyStats))
+	mux.HandleFunc("/api/overtime-pay", corsMiddleware(workHandler.GetOvertimePay))
+	mux.HandleFunc("/api/compliance", corsMiddleware(workHandler.GetCompliance))
 	mux.HandleFunc("/api/config", corsMiddleware(handleConfig(workHandler)))
+	mux.HandleFunc("/api/absences", corsMiddleware(handleAbsences(workHandler)))
+	mux.HandleFunc("/api/timebank/balance", corsMiddleware(workHandler.GetTimeBankBalance))
+	mux.HandleFunc("/api/timebank/history", corsMiddleware(workHandler.GetTimeBankHistory))
+	mux.HandleFunc("/api/timebank/entries", corsMiddleware(handleTimeBankEntries(workHandler)))
+	mux.HandleFunc("/api/projects", corsMiddleware(handleProjects(workHandler)))
+	mux.HandleFunc("/api/projects/totals", corsMiddleware(workHandler.GetProjectTotals))
+	mux.HandleFunc("/api/allocations", corsMiddleware(handleAllocations(workHandler)))
+	mux.HandleFunc("/api/sessions/note", corsMiddleware(workHandler.UpdateSessionNote))
+	mux.HandleFunc("/api/sessions/search", corsMiddleware(workHandler.SearchSessions))
+	mux.HandleFunc("/api/sessions/void", corsMiddleware(workHandler.VoidSession))
+	mux.HandleFunc("/api/punches", corsMiddleware(handlePunches(workHandler)))
+	mux.HandleFunc("/api/conflicts", corsMiddleware(workHandler.ListConflicts))
 
 	// Serve Next.js static files
 	fs := http.FileServer(http.Dir("../../frontend/out"))
@@ -38,12 +54,86 @@ func handleConfig(workHandler *WorkHandler) http.HandlerFunc {
 	}
 }
 
+// handleAbsences handles GET, POST and DELETE for /api/absences
+func handleAbsences(workHandler *WorkHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			workHandler.ListAbsences(w, r)
+		case http.MethodPost:
+			workHandler.SaveAbsence(w, r)
+		case http.MethodDelete:
+			workHandler.DeleteAbsence(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// handlePunches handles GET and POST for /api/punches
+func handlePunches(workHandler *WorkHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			workHandler.ListPunches(w, r)
+		case http.MethodPost:
+			workHandler.RecordInboundPunch(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// handleTimeBankEntries handles POST and DELETE for /api/timebank/entries
+func handleTimeBankEntries(workHandler *WorkHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			workHandler.AddTimeBankEntry(w, r)
+		case http.MethodDelete:
+			workHandler.DeleteTimeBankEntry(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// handleProjects handles GET, POST and DELETE for /api/projects
+func handleProjects(workHandler *WorkHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			workHandler.ListProjects(w, r)
+		case http.MethodPost:
+			workHandler.SaveProject(w, r)
+		case http.MethodDelete:
+			workHandler.DeleteProject(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// handleAllocations handles GET and POST for /api/allocations
+func handleAllocations(workHandler *WorkHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			workHandler.GetAllocations(w, r)
+		case http.MethodPost:
+			workHandler.SaveAllocations(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
 // corsMiddleware adds CORS headers to allow cross-origin requests
 func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Enable CORS for development
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 		if r.Method == http.MethodOptions {
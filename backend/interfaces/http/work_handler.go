@@ -1,8 +1,11 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/simon0-o/offline_me/backend/domain"
@@ -11,13 +14,36 @@ import (
 
 // WorkUsecase defines the interface for work-related business logic
 type WorkUsecase interface {
-	CheckIn(req *dto.CheckInRequest) (*dto.CheckInResponse, error)
-	CheckOut(req *dto.CheckOutRequest) (*dto.CheckOutResponse, error)
-	GetStatus() (*dto.StatusResponse, error)
-	GetTodayCheckIn(req *dto.TodayCheckInRequest) (*dto.TodayCheckInResponse, error)
-	UpdateConfig(req *dto.ConfigRequest) error
-	GetConfig() (*dto.ConfigResponse, error)
-	GetMonthlyStats() (*dto.MonthlyStatsResponse, error)
+	CheckIn(ctx context.Context, req *dto.CheckInRequest) (*dto.CheckInResponse, error)
+	CheckOut(ctx context.Context, req *dto.CheckOutRequest) (*dto.CheckOutResponse, error)
+	StartBreak(ctx context.Context, req *dto.BreakStartRequest) (*dto.BreakResponse, error)
+	EndBreak(ctx context.Context, req *dto.BreakEndRequest) (*dto.BreakResponse, error)
+	GetStatus(ctx context.Context) (*dto.StatusResponse, error)
+	GetTodayCheckIn(ctx context.Context, req *dto.TodayCheckInRequest) (*dto.TodayCheckInResponse, error)
+	UpdateConfig(ctx context.Context, req *dto.ConfigRequest) error
+	GetConfig(ctx context.Context) (*dto.ConfigResponse, error)
+	GetMonthlyStats(ctx context.Context) (*dto.MonthlyStatsResponse, error)
+	GetOvertimePay(ctx context.Context, yearMonth string) (*dto.OvertimePayResponse, error)
+	GetCompliance(ctx context.Context) (*dto.ComplianceResponse, error)
+	ListAbsences(ctx context.Context, yearMonth string) (*dto.AbsenceListResponse, error)
+	SaveAbsence(ctx context.Context, req *dto.AbsenceRequest) (*dto.AbsenceResponse, error)
+	DeleteAbsence(ctx context.Context, id string) error
+	GetTimeBankBalance(ctx context.Context) (*dto.TimeBankBalanceResponse, error)
+	GetTimeBankHistory(ctx context.Context, from, to string) (*dto.TimeBankHistoryResponse, error)
+	AddTimeBankEntry(ctx context.Context, req *dto.TimeBankEntryRequest) (*dto.TimeBankEntryResponse, error)
+	DeleteTimeBankEntry(ctx context.Context, id string) error
+	ListProjects(ctx context.Context) (*dto.ProjectListResponse, error)
+	SaveProject(ctx context.Context, req *dto.ProjectRequest) (*dto.ProjectResponse, error)
+	DeleteProject(ctx context.Context, id string) error
+	GetAllocations(ctx context.Context, sessionID string) (*dto.AllocationsResponse, error)
+	SaveAllocations(ctx context.Context, req *dto.AllocationsRequest) (*dto.AllocationsResponse, error)
+	GetProjectTotals(ctx context.Context, from, to string) (*dto.ProjectTotalsResponse, error)
+	UpdateSessionNote(ctx context.Context, req *dto.SessionNoteRequest) (*dto.SessionNoteResponse, error)
+	SearchSessions(ctx context.Context, req *dto.SessionSearchRequest) (*dto.SessionSearchResponse, error)
+	VoidSession(ctx context.Context, req *dto.VoidSessionRequest) (*dto.VoidSessionResponse, error)
+	ListPunches(ctx context.Context, from, to string) (*dto.PunchListResponse, error)
+	RecordInboundPunch(ctx context.Context, req *dto.PunchRequest) (*dto.InboundPunchResponse, error)
+	ListConflicts(ctx context.Context, from, to string) (*dto.ConflictListResponse, error)
 }
 
 // WorkHandler handles HTTP requests for work tracking
@@ -48,10 +74,10 @@ func (h *WorkHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.uc.CheckIn(&req)
+	resp, err := h.uc.CheckIn(r.Context(), &req)
 	if err != nil {
 		h.log.Errorf("Check-in failed: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.respondError(w, err)
 		return
 	}
 
@@ -72,10 +98,58 @@ func (h *WorkHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.uc.CheckOut(&req)
+	resp, err := h.uc.CheckOut(r.Context(), &req)
 	if err != nil {
 		h.log.Errorf("Check-out failed: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// StartBreak handles break start requests
+func (h *WorkHandler) StartBreak(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.BreakStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid break start request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.StartBreak(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Break start failed: %v", err)
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// EndBreak handles break end requests
+func (h *WorkHandler) EndBreak(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.BreakEndRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid break end request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.EndBreak(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Break end failed: %v", err)
+		h.respondError(w, err)
 		return
 	}
 
@@ -89,10 +163,10 @@ func (h *WorkHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.uc.GetStatus()
+	resp, err := h.uc.GetStatus(r.Context())
 	if err != nil {
 		h.log.Errorf("Failed to get status: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.respondFailure(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -113,10 +187,10 @@ func (h *WorkHandler) GetTodayCheckIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.uc.GetTodayCheckIn(&req)
+	resp, err := h.uc.GetTodayCheckIn(r.Context(), &req)
 	if err != nil {
 		h.log.Errorf("Failed to get today check-in: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.respondFailure(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -143,9 +217,9 @@ func (h *WorkHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.uc.UpdateConfig(&req); err != nil {
+	if err := h.uc.UpdateConfig(r.Context(), &req); err != nil {
 		h.log.Errorf("Failed to update config: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.respondFailure(w, err, http.StatusBadRequest)
 		return
 	}
 
@@ -159,10 +233,10 @@ func (h *WorkHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	config, err := h.uc.GetConfig()
+	config, err := h.uc.GetConfig(r.Context())
 	if err != nil {
 		h.log.Errorf("Failed to get config: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.respondFailure(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -176,16 +250,516 @@ func (h *WorkHandler) GetMonthlyStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := h.uc.GetMonthlyStats()
+	stats, err := h.uc.GetMonthlyStats(r.Context())
 	if err != nil {
 		h.log.Errorf("Failed to get monthly stats: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.respondFailure(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	h.respondJSON(w, stats)
 }
 
+// GetOvertimePay handles requests for a month's estimated overtime pay
+func (h *WorkHandler) GetOvertimePay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := h.uc.GetOvertimePay(r.Context(), r.URL.Query().Get("month"))
+	if err != nil {
+		h.log.Errorf("Failed to get overtime pay: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// GetCompliance handles requests for the labor-law limits close to or beyond being breached
+func (h *WorkHandler) GetCompliance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := h.uc.GetCompliance(r.Context())
+	if err != nil {
+		h.log.Errorf("Failed to evaluate compliance: %v", err)
+		h.respondFailure(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// ListAbsences handles requests for the absences of a month
+func (h *WorkHandler) ListAbsences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := h.uc.ListAbsences(r.Context(), r.URL.Query().Get("month"))
+	if err != nil {
+		h.log.Errorf("Failed to list absences: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// SaveAbsence handles absence create/update requests
+func (h *WorkHandler) SaveAbsence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.AbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid absence request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.SaveAbsence(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to save absence: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// DeleteAbsence handles absence delete requests
+func (h *WorkHandler) DeleteAbsence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Missing absence id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.uc.DeleteAbsence(r.Context(), id); err != nil {
+		h.log.Errorf("Failed to delete absence: %v", err)
+		h.respondFailure(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, map[string]string{"status": "success"})
+}
+
+// GetTimeBankBalance handles time bank balance requests
+func (h *WorkHandler) GetTimeBankBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := h.uc.GetTimeBankBalance(r.Context())
+	if err != nil {
+		h.log.Errorf("Failed to get time bank balance: %v", err)
+		h.respondFailure(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// GetTimeBankHistory handles time bank history requests
+func (h *WorkHandler) GetTimeBankHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	resp, err := h.uc.GetTimeBankHistory(r.Context(), query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.log.Errorf("Failed to get time bank history: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// AddTimeBankEntry handles manual time bank adjustment and comp-off requests
+func (h *WorkHandler) AddTimeBankEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.TimeBankEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid time bank entry request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.AddTimeBankEntry(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to add time bank entry: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// DeleteTimeBankEntry handles time bank entry delete requests
+func (h *WorkHandler) DeleteTimeBankEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Missing entry id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.uc.DeleteTimeBankEntry(r.Context(), id); err != nil {
+		h.log.Errorf("Failed to delete time bank entry: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, map[string]string{"status": "success"})
+}
+
+// ListProjects handles requests for all projects
+func (h *WorkHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := h.uc.ListProjects(r.Context())
+	if err != nil {
+		h.log.Errorf("Failed to list projects: %v", err)
+		h.respondFailure(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// SaveProject handles project create/update requests
+func (h *WorkHandler) SaveProject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid project request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.SaveProject(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to save project: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// DeleteProject handles project delete requests
+func (h *WorkHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Missing project id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.uc.DeleteProject(r.Context(), id); err != nil {
+		h.log.Errorf("Failed to delete project: %v", err)
+		if errors.Is(err, domain.ErrProjectInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		h.respondFailure(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, map[string]string{"status": "success"})
+}
+
+// GetAllocations handles requests for the time allocations of a session
+func (h *WorkHandler) GetAllocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		http.Error(w, "Missing session id", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.GetAllocations(r.Context(), sessionID)
+	if err != nil {
+		h.log.Errorf("Failed to get allocations: %v", err)
+		h.respondFailure(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// SaveAllocations handles requests replacing the time allocations of a session
+func (h *WorkHandler) SaveAllocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.AllocationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid allocations request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.SaveAllocations(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to save allocations: %v", err)
+		if errors.Is(err, domain.ErrInvalidAllocation) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.respondFailure(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// GetProjectTotals handles requests for the time allocated per project over a date range
+func (h *WorkHandler) GetProjectTotals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	resp, err := h.uc.GetProjectTotals(r.Context(), query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.log.Errorf("Failed to get project totals: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// UpdateSessionNote handles requests replacing the note and tags of a session
+func (h *WorkHandler) UpdateSessionNote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.SessionNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid session note request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.UpdateSessionNote(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to update session note: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// SearchSessions handles session search requests filtering by tag, text, date range and minimum overtime
+func (h *WorkHandler) SearchSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	req := dto.SessionSearchRequest{
+		From: query.Get("from"),
+		To:   query.Get("to"),
+		Tag:  query.Get("tag"),
+		Text: query.Get("q"),
+	}
+	if value := query.Get("min_overtime"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid min_overtime, expected minutes", http.StatusBadRequest)
+			return
+		}
+		req.MinOvertimeMinutes = minutes
+	}
+
+	resp, err := h.uc.SearchSessions(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to search sessions: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// VoidSession handles requests voiding a session recorded by mistake
+func (h *WorkHandler) VoidSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.VoidSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid void session request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.VoidSession(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to void session: %v", err)
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// ListPunches handles punch log requests between two dates
+func (h *WorkHandler) ListPunches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	resp, err := h.uc.ListPunches(r.Context(), query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.log.Errorf("Failed to list punches: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// RecordInboundPunch handles clock events sent by an inbound webhook
+func (h *WorkHandler) RecordInboundPunch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.PunchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorf("Invalid punch request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.uc.RecordInboundPunch(r.Context(), &req)
+	if err != nil {
+		h.log.Errorf("Failed to record inbound punch: %v", err)
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// ListConflicts handles listing the days where a session time and the HR system disagree
+func (h *WorkHandler) ListConflicts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	resp, err := h.uc.ListConflicts(r.Context(), query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.log.Errorf("Failed to list conflicts: %v", err)
+		h.respondFailure(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.respondJSON(w, resp)
+}
+
+// respondError writes the status a session rule violation maps to, any other error goes to respondFailure
+func (h *WorkHandler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNoCheckIn):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrSessionClosed),
+		errors.Is(err, domain.ErrAlreadyOnBreak),
+		errors.Is(err, domain.ErrNotOnBreak),
+		errors.Is(err, domain.ErrOutranked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrCheckInInFuture),
+		errors.Is(err, domain.ErrCheckInBeforeSession),
+		errors.Is(err, domain.ErrCheckInAfterBreak),
+		errors.Is(err, domain.ErrCheckOutInFuture),
+		errors.Is(err, domain.ErrCheckOutBeforeCheckIn),
+		errors.Is(err, domain.ErrCheckOutBeforeBreak),
+		errors.Is(err, domain.ErrBreakTooEarly):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrInvalidPunchKind):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.respondFailure(w, err, http.StatusInternalServerError)
+	}
+}
+
+// respondFailure writes 503 while the storage is unavailable, 404 for a missing record and 409 for a duplicate one,
+// any other error gets the given status, with its message unless the status is internal
+func (h *WorkHandler) respondFailure(w http.ResponseWriter, err error, status int) {
+	switch {
+	case errors.Is(err, domain.ErrAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrStorage),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, context.Canceled):
+		http.Error(w, "Storage unavailable", http.StatusServiceUnavailable)
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case status == http.StatusInternalServerError:
+		http.Error(w, "Internal server error", status)
+	default:
+		http.Error(w, err.Error(), status)
+	}
+}
+
 // respondJSON writes a JSON response
 func (h *WorkHandler) respondJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -10,7 +10,6 @@ export default async function Home() {
       work_hours: 480, // Default 8 hours
       auto_fetch_enabled: false,
       check_in_api_url: '',
-      authorization: '',
       p_auth: '',
       p_rtoken: '',
       check_in_webhook_url: '',
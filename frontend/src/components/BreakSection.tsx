@@ -0,0 +1,49 @@
+'use client';
+
+import { useState } from 'react';
+import { showNotification } from '@/lib/utils';
+import { api } from '@/lib/api';
+
+interface BreakSectionProps {
+  isOnBreak: boolean;
+  onSuccess: () => void;
+}
+
+export default function BreakSection({ isOnBreak, onSuccess }: BreakSectionProps) {
+  const [loading, setLoading] = useState(false);
+
+  const handleToggleBreak = async () => {
+    setLoading(true);
+    try {
+      const now = new Date().toISOString();
+      const result = isOnBreak
+        ? await api.endBreak({ break_end_time: now })
+        : await api.startBreak({ break_start_time: now });
+
+      const message = `Break time today: ${Math.floor(result.break_minutes / 60)}h ${result.break_minutes % 60}m`;
+      showNotification(isOnBreak ? 'Break Ended' : 'Break Started', message);
+      onSuccess();
+    } catch (error) {
+      console.error('Failed to toggle break:', error);
+      alert(isOnBreak ? 'Failed to end break' : 'Failed to start break');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="mb-8">
+      <h3 className="text-lg font-semibold mb-3">Break</h3>
+      <p className="mb-3 text-sm text-gray-600">
+        Breaks are not counted as work time and push the expected check-out later.
+      </p>
+      <button
+        onClick={handleToggleBreak}
+        disabled={loading}
+        className="bg-blue-600 text-white px-5 py-2 rounded-md text-base cursor-pointer transition-colors hover:bg-blue-700 disabled:bg-gray-400 disabled:cursor-not-allowed"
+      >
+        {loading ? 'Saving...' : isOnBreak ? 'End Break' : 'Start Break'}
+      </button>
+    </div>
+  );
+}
@@ -48,9 +48,9 @@ export default function CheckOutSection({ onSuccess }: CheckOutSectionProps) {
       if (result.overtime_minutes > 0) {
         message += `\nOvertime: ${overtimeHours}h ${overtimeMinutes}m`;
       } else if (result.overtime_minutes < 0) {
-        message += `\nUnder 10 hours by: ${overtimeHours}h ${overtimeMinutes}m`;
+        message += `\nUnder overtime threshold by: ${overtimeHours}h ${overtimeMinutes}m`;
       } else {
-        message += `\nExactly 10 hours worked`;
+        message += `\nExactly at overtime threshold`;
       }
 
       setManuallyChanged(false);
@@ -8,6 +8,7 @@ import StatusCard from '@/components/StatusCard';
 import MonthlyStatsCard from '@/components/MonthlyStatsCard';
 import CheckInSection from '@/components/CheckInSection';
 import CheckOutSection from '@/components/CheckOutSection';
+import BreakSection from '@/components/BreakSection';
 import ConfigSection from '@/components/ConfigSection';
 
 interface HomeClientProps {
@@ -108,6 +109,9 @@ export default function HomeClient({
                         currentCheckInTime={status?.check_in_time || ''}
                         onSuccess={handleRefresh}
                     />
+                    {status?.has_checked_in && !status.check_out_time && (
+                        <BreakSection isOnBreak={status.is_on_break} onSuccess={handleRefresh} />
+                    )}
                     <CheckOutSection onSuccess={handleRefresh} />
                 </>
 
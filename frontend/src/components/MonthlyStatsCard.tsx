@@ -41,9 +41,22 @@ export default function MonthlyStatsCard({ stats }: MonthlyStatsCardProps) {
           <div className="ml-2">
             <div>Check-in days: {stats.current_month.total_days}</div>
             <div>Checked-out days: {stats.current_month.checked_out_days}</div>
+            <div>
+              Absent days: {stats.current_month.absent_days} / Expected workdays: {stats.current_month.expected_workdays}
+            </div>
             <div className="font-semibold mt-1">
               Total Overtime: {formatOvertimeDisplay(stats.current_month.overtime_minutes)}
             </div>
+            {stats.current_month.weekend_overtime_minutes > 0 && (
+              <div className="text-sm text-gray-600">
+                Weekend: {formatOvertimeDisplay(stats.current_month.weekend_overtime_minutes)}
+              </div>
+            )}
+            {(stats.current_month.late_arrivals > 0 || stats.current_month.early_departures > 0) && (
+              <div className="text-sm text-gray-600">
+                Late: {stats.current_month.late_arrivals}, left early: {stats.current_month.early_departures}
+              </div>
+            )}
           </div>
         </div>
         <div>
@@ -53,9 +66,22 @@ export default function MonthlyStatsCard({ stats }: MonthlyStatsCardProps) {
           <div className="ml-2">
             <div>Check-in days: {stats.last_month.total_days}</div>
             <div>Checked-out days: {stats.last_month.checked_out_days}</div>
+            <div>
+              Absent days: {stats.last_month.absent_days} / Expected workdays: {stats.last_month.expected_workdays}
+            </div>
             <div className="font-semibold mt-1">
               Total Overtime: {formatOvertimeDisplay(stats.last_month.overtime_minutes)}
             </div>
+            {stats.last_month.weekend_overtime_minutes > 0 && (
+              <div className="text-sm text-gray-600">
+                Weekend: {formatOvertimeDisplay(stats.last_month.weekend_overtime_minutes)}
+              </div>
+            )}
+            {(stats.last_month.late_arrivals > 0 || stats.last_month.early_departures > 0) && (
+              <div className="text-sm text-gray-600">
+                Late: {stats.last_month.late_arrivals}, left early: {stats.last_month.early_departures}
+              </div>
+            )}
           </div>
         </div>
       </div>
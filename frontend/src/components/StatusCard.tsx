@@ -21,6 +21,23 @@ export default function StatusCard({ status }: StatusCardProps) {
   const minutes = status.work_hours % 60;
   const workTimeText = minutes > 0 ? `${hours} hours ${minutes} minutes` : `${hours} hours`;
 
+  // Countdown to the expected check-out, which already follows the configured check-out rule
+  const remainingMinutes = status.expected_check_out_time
+    ? Math.max(
+        0,
+        Math.ceil(
+          (new Date(status.expected_check_out_time).getTime() - new Date(status.current_time).getTime()) / 60000
+        )
+      )
+    : 0;
+
+  // Sessions of earlier workdays left open are not checked out by today's punches, they need a manual fix
+  const unclosedNotice = status.unclosed?.map((session) => (
+    <div key={session.session_id} className="font-semibold my-2 text-red-600">
+      ⚠️ Never checked out after checking in at {formatDateTime(session.check_in_time)}, please correct {session.date}
+    </div>
+  ));
+
   if (status.has_checked_in) {
     return (
       <div className="bg-gray-100 rounded-lg p-5 mb-5">
@@ -28,12 +45,18 @@ export default function StatusCard({ status }: StatusCardProps) {
         <div className="text-green-600 font-semibold">✅ Checked in today</div>
         <div className="text-lg font-semibold my-2">
           Check-in: {formatDateTime(status.check_in_time!)}
+          {status.rounded_check_in_time && status.rounded_check_in_time !== status.check_in_time && (
+            <span className="text-sm text-gray-600"> (rounded: {formatDateTime(status.rounded_check_in_time)})</span>
+          )}
         </div>
 
         {status.check_out_time ? (
           <>
             <div className="text-lg font-semibold my-2">
               Last Check-out: {formatDateTime(status.check_out_time)}
+              {status.rounded_check_out_time && status.rounded_check_out_time !== status.check_out_time && (
+                <span className="text-sm text-gray-600"> (rounded: {formatDateTime(status.rounded_check_out_time)})</span>
+              )}
             </div>
             {status.overtime_minutes > 0 ? (
               <div className="text-lg font-semibold my-2 text-orange-500">
@@ -42,12 +65,12 @@ export default function StatusCard({ status }: StatusCardProps) {
               </div>
             ) : status.overtime_minutes < 0 ? (
               <div className="text-lg font-semibold my-2 text-green-600">
-                ✅ Under 10 hours by: {Math.floor(Math.abs(status.overtime_minutes) / 60)}h{' '}
+                ✅ Under overtime threshold by: {Math.floor(Math.abs(status.overtime_minutes) / 60)}h{' '}
                 {Math.abs(status.overtime_minutes) % 60}m
               </div>
             ) : (
               <div className="text-lg font-semibold my-2 text-green-600">
-                ✅ Exactly 10 hours worked
+                ✅ Exactly at overtime threshold
               </div>
             )}
           </>
@@ -56,10 +79,41 @@ export default function StatusCard({ status }: StatusCardProps) {
             <div className="text-lg font-semibold my-2">
               Expected check-out: {formatDateTime(status.expected_check_out_time!)}
             </div>
+            {remainingMinutes > 0 && !status.is_on_break && (
+              <div className="text-lg font-semibold my-2">
+                Time left: {Math.floor(remainingMinutes / 60)}h {remainingMinutes % 60}m
+              </div>
+            )}
+            {status.balanced_check_out_time && (
+              <div className="text-lg font-semibold my-2">
+                Balance-neutral check-out: {formatDateTime(status.balanced_check_out_time)} (
+                {status.carry_over_minutes >= 0 ? '+' : '-'}
+                {Math.floor(Math.abs(status.carry_over_minutes) / 60)}h {Math.abs(status.carry_over_minutes) % 60}m carried over)
+              </div>
+            )}
             <div className="text-lg font-semibold my-2">
               Current time: {formatDateTime(status.current_time)}
             </div>
             <div className="text-lg font-semibold my-2">Work hours: {workTimeText}</div>
+            {status.is_on_break && status.break_start_time && (
+              <div className="text-lg font-semibold my-2 text-blue-600">
+                ☕ On a break since {formatDateTime(status.break_start_time)}
+              </div>
+            )}
+            {status.break_minutes > 0 && (
+              <div className="text-lg font-semibold my-2">
+                Break time today: {Math.floor(status.break_minutes / 60)}h {status.break_minutes % 60}m
+              </div>
+            )}
+            {status.compliance?.map((issue) => (
+              <div
+                key={issue.rule + issue.date}
+                className={`font-semibold my-2 ${issue.severity === 'violation' ? 'text-red-600' : 'text-orange-500'}`}
+              >
+                ⚠️ {issue.message}
+              </div>
+            ))}
+            {unclosedNotice}
           </>
         )}
       </div>
@@ -76,6 +130,7 @@ export default function StatusCard({ status }: StatusCardProps) {
         Current time: {formatDateTime(status.current_time)}
       </div>
       <div className="text-lg font-semibold my-2">Configured work hours: {workTimeText}</div>
+      {unclosedNotice}
     </div>
   );
 }
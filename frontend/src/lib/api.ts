@@ -5,9 +5,34 @@ import type {
   CheckInResponse,
   CheckOutRequest,
   CheckOutResponse,
+  BreakStartRequest,
+  BreakEndRequest,
+  BreakResponse,
   TodayCheckInRequest,
   TodayCheckInResponse,
   MonthlyStatsResponse,
+  OvertimePayResponse,
+  ComplianceResponse,
+  AbsenceRequest,
+  Absence,
+  AbsenceListResponse,
+  TimeBankEntryRequest,
+  TimeBankEntry,
+  TimeBankBalanceResponse,
+  TimeBankHistoryResponse,
+  Project,
+  ProjectRequest,
+  ProjectListResponse,
+  AllocationsRequest,
+  AllocationsResponse,
+  ProjectTotalsResponse,
+  SessionNoteRequest,
+  SessionNoteResponse,
+  SessionSearchParams,
+  SessionSearchResponse,
+  VoidSessionResponse,
+  PunchListResponse,
+  ConflictListResponse,
 } from './types';
 
 const API_BASE = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080';
@@ -22,7 +47,9 @@ async function fetchApi<T>(endpoint: string, options?: RequestInit): Promise<T>
   });
 
   if (!response.ok) {
-    throw new Error(`API request failed: ${response.statusText}`);
+    // Rule violations come back as 4xx with the reason in the body
+    const reason = (await response.text()).trim();
+    throw new Error(reason || `API request failed: ${response.statusText}`);
   }
 
   return response.json();
@@ -58,6 +85,20 @@ export const api = {
     });
   },
 
+  async startBreak(data: BreakStartRequest): Promise<BreakResponse> {
+    return fetchApi<BreakResponse>('/api/break/start', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  },
+
+  async endBreak(data: BreakEndRequest): Promise<BreakResponse> {
+    return fetchApi<BreakResponse>('/api/break/end', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  },
+
   async getTodayCheckIn(data: TodayCheckInRequest): Promise<TodayCheckInResponse> {
     return fetchApi<TodayCheckInResponse>('/api/today-checkin', {
       method: 'POST',
@@ -68,4 +109,127 @@ export const api = {
   async getMonthlyStats(): Promise<MonthlyStatsResponse> {
     return fetchApi<MonthlyStatsResponse>('/api/monthly-stats');
   },
+
+  async getOvertimePay(month?: string): Promise<OvertimePayResponse> {
+    const query = month ? `?month=${encodeURIComponent(month)}` : '';
+    return fetchApi<OvertimePayResponse>(`/api/overtime-pay${query}`);
+  },
+
+  async getCompliance(): Promise<ComplianceResponse> {
+    return fetchApi<ComplianceResponse>('/api/compliance');
+  },
+
+  async getAbsences(month?: string): Promise<AbsenceListResponse> {
+    const query = month ? `?month=${encodeURIComponent(month)}` : '';
+    return fetchApi<AbsenceListResponse>(`/api/absences${query}`);
+  },
+
+  async saveAbsence(data: AbsenceRequest): Promise<Absence> {
+    return fetchApi<Absence>('/api/absences', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  },
+
+  async deleteAbsence(id: string): Promise<void> {
+    return fetchApi(`/api/absences?id=${encodeURIComponent(id)}`, {
+      method: 'DELETE',
+    });
+  },
+
+  async getTimeBankBalance(): Promise<TimeBankBalanceResponse> {
+    return fetchApi<TimeBankBalanceResponse>('/api/timebank/balance');
+  },
+
+  async getTimeBankHistory(from?: string, to?: string): Promise<TimeBankHistoryResponse> {
+    const params = new URLSearchParams();
+    if (from) params.set('from', from);
+    if (to) params.set('to', to);
+    const query = params.toString();
+    return fetchApi<TimeBankHistoryResponse>(`/api/timebank/history${query ? `?${query}` : ''}`);
+  },
+
+  async addTimeBankEntry(data: TimeBankEntryRequest): Promise<TimeBankEntry> {
+    return fetchApi<TimeBankEntry>('/api/timebank/entries', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  },
+
+  async getProjects(): Promise<ProjectListResponse> {
+    return fetchApi<ProjectListResponse>('/api/projects');
+  },
+
+  async saveProject(data: ProjectRequest): Promise<Project> {
+    return fetchApi<Project>('/api/projects', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  },
+
+  async deleteProject(id: string): Promise<void> {
+    return fetchApi(`/api/projects?id=${encodeURIComponent(id)}`, {
+      method: 'DELETE',
+    });
+  },
+
+  async getAllocations(sessionId: string): Promise<AllocationsResponse> {
+    return fetchApi<AllocationsResponse>(`/api/allocations?session_id=${encodeURIComponent(sessionId)}`);
+  },
+
+  async saveAllocations(data: AllocationsRequest): Promise<AllocationsResponse> {
+    return fetchApi<AllocationsResponse>('/api/allocations', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  },
+
+  async getProjectTotals(from?: string, to?: string): Promise<ProjectTotalsResponse> {
+    const params = new URLSearchParams();
+    if (from) params.set('from', from);
+    if (to) params.set('to', to);
+    const query = params.toString();
+    return fetchApi<ProjectTotalsResponse>(`/api/projects/totals${query ? `?${query}` : ''}`);
+  },
+
+  async updateSessionNote(data: SessionNoteRequest): Promise<SessionNoteResponse> {
+    return fetchApi<SessionNoteResponse>('/api/sessions/note', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  },
+
+  async searchSessions(search: SessionSearchParams): Promise<SessionSearchResponse> {
+    const params = new URLSearchParams();
+    if (search.from) params.set('from', search.from);
+    if (search.to) params.set('to', search.to);
+    if (search.tag) params.set('tag', search.tag);
+    if (search.q) params.set('q', search.q);
+    if (search.min_overtime) params.set('min_overtime', String(search.min_overtime));
+    const query = params.toString();
+    return fetchApi<SessionSearchResponse>(`/api/sessions/search${query ? `?${query}` : ''}`);
+  },
+
+  async getPunches(from?: string, to?: string): Promise<PunchListResponse> {
+    const params = new URLSearchParams();
+    if (from) params.set('from', from);
+    if (to) params.set('to', to);
+    const query = params.toString();
+    return fetchApi<PunchListResponse>(`/api/punches${query ? `?${query}` : ''}`);
+  },
+
+  async getConflicts(from?: string, to?: string): Promise<ConflictListResponse> {
+    const params = new URLSearchParams();
+    if (from) params.set('from', from);
+    if (to) params.set('to', to);
+    const query = params.toString();
+    return fetchApi<ConflictListResponse>(`/api/conflicts${query ? `?${query}` : ''}`);
+  },
+
+  async voidSession(sessionId: string): Promise<VoidSessionResponse> {
+    return fetchApi<VoidSessionResponse>('/api/sessions/void', {
+      method: 'POST',
+      body: JSON.stringify({ session_id: sessionId }),
+    });
+  },
 };
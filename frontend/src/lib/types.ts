@@ -12,22 +12,128 @@ export interface StatusResponse {
   has_checked_in: boolean;
   check_in_time?: string;
   check_out_time?: string;
+  rounded_check_in_time?: string;
+  rounded_check_out_time?: string;
   expected_check_out_time?: string;
   current_time: string;
   is_check_out_time: boolean;
   work_hours: number;
   overtime_minutes: number;
+  is_on_break: boolean;
+  break_start_time?: string;
+  break_minutes: number;
+  worked_minutes: number;
+  sessions?: SessionSummary[];
+  compliance?: ComplianceIssue[];
+  carry_over_minutes: number;
+  balanced_check_out_time?: string;
+  unclosed?: UnclosedSession[]; // sessions of earlier workdays never checked out
+}
+
+export interface UnclosedSession {
+  session_id: string;
+  date: string;
+  check_in_time: string;
+}
+
+export interface RoundingPolicy {
+  interval_minutes: number; // 0 disables rounding
+  check_in: 'none' | 'up' | 'down' | 'nearest';
+  check_out: 'none' | 'up' | 'down' | 'nearest';
+  grace_minutes: number;
+}
+
+export interface CoreHours {
+  start: string; // HH:MM
+  end: string; // HH:MM
+}
+
+export interface CheckOutRule {
+  mode: 'duration' | 'fixed_end' | 'max';
+  earliest_end?: string; // HH:MM, used by fixed_end and max
+  latest_start?: string; // HH:MM
+}
+
+export interface BalancePolicy {
+  mode: 'off' | 'month' | 'week';
+  max_carry_over_minutes: number; // 0 is unlimited
+}
+
+export interface ComplianceIssue {
+  rule: 'monthly_overtime' | 'weekly_hours' | 'daily_rest';
+  severity: 'warning' | 'violation';
+  date: string;
+  actual_minutes: number;
+  limit_minutes: number;
+  message: string;
+}
+
+export interface ComplianceRules {
+  max_monthly_overtime_minutes: number;
+  max_weekly_work_minutes: number;
+  min_rest_minutes: number;
+  warning_percent: number;
+}
+
+export interface ComplianceResponse {
+  evaluated_at: string;
+  rules: ComplianceRules;
+  issues: ComplianceIssue[];
+}
+
+export type SessionState = 'open' | 'on_break' | 'closed' | 'auto_closed' | 'void';
+
+export interface SessionSummary {
+  session_id: string;
+  state: SessionState;
+  check_in_time: string;
+  check_out_time?: string;
+  check_in_source: PunchSource;
+  check_out_source?: PunchSource;
+  rounded_check_in_time: string;
+  rounded_check_out_time?: string;
+  worked_minutes: number;
+  break_minutes: number;
+  is_late: boolean;
+  late_minutes: number;
+  is_early_departure: boolean;
+  early_departure_minutes: number;
+  note?: string;
+  tags?: string[];
 }
 
 export interface WorkConfig {
   work_hours: number;
   auto_fetch_enabled: boolean;
   check_in_api_url: string;
-  authorization: string;
   p_auth: string;
   p_rtoken: string;
   check_in_webhook_url: string;
   check_out_webhook_url: string;
+  overtime_policy?: OvertimePolicy;
+  day_rollover_hour?: number;
+  weekly_schedule?: number[] | null;
+  work_cycle?: WorkCycle | null;
+  overtime_hourly_rate?: number;
+  compliance_rules?: ComplianceRules;
+  balance_policy?: BalancePolicy;
+  rounding_policy?: RoundingPolicy;
+  core_hours?: CoreHours | null;
+  check_in_reminder_lead_minutes?: number;
+  check_out_rule?: CheckOutRule;
+  source_precedence?: PunchSource[]; // highest ranked first
+}
+
+export interface WorkCycle {
+  anchor_date: string; // YYYY-MM-DD, any day in the first week of the cycle
+  weeks: number[][]; // working weekdays of each week, 0 is Sunday
+}
+
+export interface OvertimePolicy {
+  mode: 'fixed' | 'relative' | 'weekday';
+  threshold_minutes: number;
+  weekday_thresholds?: number[];
+  grace_minutes: number;
 }
 
 export interface CheckInRequest {
@@ -36,7 +142,9 @@ export interface CheckInRequest {
 
 export interface CheckInResponse {
   check_in_time: string;
+  rounded_check_in_time: string;
   expected_check_out_time: string;
+  late_minutes: number;
 }
 
 export interface CheckOutRequest {
@@ -45,9 +153,28 @@ export interface CheckOutRequest {
 
 export interface CheckOutResponse {
   check_out_time: string;
+  rounded_check_in_time: string;
+  rounded_check_out_time: string;
+  worked_minutes: number;
   overtime_minutes: number;
 }
 
+export interface BreakStartRequest {
+  break_start_time: string;
+}
+
+export interface BreakEndRequest {
+  break_end_time: string;
+}
+
+export interface BreakResponse {
+  session_id: string;
+  break_start_time: string;
+  break_end_time?: string;
+  break_minutes: number;
+  expected_check_out_time: string;
+}
+
 export interface TodayCheckInRequest {
   date: string;
   re_check_in?: boolean;
@@ -64,11 +191,248 @@ export interface TodayCheckInResponse {
 export interface MonthStats {
   year_month: string;
   total_days: number;
+  total_sessions: number;
   checked_out_days: number;
+  work_minutes: number;
   overtime_minutes: number;
+  attended_days: number;
+  absent_days: number;
+  expected_workdays: number;
+  weekend_overtime_minutes: number;
+  late_arrivals: number;
+  late_minutes: number;
+  early_departures: number;
+  early_departure_minutes: number;
+}
+
+export interface DayOvertimePay {
+  date: string;
+  day_type: 'workday' | 'rest_day' | 'holiday';
+  multiplier: number;
+  overtime_minutes: number;
+  pay: number;
+}
+
+export interface OvertimePayResponse {
+  year_month: string;
+  hourly_rate: number;
+  workday_minutes: number; // paid at 1.5x
+  rest_day_minutes: number; // paid at 2x
+  holiday_minutes: number; // paid at 3x
+  workday_pay: number;
+  rest_day_pay: number;
+  holiday_pay: number;
+  total_pay: number;
+  days: DayOvertimePay[];
 }
 
 export interface MonthlyStatsResponse {
   current_month: MonthStats;
   last_month: MonthStats;
 }
+
+export type AbsenceType = 'annual_leave' | 'sick_leave' | 'personal_leave' | 'business_trip';
+
+export type AbsenceAmount = 'full_day' | 'half_day';
+
+export interface AbsenceRequest {
+  id?: string;
+  date: string;
+  type: AbsenceType;
+  amount?: AbsenceAmount;
+  note?: string;
+}
+
+export interface Absence {
+  id: string;
+  date: string;
+  type: AbsenceType;
+  amount: AbsenceAmount;
+  days: number;
+  note: string;
+}
+
+export interface AbsenceListResponse {
+  year_month: string;
+  absent_days: number;
+  absences: Absence[];
+}
+
+export type TimeBankEntryKind = 'daily' | 'adjustment' | 'comp_off';
+
+export interface TimeBankEntryRequest {
+  date?: string;
+  kind: Exclude<TimeBankEntryKind, 'daily'>;
+  minutes: number;
+  note?: string;
+}
+
+export interface TimeBankEntry {
+  id: string;
+  date: string;
+  kind: TimeBankEntryKind;
+  minutes: number;
+  note: string;
+  created_at: string;
+  balance_minutes: number;
+}
+
+export interface TimeBankBalanceResponse {
+  balance_minutes: number;
+  as_of: string;
+}
+
+export interface TimeBankHistoryResponse {
+  from?: string;
+  to?: string;
+  opening_balance_minutes: number;
+  closing_balance_minutes: number;
+  entries: TimeBankEntry[];
+}
+
+export interface Project {
+  id: string;
+  name: string;
+  code: string;
+  archived: boolean;
+}
+
+export interface ProjectRequest {
+  id?: string;
+  name: string;
+  code?: string;
+  archived?: boolean;
+}
+
+export interface ProjectListResponse {
+  projects: Project[];
+}
+
+export interface AllocationRequest {
+  project_id: string;
+  task?: string;
+  minutes?: number; // set either minutes
+  percent?: number; // or a percentage of the worked minutes
+}
+
+export interface AllocationsRequest {
+  session_id: string;
+  allocations: AllocationRequest[];
+}
+
+export interface Allocation {
+  id: string;
+  project_id: string;
+  task: string;
+  minutes: number;
+  percent: number;
+  resolved_minutes: number;
+}
+
+export interface AllocationsResponse {
+  session_id: string;
+  date: string;
+  worked_minutes: number;
+  allocated_minutes: number;
+  allocations: Allocation[];
+}
+
+export interface TaskTotal {
+  task: string;
+  minutes: number;
+}
+
+export interface ProjectTotal {
+  project_id: string;
+  name: string;
+  code: string;
+  minutes: number;
+  tasks: TaskTotal[];
+}
+
+export interface ProjectTotalsResponse {
+  from: string;
+  to: string;
+  unallocated_minutes: number;
+  projects: ProjectTotal[];
+}
+
+export interface SessionNoteRequest {
+  session_id: string;
+  note: string; // markdown
+  tags: string[];
+}
+
+export interface VoidSessionResponse {
+  session_id: string;
+  date: string;
+  state: SessionState;
+}
+
+export type PunchKind = 'check_in' | 'check_out' | 'break_start' | 'break_end' | 'void';
+export type PunchSource = 'manual' | 'hr_auto_fetch' | 'scheduler_sync' | 'webhook';
+
+export interface Punch {
+  id: string;
+  kind: PunchKind;
+  time: string;
+  source: PunchSource;
+  session_id?: string; // the voided session of a void punch
+  recorded_at: string;
+}
+
+export interface PunchListResponse {
+  from: string;
+  to: string;
+  punches: Punch[];
+}
+
+export interface Conflict {
+  date: string;
+  kind: 'check_in' | 'check_out';
+  local_time?: string; // absent if the day has no such time yet
+  local_source?: PunchSource;
+  hr_time: string;
+  hr_source: PunchSource;
+  diff_minutes: number; // how far the HR time is after the local one
+  kept_source: PunchSource; // the source whose time the precedence policy keeps
+}
+
+export interface ConflictListResponse {
+  from: string;
+  to: string;
+  conflicts: Conflict[];
+}
+
+export interface SessionNoteResponse {
+  session_id: string;
+  date: string;
+  note: string;
+  tags: string[];
+}
+
+export interface SessionSearchParams {
+  from?: string;
+  to?: string;
+  tag?: string;
+  q?: string;
+  min_overtime?: number;
+}
+
+export interface SessionSearchResult {
+  session_id: string;
+  date: string;
+  check_in_time: string;
+  check_out_time?: string;
+  worked_minutes: number;
+  day_overtime_minutes: number;
+  note: string;
+  tags: string[];
+}
+
+export interface SessionSearchResponse {
+  from: string;
+  to: string;
+  overtime_minutes: number;
+  sessions: SessionSearchResult[];
+}